@@ -0,0 +1,432 @@
+// Command slackstub is a local stand-in for Slack to try the bot without a
+// workspace. It serves the Web API methods the bot calls, printing what the
+// bot sends, and reads commands from stdin to play the user:
+//
+//	/contact [text]       run a slash command
+//	click N               click the Nth button of the messages the bot sent
+//	show                  print the inputs of the open modal
+//	set BLOCK_ID VALUE    fill an input, picking an option for selects
+//	submit                submit the open modal
+//
+// Run the bot with -slack-api http://localhost:9999/api/ and the same
+// SLACK_SIGNING_SECRET as the stub.
+package main
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/slack-go/slack"
+)
+
+const (
+	userId    = "U0LOCAL"
+	channelId = "C0LOCAL"
+)
+
+var (
+	addr   = flag.String("addr", ":9999", "Address to serve the Slack Web API stand-in on")
+	botUrl = flag.String("bot", "http://localhost:3000", "Base URL of the bot")
+	groups = flag.String("groups", "", "Comma separated ids of the user groups the local user is in")
+	team   = flag.String("team", "T0LOCAL", "Id of the Slack team the local user is in")
+)
+
+type stub struct {
+	mu      sync.Mutex
+	secret  string
+	view    *slack.View
+	state   map[string]map[string]slack.BlockAction
+	nextId  int
+	buttons []slack.BlockAction
+}
+
+func main() {
+	godotenv.Load("../.env")
+	flag.Parse()
+	var s = &stub{secret: os.Getenv("SLACK_SIGNING_SECRET")}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/views.open", s.viewsOpen)
+	mux.HandleFunc("/api/views.update", s.viewsUpdate)
+	mux.HandleFunc("/api/chat.postMessage", s.postMessage)
+	mux.HandleFunc("/api/chat.postEphemeral", s.postMessage)
+	mux.HandleFunc("/api/chat.update", s.postMessage)
+	mux.HandleFunc("/api/usergroups.list", userGroups)
+	mux.HandleFunc("/api/users.list", users)
+	mux.HandleFunc("/response", s.response)
+	go func() { log.Fatal(http.ListenAndServe(*addr, mux)) }()
+	log.Printf("Slack stand-in listening on %s, driving the bot at %s", *addr, *botUrl)
+
+	var scanner = bufio.NewScanner(os.Stdin)
+	for fmt.Print("> "); scanner.Scan(); fmt.Print("> ") {
+		var line = strings.TrimSpace(scanner.Text())
+		var fields = strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(fields[0], "/"):
+			s.command(fields[0], strings.TrimSpace(strings.TrimPrefix(line, fields[0])))
+		case fields[0] == "click" && len(fields) == 2:
+			s.click(fields[1])
+		case fields[0] == "show":
+			s.show()
+		case fields[0] == "set" && len(fields) >= 3:
+			s.set(fields[1], strings.Join(fields[2:], " "))
+		case fields[0] == "submit":
+			s.submit()
+		default:
+			fmt.Println("commands: /contact [text] | click N | show | set BLOCK_ID VALUE | submit")
+		}
+	}
+}
+
+func (s *stub) viewsOpen(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		View slack.View `json:"view"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.nextId++
+	req.View.ID = "V" + strconv.Itoa(s.nextId)
+	req.View.Hash = strconv.FormatInt(time.Now().UnixNano(), 10)
+	s.view = &req.View
+	s.state = map[string]map[string]slack.BlockAction{}
+	s.mu.Unlock()
+	fmt.Printf("\n[views.open] %q opened, type `show` to see it\n", req.View.Title.Text)
+	writeOk(w, map[string]any{"view": req.View})
+}
+
+func (s *stub) viewsUpdate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		View   slack.View `json:"view"`
+		ViewId string     `json:"view_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	req.View.ID = req.ViewId
+	req.View.Hash = strconv.FormatInt(time.Now().UnixNano(), 10)
+	s.view = &req.View
+	s.mu.Unlock()
+	fmt.Printf("\n[views.update] %q updated, type `show` to see it\n", req.View.Title.Text)
+	writeOk(w, map[string]any{"view": req.View})
+}
+
+func (s *stub) postMessage(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	var method = strings.TrimPrefix(r.URL.Path, "/api/")
+	fmt.Printf("\n[%s] to %s: %s\n", method, r.FormValue("channel"), r.FormValue("text"))
+	if raw := r.FormValue("blocks"); raw != "" {
+		var blocks = slack.Blocks{}
+		if err := json.Unmarshal([]byte(raw), &blocks); err == nil {
+			s.render(blocks)
+		}
+	}
+	writeOk(w, map[string]any{"channel": r.FormValue("channel"), "ts": "1.000001", "message_ts": "1.000001"})
+}
+
+// response prints what the bot posted to a response_url.
+func (s *stub) response(w http.ResponseWriter, r *http.Request) {
+	var msg = slack.WebhookMessage{}
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fmt.Printf("\n[response] %s\n", msg.Text)
+	if msg.Blocks != nil {
+		s.render(*msg.Blocks)
+	}
+	w.Write([]byte("ok"))
+}
+
+// render prints the text of blocks and numbers their buttons for click.
+func (s *stub) render(blocks slack.Blocks) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, block := range blocks.BlockSet {
+		switch b := block.(type) {
+		case *slack.SectionBlock:
+			if b.Text != nil {
+				fmt.Printf("  %s\n", strings.ReplaceAll(b.Text.Text, "\n", "\n  "))
+			}
+			for _, f := range b.Fields {
+				fmt.Printf("    %s\n", strings.ReplaceAll(f.Text, "\n", ": "))
+			}
+		case *slack.ContextBlock:
+			for _, e := range b.ContextElements.Elements {
+				if t, ok := e.(*slack.TextBlockObject); ok {
+					fmt.Printf("  _%s_\n", t.Text)
+				}
+			}
+		case *slack.ActionBlock:
+			var labels = []string{}
+			for _, e := range b.Elements.ElementSet {
+				button, ok := e.(*slack.ButtonBlockElement)
+				if !ok {
+					continue
+				}
+				s.buttons = append(s.buttons, slack.BlockAction{
+					ActionID: button.ActionID,
+					BlockID:  b.BlockID,
+					Value:    button.Value,
+					Type:     slack.ActionType(slack.METButton),
+				})
+				labels = append(labels, fmt.Sprintf("[%d %s]", len(s.buttons), button.Text.Text))
+			}
+			fmt.Printf("  %s\n", strings.Join(labels, " "))
+		case *slack.DividerBlock:
+			fmt.Println("  ---")
+		}
+	}
+}
+
+func (s *stub) command(command, text string) {
+	var form = url.Values{
+		"command":      {command},
+		"text":         {text},
+		"user_id":      {userId},
+		"channel_id":   {channelId},
+		"team_id":      {*team},
+		"trigger_id":   {triggerId()},
+		"response_url": {s.responseUrl()},
+	}
+	s.post("/slack/commands", form)
+}
+
+func (s *stub) click(n string) {
+	idx, err := strconv.Atoi(n)
+	s.mu.Lock()
+	if err != nil || idx < 1 || idx > len(s.buttons) {
+		s.mu.Unlock()
+		fmt.Printf("no button %s\n", n)
+		return
+	}
+	var action = s.buttons[idx-1]
+	s.mu.Unlock()
+	s.interact(slack.InteractionCallback{
+		Type:           slack.InteractionTypeBlockActions,
+		User:           slack.User{ID: userId},
+		Team:           slack.Team{ID: *team},
+		Channel:        slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: channelId}}},
+		TriggerID:      triggerId(),
+		ResponseURL:    s.responseUrl(),
+		ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{&action}},
+	})
+}
+
+func (s *stub) responseUrl() string {
+	var host = *addr
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	}
+	return "http://" + host + "/response"
+}
+
+func triggerId() string {
+	return "T" + strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+func (s *stub) show() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.view == nil {
+		fmt.Println("no modal is open")
+		return
+	}
+	fmt.Printf("%s\n", s.view.Title.Text)
+	for _, block := range s.view.Blocks.BlockSet {
+		input, ok := block.(*slack.InputBlock)
+		if !ok {
+			continue
+		}
+		var value = ""
+		for _, a := range s.state[input.BlockID] {
+			value = " = " + stateValue(a)
+		}
+		var optional = ""
+		if input.Optional {
+			optional = " (optional)"
+		}
+		_, elementType := elementInfo(input.Element)
+		fmt.Printf("  %-20s %s%s [%s]%s\n", input.BlockID, input.Label.Text, optional, elementType, value)
+		if sel, ok := input.Element.(*slack.SelectBlockElement); ok {
+			var labels = []string{}
+			for _, o := range sel.Options {
+				labels = append(labels, o.Value)
+			}
+			fmt.Printf("  %-20s options: %s\n", "", strings.Join(labels, " | "))
+		}
+	}
+}
+
+func (s *stub) set(blockId, value string) {
+	s.mu.Lock()
+	if s.view == nil {
+		s.mu.Unlock()
+		fmt.Println("no modal is open")
+		return
+	}
+	var input *slack.InputBlock
+	for _, block := range s.view.Blocks.BlockSet {
+		if b, ok := block.(*slack.InputBlock); ok && b.BlockID == blockId {
+			input = b
+		}
+	}
+	if input == nil {
+		s.mu.Unlock()
+		fmt.Printf("no input %s in the modal\n", blockId)
+		return
+	}
+	var actionId, elementType = elementInfo(input.Element)
+	var action = slack.BlockAction{ActionID: actionId, BlockID: blockId, Type: slack.ActionType(elementType)}
+	switch elementType {
+	case slack.OptTypeStatic:
+		action.SelectedOption = slack.OptionBlockObject{Value: value, Text: &slack.TextBlockObject{Type: slack.PlainTextType, Text: value}}
+	case string(slack.METDatepicker):
+		action.SelectedDate = value
+	default:
+		action.Value = value
+	}
+	s.state[blockId] = map[string]slack.BlockAction{actionId: action}
+	var view = *s.view
+	view.State = &slack.ViewState{Values: s.state}
+	s.mu.Unlock()
+
+	if input.DispatchAction {
+		var callback = slack.InteractionCallback{
+			Type:           slack.InteractionTypeBlockActions,
+			User:           slack.User{ID: userId},
+			Team:           slack.Team{ID: *team},
+			View:           view,
+			ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{&action}},
+		}
+		s.interact(callback)
+	}
+}
+
+func (s *stub) submit() {
+	s.mu.Lock()
+	if s.view == nil {
+		s.mu.Unlock()
+		fmt.Println("no modal is open")
+		return
+	}
+	var view = *s.view
+	view.State = &slack.ViewState{Values: s.state}
+	s.mu.Unlock()
+	var callback = slack.InteractionCallback{
+		Type: slack.InteractionTypeViewSubmission,
+		User: slack.User{ID: userId},
+		Team: slack.Team{ID: *team},
+		View: view,
+	}
+	if body := s.interact(callback); strings.TrimSpace(body) == "" {
+		s.mu.Lock()
+		s.view = nil
+		s.mu.Unlock()
+		fmt.Println("modal closed")
+	}
+}
+
+func (s *stub) interact(callback slack.InteractionCallback) string {
+	payload, err := json.Marshal(&callback)
+	if err != nil {
+		fmt.Println(err)
+		return ""
+	}
+	return s.post("/slack/interactions", url.Values{"payload": {string(payload)}})
+}
+
+// post sends form to the bot, signed the way slack signs its requests.
+func (s *stub) post(path string, form url.Values) string {
+	var body = form.Encode()
+	var ts = strconv.FormatInt(time.Now().Unix(), 10)
+	var mac = hmac.New(sha256.New, []byte(s.secret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+	req, _ := http.NewRequest(http.MethodPost, strings.TrimSuffix(*botUrl, "/")+path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println(err)
+		return ""
+	}
+	defer resp.Body.Close()
+	reply, _ := io.ReadAll(resp.Body)
+	if len(reply) > 0 {
+		fmt.Printf("[bot %d] %s\n", resp.StatusCode, reply)
+	}
+	return string(reply)
+}
+
+// elementInfo returns the action id and type of an input element.
+func elementInfo(element slack.BlockElement) (string, string) {
+	switch e := element.(type) {
+	case *slack.SelectBlockElement:
+		return e.ActionID, slack.OptTypeStatic
+	case *slack.DatePickerBlockElement:
+		return e.ActionID, string(slack.METDatepicker)
+	case *slack.PlainTextInputBlockElement:
+		return e.ActionID, string(e.Type)
+	case *slack.EmailTextInputBlockElement:
+		return e.ActionID, string(e.Type)
+	case *slack.URLTextInputBlockElement:
+		return e.ActionID, string(e.Type)
+	case *slack.NumberInputBlockElement:
+		return e.ActionID, string(e.Type)
+	}
+	return "", string(element.ElementType())
+}
+
+func stateValue(a slack.BlockAction) string {
+	if a.SelectedOption.Value != "" {
+		return a.SelectedOption.Value
+	}
+	if a.SelectedDate != "" {
+		return a.SelectedDate
+	}
+	return a.Value
+}
+
+// userGroups lists the groups of -groups, with the local user in each.
+func userGroups(w http.ResponseWriter, r *http.Request) {
+	var list = []slack.UserGroup{}
+	for _, id := range strings.Split(*groups, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			list = append(list, slack.UserGroup{ID: id, IsUserGroup: true, Users: []string{userId}})
+		}
+	}
+	writeOk(w, map[string]any{"usergroups": list})
+}
+
+// users lists the local user, an admin of the workspace.
+func users(w http.ResponseWriter, r *http.Request) {
+	writeOk(w, map[string]any{"members": []slack.User{{ID: userId, IsAdmin: true}}})
+}
+
+func writeOk(w http.ResponseWriter, fields map[string]any) {
+	fields["ok"] = true
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fields)
+}
@@ -0,0 +1,80 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Post is a message made of cards. Text and every string in it are plain
+// text, adapters escape and format them for their chat.
+type Post struct {
+	Text    string
+	Cards   []Card
+	Footer  string
+	Buttons []Button
+}
+
+type Card struct {
+	Title    string
+	Link     string
+	Subtitle string
+	Fields   []Field
+	// Note is shown in small print under the fields.
+	Note    string
+	Buttons []Button
+}
+
+type Field struct {
+	Label string
+	Value string
+	// Link is a URL such as mailto: or tel: that Value opens.
+	Link string
+}
+
+type Button struct {
+	// Action is the Action.Id the button sends, empty for link buttons.
+	Action string
+	Value  string
+	Label  string
+	// Link makes the button open a URL instead of sending an action.
+	Link   string
+	Danger bool
+	// Confirm, when set, is asked before the action is sent.
+	Confirm string
+}
+
+// Markdown renders post with the markdown most chats understand, for the
+// adapters that cannot show buttons. Buttons with a link become links, the
+// others are left out.
+func (post Post) Markdown() string {
+	var builder = strings.Builder{}
+	if post.Text != "" {
+		builder.WriteString(post.Text + "\n")
+	}
+	for _, card := range post.Cards {
+		builder.WriteString("\n")
+		if card.Link != "" {
+			fmt.Fprintf(&builder, "**[%s](%s)**", card.Title, card.Link)
+		} else {
+			fmt.Fprintf(&builder, "**%s**", card.Title)
+		}
+		if card.Subtitle != "" {
+			fmt.Fprintf(&builder, " · %s", card.Subtitle)
+		}
+		builder.WriteString("\n")
+		for _, f := range card.Fields {
+			if f.Link != "" {
+				fmt.Fprintf(&builder, "%s: [%s](%s)\n", f.Label, f.Value, f.Link)
+			} else {
+				fmt.Fprintf(&builder, "%s: %s\n", f.Label, f.Value)
+			}
+		}
+		if card.Note != "" {
+			fmt.Fprintf(&builder, "_%s_\n", card.Note)
+		}
+	}
+	if post.Footer != "" {
+		fmt.Fprintf(&builder, "\n_%s_\n", post.Footer)
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
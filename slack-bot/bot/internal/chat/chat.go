@@ -0,0 +1,90 @@
+// Package chat decouples the bot's command handlers from the chat systems
+// they are reached through. An Adapter turns what a chat system sends into
+// calls of a Handler and gives it a Conversation to answer through.
+package chat
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotSupported is returned by conversations that cannot do what was
+// asked, e.g. open a form in a chat without dialogs.
+var ErrNotSupported = errors.New("not supported by this chat")
+
+// Caller is who sent a message, clicked a button or filled in a form: the
+// user, the groups they belong to, the channel they are in and the
+// workspace of the chat, empty when it has only one. Tenant is the tenant
+// of ops the bot serves them from, if it was given one.
+type Caller struct {
+	User    string
+	Groups  []string
+	Channel string
+	Team    string
+	Tenant  string
+}
+
+// Message is a command a user sent to the bot, without the prefix (slash
+// command, mention or trigger word) that addressed the bot.
+type Message struct {
+	// Id identifies the message in its chat, the same when the chat delivers
+	// it again. It is empty when the chat has no such id.
+	Id   string
+	Text string
+	Caller
+	// Thread is the thread the message belongs to, empty when the chat has
+	// no threads.
+	Thread string
+	// Admin is set when the caller administers the workspace of their Team
+	// and the message is answered privately.
+	Admin bool
+}
+
+// Action is a click on a button of a card.
+type Action struct {
+	Id    string
+	Value string
+	Caller
+}
+
+// Submission is a form the user filled in, or is filling in when passed to
+// Handler.FormChanged.
+type Submission struct {
+	// Id identifies the form submitted, the same when it is submitted again.
+	// It is empty when the chat has no such id.
+	Id     string
+	Form   string
+	State  string
+	Values map[string]string
+	Caller
+}
+
+type Conversation interface {
+	Reply(ctx context.Context, text string) error
+	// ReplyInThread answers in the thread of the message, in chats without
+	// threads it is the same as Reply.
+	ReplyInThread(ctx context.Context, text string) error
+	PostCards(ctx context.Context, post Post) error
+	OpenForm(ctx context.Context, form Form) error
+}
+
+type Handler interface {
+	HandleMessage(ctx context.Context, conv Conversation, msg Message)
+	HandleAction(ctx context.Context, conv Conversation, action Action)
+	// FormChanged is called when a Reload field of an open form changed and
+	// returns the form to show instead, or nil to keep the current one.
+	FormChanged(ctx context.Context, sub Submission) (*Form, error)
+	// ValidateForm returns the errors to show next to the fields of a
+	// submitted form, keyed by field id. It must answer quickly; when there
+	// are no errors the adapter closes the form and calls SubmitForm.
+	ValidateForm(ctx context.Context, sub Submission) map[string]string
+	SubmitForm(ctx context.Context, conv Conversation, sub Submission)
+}
+
+type Adapter interface {
+	// Prefix is how users address the bot in this chat, e.g. /contact.
+	Prefix() string
+	// Run delivers what users send to h until ctx is done or the chat
+	// cannot be reached anymore.
+	Run(ctx context.Context, h Handler) error
+}
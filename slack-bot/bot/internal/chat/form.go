@@ -0,0 +1,38 @@
+package chat
+
+type FieldType string
+
+const (
+	FIELD_TEXT      FieldType = "text"
+	FIELD_MULTILINE FieldType = "multiline"
+	FIELD_EMAIL     FieldType = "email"
+	FIELD_PHONE     FieldType = "phone"
+	FIELD_NUMBER    FieldType = "number"
+	FIELD_URL       FieldType = "url"
+	FIELD_DATE      FieldType = "date"
+	FIELD_SELECT    FieldType = "select"
+)
+
+// Form is a dialog for the user to fill in. Id tells the handler which form
+// was submitted, State is handed back to it untouched.
+type Form struct {
+	Id     string
+	Title  string
+	Submit string
+	State  string
+	Fields []FormField
+}
+
+type FormField struct {
+	Id          string
+	Label       string
+	Type        FieldType
+	Placeholder string
+	// Value is the initial value.
+	Value    string
+	Options  []string
+	Optional bool
+	// Reload asks the handler for a new form as soon as the field changes,
+	// for forms whose fields depend on it.
+	Reload bool
+}
@@ -0,0 +1,197 @@
+// Package command parses the commands users type to the bot and generates
+// their help from the same definitions.
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ErrNoCommand = errors.New("no command given")
+
+// Option is a key=value option of a command.
+type Option struct {
+	Name string
+	// Value names the value in help, e.g. BOARD.
+	Value string
+	Usage string
+	// Values, when set, lists the accepted values; anything else is rejected
+	// with a suggestion of the closest one. A nil list accepts any value.
+	// It gets the context Parse was called with.
+	Values func(ctx context.Context) []string
+}
+
+type Command struct {
+	Name    string
+	Aliases []string
+	// Args names the positional arguments in help, e.g. NAME...
+	Args    string
+	MinArgs int
+	Summary string
+	// Description is shown below the usage line in the help of the command.
+	Description string
+	Options     []Option
+	Examples    []string
+}
+
+func (c *Command) option(name string) *Option {
+	for i := range c.Options {
+		if strings.EqualFold(c.Options[i].Name, name) {
+			return &c.Options[i]
+		}
+	}
+	return nil
+}
+
+// Invocation is a parsed command line.
+type Invocation struct {
+	Command *Command
+	Args    []string
+	Options map[string]string
+}
+
+// Option returns the value given for the option name, or "".
+func (inv *Invocation) Option(name string) string {
+	return inv.Options[strings.ToLower(name)]
+}
+
+// Parser parses command lines against a fixed set of commands. Prefix is
+// how users address the bot, e.g. /contact, and only shows up in help.
+type Parser struct {
+	Prefix   string
+	commands []Command
+}
+
+func New(prefix string, commands ...Command) *Parser {
+	return &Parser{Prefix: prefix, commands: commands}
+}
+
+func (p *Parser) Commands() []Command {
+	return p.commands
+}
+
+// Lookup finds a command by name or alias.
+func (p *Parser) Lookup(name string) *Command {
+	for i := range p.commands {
+		var c = &p.commands[i]
+		if strings.EqualFold(c.Name, name) || slices.ContainsFunc(c.Aliases, func(a string) bool {
+			return strings.EqualFold(a, name)
+		}) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Parse splits line into a command, its arguments and its key=value options.
+// Tokens with an `=` are only options when the key is one of the command's;
+// an unknown key that looks like an option is an error so typos don't end
+// up in the search terms.
+func (p *Parser) Parse(ctx context.Context, line string) (*Invocation, error) {
+	tokens, err := Split(line)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, ErrNoCommand
+	}
+	var cmd = p.Lookup(tokens[0].Text)
+	if cmd == nil {
+		return nil, &UnknownError{Kind: "command", Name: tokens[0].Text, Suggestion: Suggest(tokens[0].Text, p.names())}
+	}
+	var inv = &Invocation{Command: cmd, Args: []string{}, Options: map[string]string{}}
+	for _, t := range tokens[1:] {
+		if t.Eq <= 0 {
+			inv.Args = append(inv.Args, t.Text)
+			continue
+		}
+		var key, value = t.Text[:t.Eq], t.Text[t.Eq+1:]
+		var opt = cmd.option(key)
+		if opt == nil {
+			if !isIdentifier(key) {
+				inv.Args = append(inv.Args, t.Text)
+				continue
+			}
+			return nil, &UnknownError{Kind: "option", Name: key, Suggestion: Suggest(key, cmd.optionNames()), Command: cmd.Name}
+		}
+		if values := opt.valueList(ctx); values != nil {
+			var idx = slices.IndexFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+			if idx < 0 {
+				return nil, &UnknownError{Kind: opt.Name, Name: value, Suggestion: Suggest(value, values), Command: cmd.Name}
+			}
+			value = values[idx]
+		}
+		inv.Options[strings.ToLower(opt.Name)] = value
+	}
+	if len(inv.Args) < cmd.MinArgs {
+		return nil, &UsageError{Command: cmd, Usage: p.Usage(cmd)}
+	}
+	return inv, nil
+}
+
+func (p *Parser) names() []string {
+	var names = []string{}
+	for _, c := range p.commands {
+		names = append(names, c.Name)
+		names = append(names, c.Aliases...)
+	}
+	return names
+}
+
+func (o *Option) valueList(ctx context.Context) []string {
+	if o.Values == nil {
+		return nil
+	}
+	return o.Values(ctx)
+}
+
+func (c *Command) optionNames() []string {
+	var names = make([]string, 0, len(c.Options))
+	for _, o := range c.Options {
+		names = append(names, o.Name)
+	}
+	return names
+}
+
+func isIdentifier(word string) bool {
+	for _, r := range word {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return word != ""
+}
+
+// UnknownError reports a command, option or option value that does not
+// exist, with the closest existing one when there is a likely typo.
+type UnknownError struct {
+	// Kind is "command", "option" or the name of the option whose value is unknown.
+	Kind       string
+	Name       string
+	Suggestion string
+	Command    string
+}
+
+func (e *UnknownError) Error() string {
+	var msg = fmt.Sprintf("unknown %s %q", e.Kind, e.Name)
+	if e.Command != "" && e.Kind == "option" {
+		msg += " for " + e.Command
+	}
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %q?", e.Suggestion)
+	}
+	return msg
+}
+
+// UsageError reports a command given too few arguments.
+type UsageError struct {
+	Command *Command
+	Usage   string
+}
+
+func (e *UsageError) Error() string {
+	return "usage: " + e.Usage
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func FuzzParse(f *testing.F) {
+	var p = New("/contact",
+		Command{Name: "find", Aliases: []string{"search"}, MinArgs: 1, Options: []Option{
+			{Name: "board", Values: func(ctx context.Context) []string { return []string{"Clients", "Key Accounts"} }},
+			{Name: "note"},
+		}},
+		Command{Name: "help"},
+	)
+	for _, seed := range []string{
+		`find ann`,
+		`search "Ann Smith" board=clients`,
+		`find “Ann Smith” board=“Key Accounts”`,
+		`find O'Brien note='a b'`,
+		`find a\ b note=\"c\\`,
+		`find ann board="Key Accounts" note="say \"hi\""`,
+		`find ann bord=Clients`,
+		`find a=b=c`,
+		`fnd ann`,
+		``,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		inv, err := p.Parse(context.Background(), line)
+		if err != nil {
+			return
+		}
+		if len(inv.Args) < inv.Command.MinArgs {
+			t.Errorf("Parse(%q) = %d args, want at least %d", line, len(inv.Args), inv.Command.MinArgs)
+		}
+		if board, ok := inv.Options["board"]; ok && !slices.Contains([]string{"Clients", "Key Accounts"}, board) {
+			t.Errorf("Parse(%q) accepted board %q", line, board)
+		}
+	})
+}
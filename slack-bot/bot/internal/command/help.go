@@ -0,0 +1,72 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Line returns the command line made of args as users type it, e.g.
+// Line("help") is "/contact help".
+func (p *Parser) Line(args ...string) string {
+	return strings.TrimSpace(p.Prefix + " " + strings.Join(args, " "))
+}
+
+// Usage returns the synopsis of cmd, e.g. /contact find NAME... [board=BOARD].
+func (p *Parser) Usage(cmd *Command) string {
+	var parts = []string{cmd.Name}
+	if cmd.Args != "" {
+		parts = append(parts, cmd.Args)
+	}
+	for _, o := range cmd.Options {
+		parts = append(parts, fmt.Sprintf("[%s=%s]", o.Name, o.valueName()))
+	}
+	return p.Line(parts...)
+}
+
+// Help lists every command, or describes the command called name.
+func (p *Parser) Help(name string) (string, error) {
+	var builder = strings.Builder{}
+	var w = tabwriter.NewWriter(&builder, 0, 4, 2, ' ', 0)
+	if name == "" {
+		fmt.Fprintf(w, "Usage: %s\n\nCommands:\n", p.Line("COMMAND [ARGS] [key=value...]"))
+		for _, c := range p.commands {
+			fmt.Fprintf(w, "  %s\t%s\n", c.Name, c.Summary)
+		}
+		w.Flush()
+		fmt.Fprintf(&builder, "\nRun `%s` for the arguments and options of a command.", p.Line("help COMMAND"))
+		return builder.String(), nil
+	}
+	var cmd = p.Lookup(name)
+	if cmd == nil {
+		return "", &UnknownError{Kind: "command", Name: name, Suggestion: Suggest(name, p.names())}
+	}
+	fmt.Fprintf(&builder, "Usage: %s\n\n%s\n", p.Usage(cmd), cmd.Summary)
+	if cmd.Description != "" {
+		fmt.Fprintf(&builder, "%s\n", cmd.Description)
+	}
+	if len(cmd.Aliases) > 0 {
+		fmt.Fprintf(&builder, "Aliases: %s\n", strings.Join(cmd.Aliases, ", "))
+	}
+	if len(cmd.Options) > 0 {
+		fmt.Fprintf(&builder, "\nOptions:\n")
+		for _, o := range cmd.Options {
+			fmt.Fprintf(w, "  %s=%s\t%s\n", o.Name, o.valueName(), o.Usage)
+		}
+		w.Flush()
+	}
+	if len(cmd.Examples) > 0 {
+		fmt.Fprintf(&builder, "\nExamples:\n")
+		for _, e := range cmd.Examples {
+			fmt.Fprintf(&builder, "  %s\n", p.Line(e))
+		}
+	}
+	return strings.TrimRight(builder.String(), "\n"), nil
+}
+
+func (o Option) valueName() string {
+	if o.Value != "" {
+		return o.Value
+	}
+	return strings.ToUpper(o.Name)
+}
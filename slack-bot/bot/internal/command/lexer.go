@@ -0,0 +1,125 @@
+package command
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// Token is one whitespace separated word of a command line, with its quotes
+// and escapes removed.
+type Token struct {
+	Text string
+	// Eq is the index in Text of the first `=` outside quotes, -1 if none.
+	// Only such tokens can be key=value options.
+	Eq int
+}
+
+// Split breaks a command line into tokens. Single or double quotes, straight
+// or curly as chat clients like to turn them into, keep whitespace inside a
+// token; a backslash escapes the next character. Quotes only open at the
+// start of a word or right after `key=`, so names like O'Brien need none.
+func Split(line string) ([]Token, error) {
+	var tokens = []Token{}
+	var text = strings.Builder{}
+	var runes = []rune(line)
+	var inToken = false
+	var eq = -1
+	var flush = func() {
+		if inToken {
+			tokens = append(tokens, Token{Text: text.String(), Eq: eq})
+		}
+		text.Reset()
+		inToken = false
+		eq = -1
+	}
+	for i := 0; i < len(runes); i++ {
+		var r = runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '\\' && i+1 < len(runes):
+			i++
+			text.WriteRune(runes[i])
+			inToken = true
+		case isQuote(r) && (!inToken || (eq >= 0 && eq+1 == text.Len())):
+			end, ok := closingQuote(runes, i)
+			if !ok {
+				return nil, ErrUnterminatedQuote
+			}
+			text.WriteString(unescape(runes[i+1 : end]))
+			inToken = true
+			i = end
+		case r == '=' && eq < 0 && inToken:
+			eq = text.Len()
+			text.WriteRune(r)
+		default:
+			text.WriteRune(r)
+			inToken = true
+		}
+	}
+	flush()
+	return tokens, nil
+}
+
+// closingQuote finds the quote closing the one at start: a quote of the same
+// kind followed by whitespace or the end of the line.
+func closingQuote(runes []rune, start int) (int, bool) {
+	var kind = quoteKind(runes[start])
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == '\\' {
+			i++
+			continue
+		}
+		if quoteKind(runes[i]) == kind && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func unescape(runes []rune) string {
+	var text = strings.Builder{}
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) {
+			i++
+		}
+		text.WriteRune(runes[i])
+	}
+	return text.String()
+}
+
+func isQuote(r rune) bool {
+	return quoteKind(r) != 0
+}
+
+func quoteKind(r rune) rune {
+	switch r {
+	case '"', '“', '”', '„':
+		return '"'
+	case '\'', '‘', '’':
+		return '\''
+	}
+	return 0
+}
+
+// Quote returns word as a single token, quoting it when needed.
+func Quote(word string) string {
+	if word != "" && !strings.ContainsFunc(word, func(r rune) bool {
+		return unicode.IsSpace(r) || isQuote(r) || r == '\\' || r == '='
+	}) {
+		return word
+	}
+	var text = strings.Builder{}
+	text.WriteRune('"')
+	for _, r := range word {
+		if r == '\\' || isQuote(r) {
+			text.WriteRune('\\')
+		}
+		text.WriteRune(r)
+	}
+	text.WriteRune('"')
+	return text.String()
+}
@@ -0,0 +1,40 @@
+package command
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzSplit(f *testing.F) {
+	for _, seed := range []string{
+		`find ann`,
+		`find "Ann Smith" board=Clients`,
+		`find “Ann Smith” ‘O Brien’ „x”`,
+		`find O'Brien`,
+		`find a\ b \"c\\`,
+		`find board="Key Accounts"`,
+		`find note="say \"hi\""`,
+		`find "unterminated`,
+		`=x a= "`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		tokens, err := Split(line)
+		if err != nil {
+			return
+		}
+		for _, token := range tokens {
+			if token.Eq >= len(token.Text) || token.Eq >= 0 && token.Text[token.Eq] != '=' {
+				t.Errorf("Split(%q): token %q has Eq %d", line, token.Text, token.Eq)
+			}
+		}
+		if !utf8.ValidString(line) {
+			return
+		}
+		tokens, err = Split(Quote(line))
+		if err != nil || len(tokens) != 1 || tokens[0].Text != line || tokens[0].Eq != -1 {
+			t.Errorf("Split(Quote(%q)) = %q, %v, want the word back as one token", line, tokens, err)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package command
+
+import (
+	"strings"
+)
+
+// Suggest returns the candidate closest to word when it is close enough to
+// be a typo of it, or "" otherwise.
+func Suggest(word string, candidates []string) string {
+	var lower = strings.ToLower(word)
+	var best = ""
+	var bestDistance = maxTypos(lower) + 1
+	for _, c := range candidates {
+		var d = distance(lower, strings.ToLower(c))
+		if d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// maxTypos is how many edits apart a word and its suggestion may be.
+func maxTypos(word string) int {
+	var n = len([]rune(word))
+	switch {
+	case n <= 2:
+		return 1
+	case n <= 5:
+		return 2
+	}
+	return n / 3
+}
+
+// distance is the Damerau-Levenshtein distance between a and b, so that a
+// swap of two letters counts as one typo.
+func distance(a, b string) int {
+	var ra, rb = []rune(a), []rune(b)
+	var prev2 = make([]int, len(rb)+1)
+	var prev = make([]int, len(rb)+1)
+	var cur = make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			var cost = 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,57 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+)
+
+type callerKey struct{}
+
+// withCaller returns ctx for the work done for caller. The ops calls made
+// with it forward the caller, for ops to check its own policy as well and
+// to serve it from its tenant.
+func (h *Handler) withCaller(ctx context.Context, caller chat.Caller) context.Context {
+	if caller.Tenant == "" {
+		caller.Tenant = h.tenant
+	}
+	ctx = context.WithValue(ctx, callerKey{}, caller)
+	return authz.OutgoingContext(ctx, authz.Caller(caller))
+}
+
+func callerOf(ctx context.Context) chat.Caller {
+	caller, _ := ctx.Value(callerKey{}).(chat.Caller)
+	return caller
+}
+
+// UseTenant has ops serve every caller from tenant, rather than from the
+// tenant of their team.
+func (h *Handler) UseTenant(tenant string) {
+	h.tenant = tenant
+}
+
+// UsePolicy only lets users work with the boards policy allows them to.
+// Without a policy everything is left to ops.
+func (h *Handler) UsePolicy(policy *authz.Policy) {
+	h.policy = policy
+}
+
+func (h *Handler) allowed(ctx context.Context, board string, op authz.Operation) bool {
+	return h.policy.Allowed(authz.Caller(callerOf(ctx)), board, op)
+}
+
+// check tells the user when they may not do op on board, before ops is
+// asked to.
+func (h *Handler) check(ctx context.Context, conv chat.Conversation, board string, op authz.Operation) bool {
+	if h.allowed(ctx, board, op) {
+		return true
+	}
+	h.reply(ctx, conv, denied(board, op))
+	return false
+}
+
+func denied(board string, op authz.Operation) string {
+	return fmt.Sprintf("Sorry, you may not %s contacts on %s", op, board)
+}
@@ -0,0 +1,254 @@
+package contacts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+)
+
+const (
+	// a search fetches at most findLimit contacts, shown pageSize at a time
+	findLimit = 50
+	pageSize  = 5
+	// how long "Show more" and Edit keep working on a search
+	searchTTL = 30 * time.Minute
+
+	editAction    = "edit_contact"
+	noteAction    = "add_note"
+	archiveAction = "archive_contact"
+	moreAction    = "show_more"
+)
+
+var errSearchExpired = errors.New("this search expired, run it again")
+
+// search keeps the contacts a find command returned so their cards can be
+// paged through and edited without searching monday again.
+type search struct {
+	query   string
+	items   []*pb.FindItemResponse
+	created time.Time
+}
+
+type searches struct {
+	mu   sync.Mutex
+	byId map[string]*search
+}
+
+func newSearches() *searches {
+	return &searches{byId: map[string]*search{}}
+}
+
+func (s *searches) add(query string, items []*pb.FindItemResponse) string {
+	var raw = make([]byte, 8)
+	rand.Read(raw)
+	var id = hex.EncodeToString(raw)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, old := range s.byId {
+		if time.Since(old.created) > searchTTL {
+			delete(s.byId, key)
+		}
+	}
+	s.byId[id] = &search{query: query, items: items, created: time.Now()}
+	return id
+}
+
+func (s *searches) get(id string) (*search, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found, ok := s.byId[id]
+	if !ok || time.Since(found.created) > searchTTL {
+		return nil, errSearchExpired
+	}
+	return found, nil
+}
+
+// cardRef is the value of the buttons of a contact card.
+type cardRef struct {
+	Search string `json:"s"`
+	Index  int    `json:"i"`
+	Id     string `json:"id"`
+	Name   string `json:"n"`
+	Board  string `json:"b"`
+}
+
+func parseCardRef(value string) (cardRef, error) {
+	var ref = cardRef{}
+	if err := json.Unmarshal([]byte(value), &ref); err != nil {
+		return ref, fmt.Errorf("failed to decode button value: %w", err)
+	}
+	return ref, nil
+}
+
+// item returns the contact the card was rendered for.
+func (h *Handler) item(ref cardRef) (*pb.FindItemResponse, error) {
+	found, err := h.searches.get(ref.Search)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Index < 0 || ref.Index >= len(found.items) {
+		return nil, fmt.Errorf("no contact %d in search %s", ref.Index, ref.Search)
+	}
+	return found.items[ref.Index], nil
+}
+
+// find searches contacts by column, the name when empty, and answers with
+// the first page of cards. A board narrows the results down to its contacts.
+func (h *Handler) find(ctx context.Context, conv chat.Conversation, query, board, column string) {
+	if board != "" && !h.check(ctx, conv, board, authz.FIND) {
+		return
+	}
+	if board == "" && h.policy != nil {
+		var names = h.boardNames(ctx)
+		if names != nil && len(h.policy.Boards(authz.Caller(callerOf(ctx)), names, authz.FIND)) == 0 {
+			h.reply(ctx, conv, "Sorry, you may not search any contact board")
+			return
+		}
+	}
+	h.reply(ctx, conv, fmt.Sprintf("Searching for %s...", query))
+	if column == "" {
+		column = "name"
+	}
+	items, err := h.findItems(ctx, column, query, board)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to find %s: %w", query, err))
+		h.reply(ctx, conv, fmt.Sprintf("Search for %s failed: %s", query, err))
+		return
+	}
+	items = slices.DeleteFunc(items, func(item *pb.FindItemResponse) bool {
+		return !h.allowed(ctx, item.Board, authz.FIND)
+	})
+	if len(items) == 0 {
+		h.reply(ctx, conv, fmt.Sprintf("No contact matches %s", query))
+		return
+	}
+	var id = h.searches.add(query, items)
+	found, _ := h.searches.get(id)
+	if err := conv.PostCards(ctx, resultsPost(id, found, 0)); err != nil {
+		log.Println(fmt.Errorf("failed to post results: %w", err))
+	}
+}
+
+// findItems searches the contacts of board, of every board when empty, for
+// the limit to apply to that board only.
+func (h *Handler) findItems(ctx context.Context, column, query, board string) ([]*pb.FindItemResponse, error) {
+	stream, err := h.ops.FindItem(ctx, &pb.FindItemRequest{
+		Column: column,
+		Value:  query,
+		Limit:  findLimit,
+		Sort:   pb.Sort_SORT_RELEVANCE,
+		Board:  board,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var items = []*pb.FindItemResponse{}
+	for {
+		item, err := stream.Recv()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+// showMore answers a "Show more" click with the next page of the search.
+func (h *Handler) showMore(ctx context.Context, conv chat.Conversation, value string) error {
+	id, offset, _ := strings.Cut(value, ":")
+	start, err := strconv.Atoi(offset)
+	if err != nil {
+		return fmt.Errorf("invalid page %s: %w", value, err)
+	}
+	found, err := h.searches.get(id)
+	if err != nil {
+		h.reply(ctx, conv, fmt.Sprintf("This search expired, run `%s` again", h.commands.Line("find")))
+		return nil
+	}
+	return conv.PostCards(ctx, resultsPost(id, found, start))
+}
+
+// resultsPost renders the page of found starting at offset, followed by a
+// "Show more" button while there are contacts left.
+func resultsPost(id string, found *search, offset int) chat.Post {
+	var end = min(offset+pageSize, len(found.items))
+	var post = chat.Post{}
+	if offset == 0 {
+		post.Text = fmt.Sprintf("Found %d contacts matching %s", len(found.items), found.query)
+		if len(found.items) == 1 {
+			post.Text = fmt.Sprintf("Found one contact matching %s", found.query)
+		}
+	}
+	for i := offset; i < end; i++ {
+		post.Cards = append(post.Cards, contactCard(id, i, found.items[i]))
+	}
+	if len(found.items) > pageSize {
+		post.Footer = fmt.Sprintf("Showing %d-%d of %d", offset+1, end, len(found.items))
+	}
+	if end < len(found.items) {
+		post.Buttons = []chat.Button{{Action: moreAction, Value: fmt.Sprintf("%s:%d", id, end), Label: "Show more"}}
+	}
+	return post
+}
+
+// contactCard renders one found contact with the actions available on it.
+func contactCard(searchId string, index int, item *pb.FindItemResponse) chat.Card {
+	var card = chat.Card{Title: item.Name, Link: item.Url, Subtitle: item.Board}
+	if item.Group != "" {
+		card.Subtitle += " › " + item.Group
+	}
+	if item.Email != "" {
+		card.Fields = append(card.Fields, chat.Field{Label: "Email", Value: item.Email, Link: "mailto:" + item.Email})
+	}
+	if item.Phone != "" {
+		card.Fields = append(card.Fields, chat.Field{Label: "Phone", Value: item.Phone, Link: "tel:" + dialable(item.Phone)})
+	}
+	if item.Source != pb.Source_SOURCE_LIVE && item.SyncedAt != nil {
+		var source = "local index"
+		if item.Source == pb.Source_SOURCE_MIRROR {
+			source = "offline mirror, monday could not be reached"
+		}
+		var age = time.Since(item.SyncedAt.AsTime()).Round(time.Minute)
+		card.Note = fmt.Sprintf("From the %s, synced %s ago", source, age)
+	}
+
+	encoded, _ := json.Marshal(cardRef{Search: searchId, Index: index, Id: item.Id, Name: item.Name, Board: item.Board})
+	var ref = string(encoded)
+	card.Buttons = []chat.Button{
+		{Action: editAction, Value: ref, Label: "Edit"},
+		{Action: noteAction, Value: ref, Label: "Add note"},
+		{Action: archiveAction, Value: ref, Label: "Archive", Danger: true,
+			Confirm: fmt.Sprintf("Archive %s from %s?", item.Name, item.Board)},
+	}
+	if item.Url != "" {
+		card.Buttons = append(card.Buttons, chat.Button{Label: "Open in monday", Link: item.Url})
+	}
+	return card
+}
+
+// dialable strips a phone number down to what a tel: link accepts.
+func dialable(phone string) string {
+	var digits = strings.Builder{}
+	for i, r := range phone {
+		if (r >= '0' && r <= '9') || (r == '+' && i == 0) {
+			digits.WriteRune(r)
+		}
+	}
+	return digits.String()
+}
@@ -0,0 +1,499 @@
+package contacts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/command"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+)
+
+const (
+	addContactForm  = "add_contact"
+	editContactForm = "edit_contact"
+	addNoteForm     = "add_note"
+
+	boardField        = "board"
+	groupField        = "group"
+	nameField         = "name"
+	noteField         = "note"
+	columnFieldPrefix = "col:"
+)
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ().-]{5,}$`)
+
+// formState is the State of the contact forms. Values holds what the edit
+// form was opened with, so only changed fields are written back.
+type formState struct {
+	Board  string            `json:"board,omitempty"`
+	Item   string            `json:"item,omitempty"`
+	Name   string            `json:"name,omitempty"`
+	Values map[string]string `json:"values,omitempty"`
+}
+
+func (s formState) encode() string {
+	encoded, _ := json.Marshal(s)
+	return string(encoded)
+}
+
+func parseFormState(raw string) formState {
+	var state = formState{}
+	json.Unmarshal([]byte(raw), &state)
+	return state
+}
+
+// add opens the add contact form, or adds the contact right away when its
+// name was given as an option, once for the message messageId.
+func (h *Handler) add(ctx context.Context, conv chat.Conversation, inv *command.Invocation, messageId string) {
+	if inv.Option("name") != "" {
+		h.addDirectly(ctx, conv, inv, messageId)
+		return
+	}
+	if board := inv.Option("board"); board != "" && !h.check(ctx, conv, board, authz.ADD) {
+		return
+	}
+	boards, err := h.addableBoards(ctx)
+	if err != nil {
+		h.reply(ctx, conv, fmt.Sprintf("Sorry, I could not load the contact boards: %s", err))
+		return
+	}
+	if len(boards) == 0 {
+		h.reply(ctx, conv, "There are no contact boards you may add to")
+		return
+	}
+	var selected *pb.BoardDescription
+	if len(boards) == 1 {
+		selected = boards[0]
+	} else if board := inv.Option("board"); board != "" {
+		selected = findBoard(boards, board)
+	}
+	err = conv.OpenForm(ctx, addContactView(boards, selected))
+	if errors.Is(err, chat.ErrNotSupported) {
+		h.reply(ctx, conv, fmt.Sprintf("Forms cannot be opened from here, add the contact with `%s`",
+			h.commands.Line(`add board=BOARD name="NAME" [email=EMAIL] [phone=PHONE]`)))
+		return
+	}
+	if err != nil {
+		h.reply(ctx, conv, fmt.Sprintf("Sorry, I could not open the form: %s", err))
+	}
+}
+
+func (h *Handler) addDirectly(ctx context.Context, conv chat.Conversation, inv *command.Invocation, messageId string) {
+	var req = &pb.CreateItemRequest{
+		Board: inv.Option("board"),
+		Group: inv.Option("group"),
+		Name:  inv.Option("name"),
+		Email: inv.Option("email"),
+		Phone: inv.Option("phone"),
+		// the chat delivering the message again adds the contact once
+		IdempotencyKey: messageId,
+	}
+	if req.Board == "" {
+		h.reply(ctx, conv, "Which board? Add `board=BOARD`")
+		return
+	}
+	if !h.check(ctx, conv, req.Board, authz.ADD) {
+		return
+	}
+	if req.Email != "" {
+		if msg := validateColumn(&pb.ColumnMeta{Type: "email"}, req.Email); msg != "" {
+			h.reply(ctx, conv, msg)
+			return
+		}
+	}
+	if req.Phone != "" {
+		if msg := validateColumn(&pb.ColumnMeta{Type: "phone"}, req.Phone); msg != "" {
+			h.reply(ctx, conv, msg)
+			return
+		}
+	}
+	if _, err := h.ops.CreateItem(ctx, req); err != nil {
+		h.outcome(ctx, conv, fmt.Sprintf("Failed to add %s to %s: %s", req.Name, req.Board, err))
+		return
+	}
+	h.outcome(ctx, conv, fmt.Sprintf("Added %s to %s", req.Name, req.Board))
+}
+
+// boardSelected returns the add contact form with the groups and columns of
+// the board the user just picked.
+func (h *Handler) boardSelected(ctx context.Context, sub chat.Submission) (*chat.Form, error) {
+	boards, err := h.addableBoards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var form = addContactView(boards, findBoard(boards, sub.Values[boardField]))
+	return &form, nil
+}
+
+// addableBoards describes the boards the caller may add contacts to.
+func (h *Handler) addableBoards(ctx context.Context) ([]*pb.BoardDescription, error) {
+	resp, err := h.ops.DescribeBoard(ctx, &pb.DescribeBoardRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(resp.Boards, func(board *pb.BoardDescription) bool {
+		return !h.allowed(ctx, board.Name, authz.ADD)
+	}), nil
+}
+
+func addContactView(boards []*pb.BoardDescription, selected *pb.BoardDescription) chat.Form {
+	var names = make([]string, 0, len(boards))
+	for _, board := range boards {
+		names = append(names, board.Name)
+	}
+	var state = formState{}
+	var boardInput = chat.FormField{Id: boardField, Label: "Board", Type: chat.FIELD_SELECT, Placeholder: "Choose a board", Options: names, Reload: true}
+	if selected != nil {
+		boardInput.Value = selected.Name
+		state.Board = selected.Name
+	}
+	var fields = []chat.FormField{boardInput}
+	if selected != nil && len(selected.Groups) > 0 {
+		var groups = make([]string, 0, len(selected.Groups))
+		for _, g := range selected.Groups {
+			groups = append(groups, g.Title)
+		}
+		fields = append(fields, chat.FormField{Id: groupField, Label: "Group", Type: chat.FIELD_SELECT,
+			Placeholder: "Board's default group", Options: groups, Optional: true})
+	}
+	fields = append(fields, chat.FormField{Id: nameField, Label: "Name", Type: chat.FIELD_TEXT, Placeholder: "Jane Doe"})
+	if selected != nil {
+		for _, col := range selected.Columns {
+			if field, ok := columnField(col, ""); ok {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return chat.Form{Id: addContactForm, Title: "Add contact", Submit: "Add", State: state.encode(), Fields: fields}
+}
+
+// columnField picks the field matching a column type, filled with initial.
+// Columns that cannot be filled from a form (people, formulas, mirrors...)
+// have none.
+func columnField(col *pb.ColumnMeta, initial string) (chat.FormField, bool) {
+	var field = chat.FormField{Id: columnFieldPrefix + col.Id, Label: col.Title, Value: initial, Optional: true}
+	switch col.Type {
+	case "text":
+		field.Type = chat.FIELD_TEXT
+	case "long_text":
+		field.Type = chat.FIELD_MULTILINE
+	case "email":
+		field.Type, field.Placeholder = chat.FIELD_EMAIL, "jane@example.com"
+	case "phone":
+		field.Type, field.Placeholder = chat.FIELD_PHONE, "+40 722 123 456"
+	case "numbers":
+		field.Type = chat.FIELD_NUMBER
+	case "link":
+		field.Type, field.Placeholder = chat.FIELD_URL, "https://"
+	case "date":
+		field.Type = chat.FIELD_DATE
+	case "status", "dropdown":
+		if len(col.Labels) == 0 {
+			return field, false
+		}
+		field.Type, field.Options = chat.FIELD_SELECT, col.Labels
+		if !slices.Contains(col.Labels, initial) {
+			field.Value = ""
+		}
+	default:
+		return field, false
+	}
+	return field, true
+}
+
+// parseAddContact turns a submitted add contact form into a CreateItem
+// request, or into the errors to show next to each invalid field.
+func (h *Handler) parseAddContact(ctx context.Context, sub chat.Submission) (*pb.CreateItemRequest, map[string]string) {
+	var state = parseFormState(sub.State)
+	var boardName = sub.Values[boardField]
+	if boardName == "" || !strings.EqualFold(boardName, state.Board) {
+		return nil, map[string]string{boardField: "The fields of this board are still loading, submit again in a moment"}
+	}
+	if !h.allowed(ctx, boardName, authz.ADD) {
+		return nil, map[string]string{boardField: denied(boardName, authz.ADD)}
+	}
+	resp, err := h.ops.DescribeBoard(ctx, &pb.DescribeBoardRequest{Board: boardName})
+	if err != nil || len(resp.Boards) == 0 {
+		return nil, map[string]string{boardField: fmt.Sprintf("Could not load board %s, try again", boardName)}
+	}
+	var board = resp.Boards[0]
+	var errs = map[string]string{}
+	var req = &pb.CreateItemRequest{
+		Board:   board.Name,
+		Name:    strings.TrimSpace(sub.Values[nameField]),
+		Group:   sub.Values[groupField],
+		Columns: map[string]string{},
+	}
+	if req.Name == "" {
+		errs[nameField] = "A name is required"
+	}
+	var columns = parseColumns(board, sub.Values, errs)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	for id, value := range columns {
+		if value != "" {
+			req.Columns[id] = value
+		}
+	}
+	return req, nil
+}
+
+func (h *Handler) submitAddContact(ctx context.Context, conv chat.Conversation, sub chat.Submission) {
+	req, errs := h.parseAddContact(ctx, sub)
+	if len(errs) > 0 {
+		h.outcome(ctx, conv, fmt.Sprintf("Could not add the contact: %s", joinErrors(errs)))
+		return
+	}
+	req.IdempotencyKey = sub.Id
+	if _, err := h.ops.CreateItem(ctx, req); err != nil {
+		h.outcome(ctx, conv, fmt.Sprintf("Failed to add %s to %s: %s", req.Name, req.Board, err))
+		return
+	}
+	h.outcome(ctx, conv, fmt.Sprintf("Added %s to %s", req.Name, req.Board))
+}
+
+func (h *Handler) openEditContact(ctx context.Context, conv chat.Conversation, value string) error {
+	ref, err := parseCardRef(value)
+	if err != nil {
+		return err
+	}
+	item, err := h.item(ref)
+	if err != nil {
+		h.reply(ctx, conv, fmt.Sprintf("Cannot edit %s: %s", ref.Name, err))
+		return nil
+	}
+	if !h.check(ctx, conv, item.Board, authz.UPDATE) {
+		return nil
+	}
+	resp, err := h.ops.DescribeBoard(ctx, &pb.DescribeBoardRequest{Board: item.Board})
+	if err != nil {
+		return err
+	}
+	if len(resp.Boards) == 0 {
+		return fmt.Errorf("board %s not found", item.Board)
+	}
+	err = conv.OpenForm(ctx, editContactView(resp.Boards[0], item))
+	if errors.Is(err, chat.ErrNotSupported) {
+		h.reply(ctx, conv, "Forms cannot be opened from here, edit the contact in monday")
+		return nil
+	}
+	return err
+}
+
+func editContactView(board *pb.BoardDescription, item *pb.FindItemResponse) chat.Form {
+	var current = map[string]string{}
+	for _, c := range item.Columns {
+		current[c.Id] = c.Value
+	}
+	var state = formState{Item: item.Id, Name: item.Name, Board: item.Board, Values: map[string]string{nameField: item.Name}}
+	var fields = []chat.FormField{{Id: nameField, Label: "Name", Type: chat.FIELD_TEXT, Value: item.Name}}
+	for _, col := range board.Columns {
+		field, ok := columnField(col, current[col.Id])
+		if !ok {
+			continue
+		}
+		state.Values[col.Id] = field.Value
+		fields = append(fields, field)
+	}
+	return chat.Form{Id: editContactForm, Title: "Edit contact", Submit: "Save", State: state.encode(), Fields: fields}
+}
+
+// parseEditContact turns a submitted edit form into an UpdateItem request
+// with only the fields that changed.
+func (h *Handler) parseEditContact(ctx context.Context, sub chat.Submission) (*pb.UpdateItemRequest, map[string]string) {
+	var state = parseFormState(sub.State)
+	if !h.allowed(ctx, state.Board, authz.UPDATE) {
+		return nil, map[string]string{nameField: denied(state.Board, authz.UPDATE)}
+	}
+	resp, err := h.ops.DescribeBoard(ctx, &pb.DescribeBoardRequest{Board: state.Board})
+	if err != nil || len(resp.Boards) == 0 {
+		return nil, map[string]string{nameField: fmt.Sprintf("Could not load board %s, try again", state.Board)}
+	}
+	var errs = map[string]string{}
+	var req = &pb.UpdateItemRequest{Id: state.Item, Columns: map[string]string{}}
+	var name = strings.TrimSpace(sub.Values[nameField])
+	if name == "" {
+		errs[nameField] = "A name is required"
+	}
+	if name != state.Values[nameField] {
+		req.Name = name
+	}
+	for id, value := range parseColumns(resp.Boards[0], sub.Values, errs) {
+		if value != state.Values[id] {
+			req.Columns[id] = value
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return req, nil
+}
+
+func (h *Handler) submitEditContact(ctx context.Context, conv chat.Conversation, sub chat.Submission) {
+	var state = parseFormState(sub.State)
+	req, errs := h.parseEditContact(ctx, sub)
+	if len(errs) > 0 {
+		h.outcome(ctx, conv, fmt.Sprintf("Could not update %s: %s", state.Name, joinErrors(errs)))
+		return
+	}
+	if req.Name == "" && len(req.Columns) == 0 {
+		return
+	}
+	if _, err := h.ops.UpdateItem(ctx, req); err != nil {
+		h.outcome(ctx, conv, fmt.Sprintf("Failed to update %s: %s", state.Name, err))
+		return
+	}
+	var name = state.Name
+	if req.Name != "" {
+		name = req.Name
+	}
+	h.outcome(ctx, conv, fmt.Sprintf("Updated %s", name))
+}
+
+func (h *Handler) openAddNote(ctx context.Context, conv chat.Conversation, value string) error {
+	ref, err := parseCardRef(value)
+	if err != nil {
+		return err
+	}
+	if !h.check(ctx, conv, ref.Board, authz.UPDATE) {
+		return nil
+	}
+	var state = formState{Item: ref.Id, Name: ref.Name, Board: ref.Board}
+	var form = chat.Form{
+		Id:     addNoteForm,
+		Title:  "Add note",
+		Submit: "Add",
+		State:  state.encode(),
+		Fields: []chat.FormField{{Id: noteField, Label: "Note on " + ref.Name, Type: chat.FIELD_MULTILINE}},
+	}
+	err = conv.OpenForm(ctx, form)
+	if errors.Is(err, chat.ErrNotSupported) {
+		h.reply(ctx, conv, "Forms cannot be opened from here, add the note in monday")
+		return nil
+	}
+	return err
+}
+
+func (h *Handler) parseAddNote(ctx context.Context, sub chat.Submission) (*pb.AddNoteRequest, map[string]string) {
+	var state = parseFormState(sub.State)
+	if !h.allowed(ctx, state.Board, authz.UPDATE) {
+		return nil, map[string]string{noteField: denied(state.Board, authz.UPDATE)}
+	}
+	var body = strings.TrimSpace(sub.Values[noteField])
+	if body == "" {
+		return nil, map[string]string{noteField: "The note is empty"}
+	}
+	return &pb.AddNoteRequest{ItemId: state.Item, Body: body}, nil
+}
+
+func (h *Handler) submitAddNote(ctx context.Context, conv chat.Conversation, sub chat.Submission) {
+	var state = parseFormState(sub.State)
+	req, errs := h.parseAddNote(ctx, sub)
+	if len(errs) > 0 {
+		h.outcome(ctx, conv, fmt.Sprintf("Could not add a note to %s: %s", state.Name, joinErrors(errs)))
+		return
+	}
+	if _, err := h.ops.AddNote(ctx, req); err != nil {
+		h.outcome(ctx, conv, fmt.Sprintf("Failed to add a note to %s: %s", state.Name, err))
+		return
+	}
+	h.outcome(ctx, conv, fmt.Sprintf("Added a note to %s", state.Name))
+}
+
+// archiveContact archives the contact of a card; the button already asked
+// the user to confirm.
+func (h *Handler) archiveContact(ctx context.Context, conv chat.Conversation, value string) error {
+	ref, err := parseCardRef(value)
+	if err != nil {
+		return err
+	}
+	if !h.check(ctx, conv, ref.Board, authz.DELETE) {
+		return nil
+	}
+	if _, err := h.ops.ArchiveItem(ctx, &pb.ArchiveItemRequest{Id: ref.Id}); err != nil {
+		h.outcome(ctx, conv, fmt.Sprintf("Failed to archive %s: %s", ref.Name, err))
+		return nil
+	}
+	h.outcome(ctx, conv, fmt.Sprintf("Archived %s", ref.Name))
+	return nil
+}
+
+// parseColumns returns the value of every column field of the form, keyed
+// by column id, adding an error to errs for each invalid one. Fields left
+// empty are returned with an empty value.
+func parseColumns(board *pb.BoardDescription, values map[string]string, errs map[string]string) map[string]string {
+	var columns = map[string]string{}
+	for _, col := range board.Columns {
+		var fieldId = columnFieldPrefix + col.Id
+		value, ok := values[fieldId]
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value != "" {
+			if msg := validateColumn(col, value); msg != "" {
+				errs[fieldId] = msg
+				continue
+			}
+		}
+		columns[col.Id] = value
+	}
+	return columns
+}
+
+func validateColumn(col *pb.ColumnMeta, value string) string {
+	switch col.Type {
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != value {
+			return "Enter an email like jane@example.com"
+		}
+	case "phone":
+		if !phonePattern.MatchString(value) {
+			return "Enter a phone number, digits with an optional leading +"
+		}
+	case "numbers":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "Enter a number"
+		}
+	case "link":
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "Enter a link starting with http:// or https://"
+		}
+	case "status", "dropdown":
+		if !slices.Contains(col.Labels, value) {
+			return fmt.Sprintf("Pick one of %s", strings.Join(col.Labels, ", "))
+		}
+	}
+	return ""
+}
+
+func joinErrors(errs map[string]string) string {
+	var msgs = make([]string, 0, len(errs))
+	for _, msg := range errs {
+		msgs = append(msgs, msg)
+	}
+	slices.Sort(msgs)
+	return strings.Join(msgs, "; ")
+}
+
+func findBoard(boards []*pb.BoardDescription, name string) *pb.BoardDescription {
+	for _, b := range boards {
+		if strings.EqualFold(b.Name, name) {
+			return b
+		}
+	}
+	return nil
+}
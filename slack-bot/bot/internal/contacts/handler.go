@@ -0,0 +1,268 @@
+// Package contacts holds the bot's command handlers. They only talk to the
+// user through chat.Conversation, so every chat adapter shares them.
+package contacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/command"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+)
+
+const (
+	// board names are only used to catch typos, they can be a little stale
+	boardNamesTTL = 5 * time.Minute
+	// how long listing the boards may take before typos are let through
+	boardNamesTimeout = 1 * time.Second
+)
+
+type Handler struct {
+	ops      pb.MondayServiceClient
+	commands *command.Parser
+	searches *searches
+	boards   boardNames
+	policy   *authz.Policy
+	// tenant is the tenant of ops every caller is served from, the one of
+	// their team when empty
+	tenant string
+	links  *installLinks
+}
+
+// New returns the handlers of the commands users send prefixed with prefix,
+// e.g. /contact.
+func New(ops pb.MondayServiceClient, prefix string) *Handler {
+	var h = &Handler{ops: ops, searches: newSearches(), boards: boardNames{byCaller: map[string]*cachedNames{}}}
+	h.commands = h.newParser(prefix)
+	return h
+}
+
+// newParser defines the commands of the bot.
+func (h *Handler) newParser(prefix string) *command.Parser {
+	var board = command.Option{Name: "board", Usage: "Name of the contact board", Values: h.boardNames}
+	return command.New(prefix,
+		command.Command{
+			Name:        "find",
+			Aliases:     []string{"search"},
+			Args:        "NAME...",
+			MinArgs:     1,
+			Summary:     "Find contacts, best matches first",
+			Description: "Searches the name column unless col is given.",
+			Options: []command.Option{
+				{Name: board.Name, Usage: "Only show contacts from this board", Values: h.boardNames},
+				{Name: "col", Value: "COLUMN", Usage: "Id of the column to search, name by default"},
+			},
+			Examples: []string{`find John Smith`, `find "O'Brien" board="Contacts RO"`, `find col=email jane@example.com`},
+		},
+		command.Command{
+			Name:        "add",
+			Aliases:     []string{"new"},
+			Summary:     "Add a contact through a form",
+			Description: "Giving a name adds the contact right away, without a form.",
+			Options: []command.Option{
+				board,
+				{Name: "group", Usage: "Group of the board, its default group if not given"},
+				{Name: "name", Usage: "Name of the contact"},
+				{Name: "email", Usage: "Email of the contact"},
+				{Name: "phone", Usage: "Phone number of the contact"},
+			},
+			Examples: []string{`add`, `add board=Clients`, `add board=Clients name="Jane Doe" email=jane@example.com`},
+		},
+		command.Command{
+			Name:        "undo",
+			Summary:     "Revert your last change",
+			Description: "Deletes the contact or note you last added, or puts back what you last edited, for a few minutes after.",
+			Examples:    []string{`undo`},
+		},
+		command.Command{
+			Name:        "install",
+			Summary:     "Get a link to install ops in your monday account",
+			Description: "Only for the admins of the workspace. The link routes the workspace to the monday account it is followed into.",
+			Examples:    []string{`install`},
+		},
+		command.Command{
+			Name:     "help",
+			Args:     "[COMMAND]",
+			Summary:  "Show the commands, or the arguments and options of one",
+			Examples: []string{`help find`},
+		},
+	)
+}
+
+func (h *Handler) HandleMessage(ctx context.Context, conv chat.Conversation, msg chat.Message) {
+	ctx, req := startRequest(h.withCaller(ctx, msg.Caller), "command", "unknown", msg.Caller)
+	defer req.end()
+	var text = msg.Text
+	if strings.TrimSpace(text) == "" {
+		text = "add"
+	}
+	inv, err := h.commands.Parse(ctx, text)
+	if err != nil {
+		req.outcome = OUTCOME_INVALID
+		var usage *command.UsageError
+		if errors.As(err, &usage) {
+			req.name = usage.Command.Name
+			h.reply(ctx, conv, fmt.Sprintf("Usage: `%s`", usage.Usage))
+			return
+		}
+		h.reply(ctx, conv, fmt.Sprintf("Sorry, %s\nRun `%s` for the list of commands.", err, h.commands.Line("help")))
+		return
+	}
+	req.name = inv.Command.Name
+	switch inv.Command.Name {
+	case "help":
+		help, err := h.commands.Help(strings.Join(inv.Args, " "))
+		if err != nil {
+			h.reply(ctx, conv, fmt.Sprintf("Sorry, %s", err))
+			return
+		}
+		h.reply(ctx, conv, "```"+help+"```")
+	case "find":
+		h.find(ctx, conv, strings.Join(inv.Args, " "), inv.Option("board"), inv.Option("col"))
+	case "add":
+		h.add(ctx, conv, inv, msg.Id)
+	case "undo":
+		h.undo(ctx, conv)
+	case "install":
+		h.install(ctx, conv, msg)
+	}
+}
+
+func (h *Handler) HandleAction(ctx context.Context, conv chat.Conversation, action chat.Action) {
+	ctx, req := startRequest(h.withCaller(ctx, action.Caller), "action", action.Id, action.Caller)
+	defer req.end()
+	var err error
+	switch action.Id {
+	case editAction:
+		err = h.openEditContact(ctx, conv, action.Value)
+	case noteAction:
+		err = h.openAddNote(ctx, conv, action.Value)
+	case archiveAction:
+		err = h.archiveContact(ctx, conv, action.Value)
+	case moreAction:
+		err = h.showMore(ctx, conv, action.Value)
+	default:
+		req.name = "unknown"
+		slog.Debug("Ignoring action", "id", action.Id)
+	}
+	if err != nil {
+		req.outcome = OUTCOME_ERROR
+		log.Println(fmt.Errorf("failed to handle %s: %w", action.Id, err))
+	}
+}
+
+func (h *Handler) FormChanged(ctx context.Context, sub chat.Submission) (*chat.Form, error) {
+	ctx = h.withCaller(ctx, sub.Caller)
+	if sub.Form != addContactForm {
+		return nil, nil
+	}
+	return h.boardSelected(ctx, sub)
+}
+
+func (h *Handler) ValidateForm(ctx context.Context, sub chat.Submission) map[string]string {
+	ctx = h.withCaller(ctx, sub.Caller)
+	var errs map[string]string
+	switch sub.Form {
+	case addContactForm:
+		_, errs = h.parseAddContact(ctx, sub)
+	case editContactForm:
+		_, errs = h.parseEditContact(ctx, sub)
+	case addNoteForm:
+		_, errs = h.parseAddNote(ctx, sub)
+	}
+	if len(errs) > 0 {
+		handled.WithLabelValues("form", sub.Form, OUTCOME_INVALID).Inc()
+	}
+	return errs
+}
+
+func (h *Handler) SubmitForm(ctx context.Context, conv chat.Conversation, sub chat.Submission) {
+	ctx, req := startRequest(h.withCaller(ctx, sub.Caller), "form", sub.Form, sub.Caller)
+	defer req.end()
+	switch sub.Form {
+	case addContactForm:
+		h.submitAddContact(ctx, conv, sub)
+	case editContactForm:
+		h.submitEditContact(ctx, conv, sub)
+	case addNoteForm:
+		h.submitAddNote(ctx, conv, sub)
+	}
+}
+
+func (h *Handler) reply(ctx context.Context, conv chat.Conversation, text string) {
+	if err := conv.Reply(ctx, text); err != nil {
+		log.Println(fmt.Errorf("failed to reply: %w", err))
+	}
+}
+
+// outcome tells the user how a change they asked for went, in the thread
+// of the message or card it was asked from.
+func (h *Handler) outcome(ctx context.Context, conv chat.Conversation, text string) {
+	if err := conv.ReplyInThread(ctx, text); err != nil {
+		log.Println(fmt.Errorf("failed to reply: %w", err))
+	}
+}
+
+type boardNames struct {
+	mu       sync.Mutex
+	byCaller map[string]*cachedNames
+}
+
+type cachedNames struct {
+	names   []string
+	fetched time.Time
+}
+
+// boardNames lists the contact boards the caller in ctx can see, for the
+// board option to suggest the right one when a name is misspelled. When ops
+// cannot be reached any board is accepted.
+func (h *Handler) boardNames(ctx context.Context) []string {
+	var caller = callerOf(ctx)
+	var key = strings.Join([]string{caller.Tenant, caller.Team, caller.User, caller.Channel, strings.Join(caller.Groups, ",")}, "|")
+	if names, ok := h.boards.get(key); ok {
+		return names
+	}
+	ctx, cancel := context.WithTimeout(ctx, boardNamesTimeout)
+	defer cancel()
+	resp, err := h.ops.DescribeBoard(ctx, &pb.DescribeBoardRequest{})
+	if err != nil {
+		log.Println(fmt.Errorf("failed to list boards: %w", err))
+		return nil
+	}
+	var names = make([]string, 0, len(resp.Boards))
+	for _, board := range resp.Boards {
+		names = append(names, board.Name)
+	}
+	h.boards.put(key, names)
+	return names
+}
+
+// get returns the names cached for key. The lock is only held around the
+// map so a slow DescribeBoard for one caller doesn't hold up the others.
+func (b *boardNames) get(key string) ([]string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if cached, ok := b.byCaller[key]; ok && time.Since(cached.fetched) < boardNamesTTL {
+		return cached.names, true
+	}
+	return nil, false
+}
+
+func (b *boardNames) put(key string, names []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for k, cached := range b.byCaller {
+		if time.Since(cached.fetched) >= boardNamesTTL {
+			delete(b.byCaller, k)
+		}
+	}
+	b.byCaller[key] = &cachedNames{names: names, fetched: time.Now()}
+}
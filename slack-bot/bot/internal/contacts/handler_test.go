@@ -0,0 +1,90 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/installlink"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"google.golang.org/grpc"
+)
+
+// slowOps answers DescribeBoard, waiting for release once entered is set.
+type slowOps struct {
+	pb.MondayServiceClient
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (o *slowOps) DescribeBoard(ctx context.Context, req *pb.DescribeBoardRequest, opts ...grpc.CallOption) (*pb.DescribeBoardResponse, error) {
+	if o.entered != nil {
+		o.entered <- struct{}{}
+		<-o.release
+	}
+	return &pb.DescribeBoardResponse{Boards: []*pb.BoardDescription{{Name: "Clients"}}}, nil
+}
+
+func TestBoardNamesDoesNotWaitForOtherCallers(t *testing.T) {
+	var ops = &slowOps{}
+	var h = New(ops, "/contact")
+	var cached = h.withCaller(context.Background(), chat.Caller{User: "U1"})
+	h.boardNames(cached)
+
+	ops.entered, ops.release = make(chan struct{}), make(chan struct{})
+	defer close(ops.release)
+	go h.boardNames(h.withCaller(context.Background(), chat.Caller{User: "U2"}))
+	<-ops.entered
+
+	var done = make(chan []string)
+	go func() { done <- h.boardNames(cached) }()
+	select {
+	case names := <-done:
+		if fmt.Sprint(names) != "[Clients]" {
+			t.Errorf("boardNames() = %v, want the cached boards", names)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cached boards waited for another caller's DescribeBoard")
+	}
+}
+
+// replies records what the handler replied.
+type replies struct {
+	chat.Conversation
+	texts []string
+}
+
+func (r *replies) Reply(ctx context.Context, text string) error {
+	r.texts = append(r.texts, text)
+	return nil
+}
+
+func TestInstallLinksOnlyForAdmins(t *testing.T) {
+	var secret = []byte("link secret")
+	var h = New(&slowOps{}, "/contact")
+	h.UseInstallLinks(secret, "https://ops.example.com/oauth/install")
+
+	var member = &replies{}
+	h.HandleMessage(context.Background(), member, chat.Message{Text: "install", Caller: chat.Caller{User: "U2", Team: "T1"}})
+	if len(member.texts) != 1 || strings.Contains(member.texts[0], "https://") {
+		t.Fatalf("a member was answered %q, want a refusal", member.texts)
+	}
+
+	var admin = &replies{}
+	h.HandleMessage(context.Background(), admin, chat.Message{Text: "install", Caller: chat.Caller{User: "U1", Team: "T1"}, Admin: true})
+	if len(admin.texts) != 1 {
+		t.Fatalf("the admin was answered %q, want a link", admin.texts)
+	}
+	u, err := url.Parse(admin.texts[0][strings.Index(admin.texts[0], "https://"):])
+	if err != nil {
+		t.Fatal(err)
+	}
+	link, err := installlink.Verify(secret, u.Query(), time.Now())
+	if err != nil || link.Team != "T1" || link.User != "U1" {
+		t.Errorf("the admin got a link for %+v, %v, want T1 by U1", link, err)
+	}
+}
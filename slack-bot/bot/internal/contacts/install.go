@@ -0,0 +1,43 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/installlink"
+)
+
+type installLinks struct {
+	secret []byte
+	url    string
+}
+
+// UseInstallLinks lets the admins of a workspace install ops in their monday
+// account, through links to url, the install page of ops, signed with
+// secret. Without them there is nothing to install.
+func (h *Handler) UseInstallLinks(secret []byte, url string) {
+	h.links = &installLinks{secret: secret, url: url}
+}
+
+// install gives an admin a link to install ops for their workspace.
+func (h *Handler) install(ctx context.Context, conv chat.Conversation, msg chat.Message) {
+	switch {
+	case h.links == nil:
+		h.reply(ctx, conv, "Sorry, ops cannot be installed from here")
+		return
+	case !msg.Admin || msg.Team == "":
+		h.reply(ctx, conv, fmt.Sprintf("Sorry, only the admins of the workspace can install ops, privately with `%s`", h.commands.Line("install")))
+		return
+	}
+	var expiry = time.Now().Add(installlink.DEFAULT_TTL)
+	link, err := installlink.Sign(h.links.secret, h.links.url, installlink.Link{Team: msg.Team, User: msg.User, Expiry: expiry})
+	if err != nil {
+		log.Println(fmt.Errorf("failed to sign install link: %w", err))
+		h.reply(ctx, conv, "Sorry, the install link could not be made")
+		return
+	}
+	h.reply(ctx, conv, fmt.Sprintf("Install ops in your monday account before %s: %s", expiry.Format(time.Kitchen), link))
+}
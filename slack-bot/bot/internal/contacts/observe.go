@@ -0,0 +1,69 @@
+package contacts
+
+import (
+	"context"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	OUTCOME_OK      = "ok"
+	OUTCOME_INVALID = "invalid"
+	OUTCOME_ERROR   = "error"
+)
+
+var (
+	handled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "chat_handled_total",
+		Help: "Commands, button clicks and form submissions handled, by kind, name and outcome.",
+	}, []string{"kind", "name", "outcome"})
+	handlingDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "chat_handling_seconds",
+		Help:    "Time taken to handle a command, button click or form submission, by kind and name.",
+		Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"kind", "name"})
+)
+
+var tracer = otel.Tracer("github.com/CatalinCaprita/SPO/slack-bot/bot/internal/contacts")
+
+func init() {
+	prometheus.MustRegister(handled, handlingDuration)
+}
+
+// request is what a user asked for: a command, a click or a form. It is
+// counted, timed and traced from the moment it comes in until it is
+// handled, its name and outcome being known by then.
+type request struct {
+	kind    string
+	name    string
+	outcome string
+	start   time.Time
+	span    trace.Span
+}
+
+// startRequest starts a span for the request, the calls to ops made in the
+// returned context are part of it.
+func startRequest(ctx context.Context, kind, name string, caller chat.Caller) (context.Context, *request) {
+	ctx, span := tracer.Start(ctx, "chat "+kind, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+		attribute.String("chat.user", caller.User),
+		attribute.String("chat.channel", caller.Channel),
+	))
+	return ctx, &request{kind: kind, name: name, outcome: OUTCOME_OK, start: time.Now(), span: span}
+}
+
+func (r *request) end() {
+	handlingDuration.WithLabelValues(r.kind, r.name).Observe(time.Since(r.start).Seconds())
+	handled.WithLabelValues(r.kind, r.name, r.outcome).Inc()
+	r.span.SetName("chat " + r.kind + " " + r.name)
+	r.span.SetAttributes(attribute.String("chat.outcome", r.outcome))
+	if r.outcome != OUTCOME_OK {
+		r.span.SetStatus(codes.Error, r.outcome)
+	}
+	r.span.End()
+}
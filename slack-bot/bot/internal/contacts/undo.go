@@ -0,0 +1,39 @@
+package contacts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// undo reverts the last change the caller made that ops still keeps.
+func (h *Handler) undo(ctx context.Context, conv chat.Conversation) {
+	resp, err := h.ops.Revert(ctx, &pb.RevertRequest{})
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		h.outcome(ctx, conv, "There is no change of yours to undo")
+		return
+	default:
+		h.outcome(ctx, conv, fmt.Sprintf("Failed to undo: %s", status.Convert(err).Message()))
+		return
+	}
+	var board = resp.Board
+	if board == "" {
+		board = "its board"
+	}
+	switch resp.Method {
+	case "CreateItem":
+		h.outcome(ctx, conv, fmt.Sprintf("Deleted the contact you added to %s", board))
+	case "UpdateItem":
+		h.outcome(ctx, conv, fmt.Sprintf("Put back the contact you edited in %s", board))
+	case "AddNote":
+		h.outcome(ctx, conv, "Deleted the note you added")
+	default:
+		h.outcome(ctx, conv, fmt.Sprintf("Reverted your %s", resp.Method))
+	}
+}
@@ -0,0 +1,283 @@
+// Package repl is a chat adapter for the terminal, to try the bot's
+// commands without a chat server. Cards are printed with numbered buttons
+// and forms are filled in field by field.
+package repl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+)
+
+const (
+	// answering a field with clear empties it, an empty answer keeps the value
+	clear  = "-"
+	cancel = "!cancel"
+)
+
+// Terminal reads commands from in, one per line, and writes the answers to
+// out.
+type Terminal struct {
+	in      io.Reader
+	out     io.Writer
+	user    string
+	lines   chan string
+	handler chat.Handler
+	// buttons are numbered across the session so older cards stay usable
+	buttons []chat.Button
+}
+
+func New(in io.Reader, out io.Writer) *Terminal {
+	var user = os.Getenv("USER")
+	if user == "" {
+		user = "terminal"
+	}
+	return &Terminal{in: in, out: out, user: user}
+}
+
+// Prefix is empty, commands are typed as they are, e.g. find John.
+func (t *Terminal) caller() chat.Caller {
+	return chat.Caller{User: t.user}
+}
+
+func (t *Terminal) Prefix() string {
+	return ""
+}
+
+func (t *Terminal) Run(ctx context.Context, h chat.Handler) error {
+	t.handler = h
+	t.lines = make(chan string)
+	var scanErr = make(chan error, 1)
+	go func() {
+		var scanner = bufio.NewScanner(t.in)
+		for scanner.Scan() {
+			t.lines <- scanner.Text()
+		}
+		scanErr <- scanner.Err()
+		close(t.lines)
+	}()
+	fmt.Fprintln(t.out, "Type a command, help for the list of commands, click N to press button N, quit to leave.")
+	for {
+		line, ok := t.read(ctx, "> ")
+		if !ok {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return <-scanErr
+		}
+		for _, prefix := range []string{"@contact", "/contact"} {
+			line = strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		}
+		switch {
+		case line == "quit" || line == "exit":
+			return nil
+		case strings.HasPrefix(line, "click "):
+			t.click(ctx, strings.TrimSpace(strings.TrimPrefix(line, "click ")))
+		default:
+			t.handler.HandleMessage(ctx, t, chat.Message{Text: line, Caller: t.caller()})
+		}
+	}
+}
+
+// read prompts for a line, it returns false once the input or ctx is done.
+func (t *Terminal) read(ctx context.Context, prompt string) (string, bool) {
+	fmt.Fprint(t.out, prompt)
+	select {
+	case line, ok := <-t.lines:
+		return strings.TrimSpace(line), ok
+	case <-ctx.Done():
+		fmt.Fprintln(t.out)
+		return "", false
+	}
+}
+
+func (t *Terminal) click(ctx context.Context, number string) {
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 || n > len(t.buttons) {
+		fmt.Fprintf(t.out, "There is no button %s\n", number)
+		return
+	}
+	var button = t.buttons[n-1]
+	if button.Confirm != "" {
+		answer, ok := t.read(ctx, button.Confirm+" [y/N] ")
+		if !ok || !strings.EqualFold(answer, "y") {
+			return
+		}
+	}
+	t.handler.HandleAction(ctx, t, chat.Action{Id: button.Action, Value: button.Value, Caller: t.caller()})
+}
+
+func (t *Terminal) Reply(ctx context.Context, text string) error {
+	fmt.Fprintln(t.out, strings.ReplaceAll(text, "```", ""))
+	return nil
+}
+
+func (t *Terminal) ReplyInThread(ctx context.Context, text string) error {
+	fmt.Fprintf(t.out, "  ↳ %s\n", text)
+	return nil
+}
+
+func (t *Terminal) PostCards(ctx context.Context, post chat.Post) error {
+	if post.Text != "" {
+		fmt.Fprintln(t.out, post.Text)
+	}
+	for _, card := range post.Cards {
+		fmt.Fprintf(t.out, "\n  %s", card.Title)
+		if card.Subtitle != "" {
+			fmt.Fprintf(t.out, " · %s", card.Subtitle)
+		}
+		fmt.Fprintln(t.out)
+		for _, f := range card.Fields {
+			fmt.Fprintf(t.out, "  %s: %s\n", f.Label, f.Value)
+		}
+		if card.Note != "" {
+			fmt.Fprintf(t.out, "  (%s)\n", card.Note)
+		}
+		t.printButtons(card.Buttons)
+	}
+	if post.Footer != "" {
+		fmt.Fprintf(t.out, "\n%s\n", post.Footer)
+	}
+	t.printButtons(post.Buttons)
+	return nil
+}
+
+// printButtons numbers the buttons that send actions and prints the links
+// of the others.
+func (t *Terminal) printButtons(buttons []chat.Button) {
+	var labels = []string{}
+	var links = []string{}
+	for _, b := range buttons {
+		if b.Link != "" {
+			links = append(links, fmt.Sprintf("  %s: %s", b.Label, b.Link))
+			continue
+		}
+		t.buttons = append(t.buttons, b)
+		labels = append(labels, fmt.Sprintf("[%d] %s", len(t.buttons), b.Label))
+	}
+	if len(labels) > 0 {
+		fmt.Fprintf(t.out, "  %s\n", strings.Join(labels, "  "))
+	}
+	for _, l := range links {
+		fmt.Fprintln(t.out, l)
+	}
+}
+
+// OpenForm asks for the fields of form one by one, then submits it. Fields
+// the handler rejects are asked again.
+func (t *Terminal) OpenForm(ctx context.Context, form chat.Form) error {
+	fmt.Fprintf(t.out, "%s (%s to give up, %s to clear a value)\n", form.Title, cancel, clear)
+	var sub = chat.Submission{Form: form.Id, State: form.State, Values: map[string]string{}, Caller: t.caller()}
+	var answered = map[string]bool{}
+	for {
+		var i = slices.IndexFunc(form.Fields, func(f chat.FormField) bool { return !answered[f.Id] })
+		if i < 0 {
+			errs := t.handler.ValidateForm(ctx, sub)
+			if len(errs) == 0 {
+				break
+			}
+			var retry = false
+			for _, f := range form.Fields {
+				if msg, ok := errs[f.Id]; ok {
+					fmt.Fprintf(t.out, "%s: %s\n", f.Label, msg)
+					delete(answered, f.Id)
+					retry = true
+				}
+			}
+			if !retry {
+				// none of the fields can fix it
+				for _, msg := range errs {
+					fmt.Fprintln(t.out, msg)
+				}
+				return nil
+			}
+			continue
+		}
+		var field = form.Fields[i]
+		var previous, seen = sub.Values[field.Id]
+		if !seen {
+			previous = field.Value
+		}
+		value, ok := t.ask(ctx, field, previous)
+		if !ok {
+			fmt.Fprintln(t.out, "Cancelled")
+			return nil
+		}
+		sub.Values[field.Id], answered[field.Id] = value, true
+		if field.Reload && value != previous {
+			changed, err := t.handler.FormChanged(ctx, sub)
+			if err != nil {
+				return err
+			}
+			if changed != nil {
+				form, sub.State = *changed, changed.State
+			}
+		}
+	}
+	t.handler.SubmitForm(ctx, t, sub)
+	return nil
+}
+
+// ask reads the value of field until it is a valid one.
+func (t *Terminal) ask(ctx context.Context, field chat.FormField, previous string) (string, bool) {
+	var prompt = field.Label
+	if field.Optional {
+		prompt += " (optional)"
+	}
+	if field.Type == chat.FIELD_DATE {
+		prompt += " (YYYY-MM-DD)"
+	}
+	if previous != "" {
+		prompt += fmt.Sprintf(" [%s]", previous)
+	}
+	if field.Type == chat.FIELD_SELECT {
+		for i, o := range field.Options {
+			fmt.Fprintf(t.out, "  %d. %s\n", i+1, o)
+		}
+	}
+	for {
+		answer, ok := t.read(ctx, prompt+": ")
+		if !ok || answer == cancel {
+			return "", false
+		}
+		var value = answer
+		switch answer {
+		case "":
+			value = previous
+		case clear:
+			value = ""
+		}
+		if field.Type == chat.FIELD_SELECT && value != "" {
+			value = choose(field.Options, value)
+			if value == "" {
+				fmt.Fprintf(t.out, "Pick one of the %d options, by number or name\n", len(field.Options))
+				continue
+			}
+		}
+		if value == "" && !field.Optional {
+			fmt.Fprintln(t.out, "This field is required")
+			continue
+		}
+		return value, true
+	}
+}
+
+// choose returns the option answer is the number or name of, or "".
+func choose(options []string, answer string) string {
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return options[n-1]
+	}
+	for _, o := range options {
+		if strings.EqualFold(o, answer) {
+			return o
+		}
+	}
+	return ""
+}
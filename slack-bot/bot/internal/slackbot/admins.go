@@ -0,0 +1,43 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// admins rarely change, a stale list only lasts this long
+const adminsTTL = 5 * time.Minute
+
+// workspaceAdmins caches the admins and owners of the workspace, listed
+// with the users:read scope.
+type workspaceAdmins struct {
+	mu      sync.Mutex
+	users   map[string]bool
+	fetched time.Time
+}
+
+// isAdmin tells whether user administers or owns the workspace.
+func (b *Bot) isAdmin(ctx context.Context, user string) bool {
+	b.admins.mu.Lock()
+	defer b.admins.mu.Unlock()
+	if b.admins.users != nil && time.Since(b.admins.fetched) < adminsTTL {
+		return b.admins.users[user]
+	}
+	// failures are not retried before the TTL either, nobody is an admin
+	// until then
+	b.admins.users, b.admins.fetched = map[string]bool{}, time.Now()
+	users, err := b.api.GetUsersContext(ctx)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to list users: %w", err))
+		return false
+	}
+	for _, u := range users {
+		if u.IsAdmin || u.IsOwner {
+			b.admins.users[u.ID] = true
+		}
+	}
+	return b.admins.users[user]
+}
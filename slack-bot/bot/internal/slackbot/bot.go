@@ -0,0 +1,277 @@
+// Package slackbot is the chat adapter for slack: the /contact slash command,
+// mentions and direct messages, answered with Block Kit cards and modals.
+package slackbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const (
+	PREFIX = "/contact"
+	// slack expects an answer to commands and interactions within 3 seconds
+	ackTimeout = 2500 * time.Millisecond
+	// how long handlers may run once slack got its answer
+	opsTimeout = 30 * time.Second
+)
+
+// Bot answers the slash commands, events and interactive payloads slack
+// posts to it by calling the chat handler.
+type Bot struct {
+	api     *slack.Client
+	secret  string
+	addr    string
+	handler chat.Handler
+	groups  userGroups
+	admins  workspaceAdmins
+}
+
+func New(api *slack.Client, signingSecret, addr string) *Bot {
+	return &Bot{api: api, secret: signingSecret, addr: addr}
+}
+
+func (b *Bot) Prefix() string {
+	return PREFIX
+}
+
+func (b *Bot) Run(ctx context.Context, h chat.Handler) error {
+	b.handler = h
+	var server = &http.Server{Addr: b.addr, Handler: b.Handler()}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	log.Printf("Listening for slack on %s", b.addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (b *Bot) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /slack/commands", b.verified(b.handleCommand))
+	mux.HandleFunc("POST /slack/events", b.verified(b.handleEvent))
+	mux.HandleFunc("POST /slack/interactions", b.verified(b.handleInteraction))
+	return mux
+}
+
+// verified rejects requests that were not signed with the app's signing secret.
+func (b *Bot) verified(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		verifier, err := slack.NewSecretsVerifier(r.Header, b.secret)
+		if err != nil {
+			http.Error(w, "unsigned request", http.StatusUnauthorized)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		verifier.Write(body)
+		if err := verifier.Ensure(); err != nil {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next(w, r)
+	}
+}
+
+// later runs fn once slack got its answer.
+func later(fn func(ctx context.Context)) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), opsTimeout)
+		defer cancel()
+		fn(ctx)
+	}()
+}
+
+func (b *Bot) handleCommand(w http.ResponseWriter, r *http.Request) {
+	cmd, err := slack.SlashCommandParse(r)
+	if err != nil {
+		http.Error(w, "invalid command", http.StatusBadRequest)
+		return
+	}
+	if cmd.Command != PREFIX {
+		fmt.Fprintf(w, "Unknown command %s, try `%s help`", cmd.Command, PREFIX)
+		return
+	}
+	var conv = &conversation{
+		api:         b.api,
+		user:        cmd.UserID,
+		channel:     cmd.ChannelID,
+		responseURL: cmd.ResponseURL,
+		triggerID:   cmd.TriggerID,
+	}
+	later(func(ctx context.Context) {
+		var msg = chat.Message{
+			Id:     "command:" + cmd.TriggerID,
+			Text:   slackText(cmd.Text),
+			Caller: b.caller(ctx, cmd.TeamID, cmd.UserID, cmd.ChannelID),
+			// slash commands are answered privately, mentions are not
+			Admin: b.isAdmin(ctx, cmd.UserID),
+		}
+		b.handler.HandleMessage(ctx, conv, msg)
+	})
+	w.WriteHeader(http.StatusOK)
+}
+
+var mention = regexp.MustCompile(`^\s*<@[A-Z0-9]+>\s*`)
+
+// handleEvent answers mentions of the bot and direct messages to it, in the
+// thread of the message.
+func (b *Bot) handleEvent(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	event, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+	if err != nil {
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+	if event.Type == slackevents.URLVerification {
+		var challenge = slackevents.ChallengeResponse{}
+		json.Unmarshal(body, &challenge)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(challenge.Challenge))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	// id is the message, ts the thread it is in
+	var text, user, channel, id, ts string
+	switch ev := event.InnerEvent.Data.(type) {
+	case *slackevents.AppMentionEvent:
+		if ev.BotID != "" {
+			return
+		}
+		text, user, channel, id, ts = ev.Text, ev.User, ev.Channel, ev.Channel+":"+ev.TimeStamp, thread(ev.ThreadTimeStamp, ev.TimeStamp)
+	case *slackevents.MessageEvent:
+		// only direct messages, mentions in channels arrive as app_mention
+		if ev.ChannelType != slackevents.ChannelTypeIM || ev.BotID != "" || ev.SubType != "" {
+			return
+		}
+		text, user, channel, id, ts = ev.Text, ev.User, ev.Channel, ev.Channel+":"+ev.TimeStamp, thread(ev.ThreadTimeStamp, ev.TimeStamp)
+	default:
+		slog.Debug("Ignoring event", "type", event.InnerEvent.Type)
+		return
+	}
+	var conv = &conversation{api: b.api, user: user, channel: channel, thread: ts, public: true}
+	later(func(ctx context.Context) {
+		var msg = chat.Message{
+			Id:     "message:" + id,
+			Text:   slackText(mention.ReplaceAllString(text, "")),
+			Caller: b.caller(ctx, event.TeamID, user, channel),
+			Thread: ts,
+		}
+		b.handler.HandleMessage(ctx, conv, msg)
+	})
+}
+
+// thread returns the thread a message is in, or starts one on it.
+func thread(threadTs, ts string) string {
+	if threadTs != "" {
+		return threadTs
+	}
+	return ts
+}
+
+func (b *Bot) handleInteraction(w http.ResponseWriter, r *http.Request) {
+	callback, err := slack.InteractionCallbackParse(r)
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), ackTimeout)
+	defer cancel()
+	switch callback.Type {
+	case slack.InteractionTypeBlockActions:
+		if callback.View.ID != "" {
+			if err := b.formChanged(ctx, callback); err != nil {
+				log.Println(fmt.Errorf("failed to update %s modal: %w", callback.View.CallbackID, err))
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var conv = &conversation{
+			api:         b.api,
+			user:        callback.User.ID,
+			channel:     callback.Channel.ID,
+			thread:      callback.Container.ThreadTs,
+			public:      !callback.Container.IsEphemeral,
+			responseURL: callback.ResponseURL,
+			triggerID:   callback.TriggerID,
+		}
+		for _, a := range callback.ActionCallback.BlockActions {
+			if strings.HasPrefix(a.ActionID, linkAction) {
+				// link buttons only need to be acknowledged
+				continue
+			}
+			later(func(ctx context.Context) {
+				var action = chat.Action{Id: a.ActionID, Value: a.Value, Caller: b.caller(ctx, callback.Team.ID, conv.user, conv.channel)}
+				b.handler.HandleAction(ctx, conv, action)
+			})
+		}
+		w.WriteHeader(http.StatusOK)
+	case slack.InteractionTypeViewSubmission:
+		var metadata = parseMetadata(callback.View.PrivateMetadata)
+		var sub = b.submission(ctx, callback)
+		if errs := b.handler.ValidateForm(ctx, sub); len(errs) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(slack.NewErrorsViewSubmissionResponse(errs))
+			return
+		}
+		var conv = &conversation{
+			api:     b.api,
+			user:    callback.User.ID,
+			channel: metadata.Channel,
+			thread:  metadata.Thread,
+			public:  metadata.Public,
+		}
+		later(func(ctx context.Context) { b.handler.SubmitForm(ctx, conv, sub) })
+		w.WriteHeader(http.StatusOK)
+	default:
+		slog.Debug("Ignoring interaction", "type", callback.Type)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// formChanged re-renders a modal after one of its Reload fields changed.
+func (b *Bot) formChanged(ctx context.Context, callback slack.InteractionCallback) error {
+	form, err := b.handler.FormChanged(ctx, b.submission(ctx, callback))
+	if err != nil || form == nil {
+		return err
+	}
+	var metadata = parseMetadata(callback.View.PrivateMetadata)
+	_, err = b.api.UpdateViewContext(ctx, modalView(*form, metadata), "", callback.View.Hash, callback.View.ID)
+	return err
+}
+
+var slackLink = regexp.MustCompile(`<((?:mailto:|tel:)?)([^<>|]*)(?:\|([^<>]*))?>`)
+
+// slackText undoes slack's formatting of message text: links become their
+// label, or their target without the mailto: or tel: scheme, and the
+// escaped &, < and > are restored.
+func slackText(text string) string {
+	text = slackLink.ReplaceAllStringFunc(text, func(link string) string {
+		var m = slackLink.FindStringSubmatch(link)
+		if m[3] != "" {
+			return m[3]
+		}
+		return m[2]
+	})
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(text)
+}
@@ -0,0 +1,279 @@
+package slackbot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/contacts"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"github.com/slack-go/slack"
+	"google.golang.org/grpc"
+)
+
+const signingSecret = "test-secret"
+
+// standIn plays the Slack Web API the way cmd/slackstub does, handing what
+// the bot sends to the test.
+type standIn struct {
+	*httptest.Server
+	mu       sync.Mutex
+	views    chan *slack.View
+	messages chan string
+	opened   int
+}
+
+func newStandIn(t *testing.T) *standIn {
+	var s = &standIn{views: make(chan *slack.View, 10), messages: make(chan string, 10)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/views.open", s.view)
+	mux.HandleFunc("/api/views.update", s.view)
+	mux.HandleFunc("/api/chat.postMessage", s.message)
+	mux.HandleFunc("/api/chat.postEphemeral", s.message)
+	mux.HandleFunc("/api/usergroups.list", func(w http.ResponseWriter, r *http.Request) {
+		writeOk(w, map[string]any{"usergroups": []any{}})
+	})
+	mux.HandleFunc("/api/users.list", func(w http.ResponseWriter, r *http.Request) {
+		writeOk(w, map[string]any{"members": []any{}})
+	})
+	mux.HandleFunc("/response", func(w http.ResponseWriter, r *http.Request) {
+		var msg = slack.WebhookMessage{}
+		json.NewDecoder(r.Body).Decode(&msg)
+		s.messages <- msg.Text
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *standIn) view(w http.ResponseWriter, r *http.Request) {
+	var req = struct {
+		View   slack.View `json:"view"`
+		ViewId string     `json:"view_id"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	if req.ViewId == "" {
+		s.opened++
+		req.ViewId = "V" + strconv.Itoa(s.opened)
+	}
+	s.mu.Unlock()
+	req.View.ID = req.ViewId
+	s.views <- &req.View
+	writeOk(w, map[string]any{"view": req.View})
+}
+
+func (s *standIn) message(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	s.messages <- r.FormValue("text")
+	writeOk(w, map[string]any{"channel": r.FormValue("channel"), "ts": "1.000001", "message_ts": "1.000001"})
+}
+
+func writeOk(w http.ResponseWriter, fields map[string]any) {
+	fields["ok"] = true
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fields)
+}
+
+// next returns the next value sent on c, failing the test when none comes.
+func next[T any](t *testing.T, c chan T, what string) T {
+	t.Helper()
+	select {
+	case v := <-c:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no %s", what)
+	}
+	var zero T
+	return zero
+}
+
+// fakeOps answers the board descriptions and keeps the items created.
+type fakeOps struct {
+	pb.MondayServiceClient
+	boards  []*pb.BoardDescription
+	created chan *pb.CreateItemRequest
+}
+
+func (f *fakeOps) DescribeBoard(ctx context.Context, req *pb.DescribeBoardRequest, opts ...grpc.CallOption) (*pb.DescribeBoardResponse, error) {
+	var resp = &pb.DescribeBoardResponse{}
+	for _, b := range f.boards {
+		if req.Board == "" || strings.EqualFold(req.Board, b.Name) {
+			resp.Boards = append(resp.Boards, b)
+		}
+	}
+	return resp, nil
+}
+
+func (f *fakeOps) CreateItem(ctx context.Context, req *pb.CreateItemRequest, opts ...grpc.CallOption) (*pb.CreateItemResponse, error) {
+	f.created <- req
+	return &pb.CreateItemResponse{Id: "1"}, nil
+}
+
+// post sends form to the bot, signed the way Slack signs its requests.
+func post(t *testing.T, bot *httptest.Server, path string, form url.Values) (int, string) {
+	t.Helper()
+	var body = form.Encode()
+	var ts = strconv.FormatInt(time.Now().Unix(), 10)
+	var mac = hmac.New(sha256.New, []byte(signingSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+	req, _ := http.NewRequest(http.MethodPost, bot.URL+path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	reply, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, strings.TrimSpace(string(reply))
+}
+
+func interact(t *testing.T, bot *httptest.Server, callback slack.InteractionCallback) (int, string) {
+	t.Helper()
+	payload, err := json.Marshal(&callback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return post(t, bot, "/slack/interactions", url.Values{"payload": {string(payload)}})
+}
+
+// inputs returns the input blocks of view by block id.
+func inputs(view *slack.View) map[string]*slack.InputBlock {
+	var found = map[string]*slack.InputBlock{}
+	for _, block := range view.Blocks.BlockSet {
+		if input, ok := block.(*slack.InputBlock); ok {
+			found[input.BlockID] = input
+		}
+	}
+	return found
+}
+
+func options(input *slack.InputBlock) []string {
+	var values = []string{}
+	if sel, ok := input.Element.(*slack.SelectBlockElement); ok {
+		for _, o := range sel.Options {
+			values = append(values, o.Value)
+		}
+	}
+	return values
+}
+
+// filled returns view with values typed in its inputs, by block id.
+func filled(view *slack.View, values map[string]string) slack.View {
+	var state = map[string]map[string]slack.BlockAction{}
+	for blockId, value := range values {
+		var action = slack.BlockAction{ActionID: valueAction, BlockID: blockId, Value: value}
+		if _, ok := inputs(view)[blockId].Element.(*slack.SelectBlockElement); ok {
+			action = slack.BlockAction{ActionID: valueAction, BlockID: blockId, Type: slack.ActionType(slack.OptTypeStatic),
+				SelectedOption: slack.OptionBlockObject{Value: value}}
+		}
+		state[blockId] = map[string]slack.BlockAction{valueAction: action}
+	}
+	var v = *view
+	v.State = &slack.ViewState{Values: state}
+	return v
+}
+
+func TestAddContactThroughModal(t *testing.T) {
+	var slackApi = newStandIn(t)
+	var ops = &fakeOps{created: make(chan *pb.CreateItemRequest, 1), boards: []*pb.BoardDescription{
+		{Name: "Clients", Groups: []*pb.GroupMeta{{Id: "g1", Title: "Leads"}}, Columns: []*pb.ColumnMeta{
+			{Id: "name", Title: "Name", Type: "name"},
+			{Id: "email", Title: "Email", Type: "email"},
+		}},
+		{Name: "Suppliers"},
+	}}
+	var b = New(slack.New("xoxb-test", slack.OptionAPIURL(slackApi.URL+"/api/")), signingSecret, "")
+	b.handler = contacts.New(ops, PREFIX)
+	var bot = httptest.NewServer(b.Handler())
+	defer bot.Close()
+
+	code, _ := post(t, bot, "/slack/commands", url.Values{
+		"command": {PREFIX}, "text": {"add"}, "user_id": {"U1"}, "channel_id": {"C1"}, "team_id": {"T1"},
+		"trigger_id": {"trigger"}, "response_url": {slackApi.URL + "/response"},
+	})
+	if code != http.StatusOK {
+		t.Fatalf("command answered %d", code)
+	}
+	var view = next(t, slackApi.views, "modal opened")
+	if got := options(inputs(view)["board"]); fmt.Sprint(got) != "[Clients Suppliers]" {
+		t.Fatalf("board options = %v, want the boards listed", got)
+	}
+	if _, ok := inputs(view)["col:email"]; ok {
+		t.Fatal("columns shown before a board was picked")
+	}
+
+	// picking a board loads its groups and columns
+	var pick = filled(view, map[string]string{"board": "Clients"})
+	var action = pick.State.Values["board"][valueAction]
+	interact(t, bot, slack.InteractionCallback{
+		Type: slack.InteractionTypeBlockActions, User: slack.User{ID: "U1"}, Team: slack.Team{ID: "T1"}, View: pick,
+		ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{&action}},
+	})
+	view = next(t, slackApi.views, "modal updated")
+	if got := options(inputs(view)["group"]); fmt.Sprint(got) != "[Leads]" {
+		t.Errorf("group options = %v, want the groups of Clients", got)
+	}
+	if _, ok := inputs(view)["col:email"]; !ok {
+		t.Fatalf("no email field in %v", inputs(view))
+	}
+
+	// invalid fields are answered inline, without adding anything
+	var submission = slack.InteractionCallback{Type: slack.InteractionTypeViewSubmission, User: slack.User{ID: "U1"}, Team: slack.Team{ID: "T1"}}
+	submission.View = filled(view, map[string]string{"board": "Clients", "name": "Jane Doe", "col:email": "not an email"})
+	code, body := interact(t, bot, submission)
+	var answer = slack.ViewSubmissionResponse{}
+	json.Unmarshal([]byte(body), &answer)
+	if code != http.StatusOK || answer.ResponseAction != slack.RAErrors || answer.Errors["col:email"] == "" {
+		t.Fatalf("invalid submission answered %d %s, want an error on col:email", code, body)
+	}
+	select {
+	case req := <-ops.created:
+		t.Fatalf("invalid submission created %v", req)
+	default:
+	}
+
+	submission.View = filled(view, map[string]string{"board": "Clients", "group": "Leads", "name": "Jane Doe", "col:email": "jane@example.com"})
+	if code, body := interact(t, bot, submission); code != http.StatusOK || body != "" {
+		t.Fatalf("valid submission answered %d %s, want the modal closed", code, body)
+	}
+	var created = next(t, ops.created, "item created")
+	if created.Board != "Clients" || created.Group != "Leads" || created.Name != "Jane Doe" ||
+		created.Columns["email"] != "jane@example.com" || created.IdempotencyKey != "view:"+view.ID {
+		t.Errorf("created %v", created)
+	}
+	if msg := next(t, slackApi.messages, "outcome"); msg != "Added Jane Doe to Clients" {
+		t.Errorf("answered %q", msg)
+	}
+}
+
+func TestUnsignedRequestsAreRefused(t *testing.T) {
+	var b = New(slack.New("xoxb-test"), signingSecret, "")
+	var bot = httptest.NewServer(b.Handler())
+	defer bot.Close()
+	resp, err := http.PostForm(bot.URL+"/slack/commands", url.Values{"command": {PREFIX}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unsigned command answered %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,85 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/slack-go/slack"
+)
+
+// conversation answers a user where they reached the bot from. Public
+// conversations (mentions, direct messages and the cards posted there)
+// are answered in the thread of the message, the others privately: through
+// the response_url of slash commands and ephemeral cards, or with an
+// ephemeral message once the response_url is gone, e.g. after a modal.
+type conversation struct {
+	api         *slack.Client
+	user        string
+	channel     string
+	thread      string
+	public      bool
+	responseURL string
+	// triggerID allows opening a modal, for 3 seconds after the user acted
+	triggerID string
+}
+
+func (c *conversation) Reply(ctx context.Context, text string) error {
+	return c.post(ctx, escape(text), nil)
+}
+
+// ReplyInThread is the same as Reply: public conversations are always
+// answered in a thread and private answers cannot have threads.
+func (c *conversation) ReplyInThread(ctx context.Context, text string) error {
+	return c.post(ctx, escape(text), nil)
+}
+
+func (c *conversation) PostCards(ctx context.Context, post chat.Post) error {
+	var text = post.Text
+	if text == "" && len(post.Cards) > 0 {
+		text = post.Cards[0].Title
+	}
+	return c.post(ctx, escape(text), postBlocks(post))
+}
+
+func (c *conversation) OpenForm(ctx context.Context, form chat.Form) error {
+	if c.triggerID == "" {
+		return chat.ErrNotSupported
+	}
+	var metadata = modalMetadata{Channel: c.channel, Thread: c.thread, Public: c.public}
+	_, err := c.api.OpenViewContext(ctx, c.triggerID, modalView(form, metadata))
+	return err
+}
+
+func (c *conversation) post(ctx context.Context, text string, blocks []slack.Block) error {
+	if c.public {
+		var options = []slack.MsgOption{slack.MsgOptionText(text, false), slack.MsgOptionTS(c.thread)}
+		if blocks != nil {
+			options = append(options, slack.MsgOptionBlocks(blocks...))
+		}
+		_, _, err := c.api.PostMessageContext(ctx, c.channel, options...)
+		return err
+	}
+	if c.responseURL != "" {
+		var msg = &slack.WebhookMessage{Text: text}
+		if blocks != nil {
+			msg.Blocks = &slack.Blocks{BlockSet: blocks}
+		}
+		return slack.PostWebhookContext(ctx, c.responseURL, msg)
+	}
+	var options = []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if blocks != nil {
+		options = append(options, slack.MsgOptionBlocks(blocks...))
+	}
+	var err error
+	if c.channel != "" {
+		_, err = c.api.PostEphemeralContext(ctx, c.channel, c.user, options...)
+	}
+	if c.channel == "" || err != nil {
+		// not a member of the channel, fall back to a direct message
+		if _, _, err = c.api.PostMessageContext(ctx, c.user, options...); err != nil {
+			return fmt.Errorf("failed to message %s: %w", c.user, err)
+		}
+	}
+	return nil
+}
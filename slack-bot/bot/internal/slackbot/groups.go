@@ -0,0 +1,51 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/slack-go/slack"
+)
+
+// user groups rarely change, a stale membership only lasts this long
+const groupsTTL = 5 * time.Minute
+
+// userGroups caches the user groups of every user, listed with the
+// usergroups:read scope.
+type userGroups struct {
+	mu      sync.Mutex
+	byUser  map[string][]string
+	fetched time.Time
+}
+
+// caller returns who user of team is for the handlers, with their user
+// groups.
+func (b *Bot) caller(ctx context.Context, team, user, channel string) chat.Caller {
+	return chat.Caller{User: user, Groups: b.groupsOf(ctx, user), Channel: channel, Team: team}
+}
+
+func (b *Bot) groupsOf(ctx context.Context, user string) []string {
+	b.groups.mu.Lock()
+	defer b.groups.mu.Unlock()
+	if b.groups.byUser != nil && time.Since(b.groups.fetched) < groupsTTL {
+		return b.groups.byUser[user]
+	}
+	// failures are not retried before the TTL either, the callers then
+	// only get the rules for their user and channel
+	b.groups.byUser, b.groups.fetched = map[string][]string{}, time.Now()
+	groups, err := b.api.GetUserGroupsContext(ctx, slack.GetUserGroupsOptionIncludeUsers(true))
+	if err != nil {
+		log.Println(fmt.Errorf("failed to list user groups: %w", err))
+		return nil
+	}
+	for _, g := range groups {
+		for _, u := range g.Users {
+			b.groups.byUser[u] = append(b.groups.byUser[u], g.ID)
+		}
+	}
+	return b.groups.byUser[user]
+}
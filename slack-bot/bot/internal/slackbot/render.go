@@ -0,0 +1,207 @@
+package slackbot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// every input of a modal uses the same action id, blocks tell them apart
+	valueAction = "value"
+	// prefix of the action ids of link buttons, which slack sends too
+	linkAction = "link:"
+)
+
+// modalMetadata travels with a modal through private_metadata, for the
+// submission to be answered where the form was opened from.
+type modalMetadata struct {
+	Channel string `json:"c,omitempty"`
+	Thread  string `json:"t,omitempty"`
+	Public  bool   `json:"p,omitempty"`
+	State   string `json:"s,omitempty"`
+}
+
+func parseMetadata(raw string) modalMetadata {
+	var metadata = modalMetadata{}
+	json.Unmarshal([]byte(raw), &metadata)
+	return metadata
+}
+
+// postBlocks renders post as Block Kit blocks, each card a section followed
+// by its note and buttons.
+func postBlocks(post chat.Post) []slack.Block {
+	var blocks = []slack.Block{}
+	if post.Text != "" {
+		blocks = append(blocks, slack.NewSectionBlock(markdown(escape(post.Text)), nil, nil), slack.NewDividerBlock())
+	}
+	var links = 0
+	var buttons = func(blockId string, buttons []chat.Button) slack.Block {
+		var elements = make([]slack.BlockElement, 0, len(buttons))
+		for _, b := range buttons {
+			var button = slack.NewButtonBlockElement(b.Action, b.Value, plain(b.Label))
+			if b.Link != "" {
+				links++
+				button = slack.NewButtonBlockElement(fmt.Sprintf("%s%d", linkAction, links), "", plain(b.Label)).WithURL(b.Link)
+			}
+			if b.Danger {
+				button.WithStyle(slack.StyleDanger)
+			}
+			if b.Confirm != "" {
+				button.WithConfirm(slack.NewConfirmationBlockObject(plain(b.Label), plain(b.Confirm), plain(b.Label), plain("Cancel")))
+			}
+			elements = append(elements, button)
+		}
+		return slack.NewActionBlock(blockId, elements...)
+	}
+	for i, card := range post.Cards {
+		var title = "*" + escape(card.Title) + "*"
+		if card.Link != "" {
+			title = fmt.Sprintf("*<%s|%s>*", card.Link, escape(card.Title))
+		}
+		if card.Subtitle != "" {
+			title += "\n" + escape(card.Subtitle)
+		}
+		var fields []*slack.TextBlockObject
+		for _, f := range card.Fields {
+			var value = escape(f.Value)
+			if f.Link != "" {
+				value = fmt.Sprintf("<%s|%s>", f.Link, value)
+			}
+			fields = append(fields, markdown(fmt.Sprintf("*%s*\n%s", escape(f.Label), value)))
+		}
+		blocks = append(blocks, slack.NewSectionBlock(markdown(title), fields, nil))
+		if card.Note != "" {
+			blocks = append(blocks, slack.NewContextBlock("", markdown(escape(card.Note))))
+		}
+		if len(card.Buttons) > 0 {
+			blocks = append(blocks, buttons(fmt.Sprintf("card:%d", i), card.Buttons))
+		}
+		blocks = append(blocks, slack.NewDividerBlock())
+	}
+	if post.Footer != "" {
+		blocks = append(blocks, slack.NewContextBlock("", markdown(escape(post.Footer))))
+	}
+	if len(post.Buttons) > 0 {
+		blocks = append(blocks, buttons("post", post.Buttons))
+	}
+	return blocks
+}
+
+// modalView renders form as a modal, one input block per field named after
+// the field id.
+func modalView(form chat.Form, metadata modalMetadata) slack.ModalViewRequest {
+	metadata.State = form.State
+	var blocks = make([]slack.Block, 0, len(form.Fields))
+	for _, f := range form.Fields {
+		var input = slack.NewInputBlock(f.Id, plain(f.Label), nil, fieldInput(f))
+		input.Optional = f.Optional
+		if f.Reload {
+			input.WithDispatchAction(true)
+		}
+		blocks = append(blocks, input)
+	}
+	var submit = form.Submit
+	if submit == "" {
+		submit = "Save"
+	}
+	encoded, _ := json.Marshal(metadata)
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      form.Id,
+		Title:           plain(form.Title),
+		Submit:          plain(submit),
+		Close:           plain("Cancel"),
+		Blocks:          slack.Blocks{BlockSet: blocks},
+		PrivateMetadata: string(encoded),
+	}
+}
+
+func fieldInput(f chat.FormField) slack.BlockElement {
+	var placeholder *slack.TextBlockObject
+	if f.Placeholder != "" {
+		placeholder = plain(f.Placeholder)
+	}
+	switch f.Type {
+	case chat.FIELD_MULTILINE:
+		return slack.NewPlainTextInputBlockElement(placeholder, valueAction).WithInitialValue(f.Value).WithMultiline(true)
+	case chat.FIELD_EMAIL:
+		var input = slack.NewEmailTextInputBlockElement(placeholder, valueAction)
+		input.InitialValue = f.Value
+		return input
+	case chat.FIELD_NUMBER:
+		return slack.NewNumberInputBlockElement(placeholder, valueAction, true).WithInitialValue(f.Value)
+	case chat.FIELD_URL:
+		var input = slack.NewURLTextInputBlockElement(placeholder, valueAction)
+		input.InitialValue = f.Value
+		return input
+	case chat.FIELD_DATE:
+		var input = slack.NewDatePickerBlockElement(valueAction)
+		input.InitialDate = f.Value
+		return input
+	case chat.FIELD_SELECT:
+		var options = make([]*slack.OptionBlockObject, 0, len(f.Options))
+		for _, o := range f.Options {
+			options = append(options, option(o))
+		}
+		var input = slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, placeholder, valueAction, options...)
+		if slices.Contains(f.Options, f.Value) {
+			input.WithInitialOption(option(f.Value))
+		}
+		return input
+	}
+	return slack.NewPlainTextInputBlockElement(placeholder, valueAction).WithInitialValue(f.Value)
+}
+
+// submission reads the values of the modal an interaction came from.
+func (b *Bot) submission(ctx context.Context, callback slack.InteractionCallback) chat.Submission {
+	var metadata = parseMetadata(callback.View.PrivateMetadata)
+	var sub = chat.Submission{
+		Id:     "view:" + callback.View.ID,
+		Form:   callback.View.CallbackID,
+		State:  metadata.State,
+		Values: map[string]string{},
+		Caller: b.caller(ctx, callback.Team.ID, callback.User.ID, metadata.Channel),
+	}
+	if callback.View.State == nil {
+		return sub
+	}
+	for blockId, actions := range callback.View.State.Values {
+		if action, ok := actions[valueAction]; ok {
+			sub.Values[blockId] = actionValue(action)
+		}
+	}
+	return sub
+}
+
+func actionValue(action slack.BlockAction) string {
+	switch action.Type {
+	case slack.ActionType(slack.OptTypeStatic):
+		return action.SelectedOption.Value
+	case slack.ActionType(slack.METDatepicker):
+		return action.SelectedDate
+	}
+	return action.Value
+}
+
+// escape keeps user data from being read as mrkdwn links or mentions.
+func escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+func markdown(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.MarkdownType, text, false, false)
+}
+
+func plain(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.PlainTextType, text, false, false)
+}
+
+func option(value string) *slack.OptionBlockObject {
+	return slack.NewOptionBlockObject(value, plain(value), nil)
+}
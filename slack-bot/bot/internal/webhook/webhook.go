@@ -0,0 +1,177 @@
+// Package webhook is a chat adapter for the outgoing webhooks of Mattermost
+// and Rocket.Chat: the chat posts the messages starting with a trigger word
+// and the bot answers in the response. Chats reached this way cannot show
+// buttons or forms, cards are sent as markdown.
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+)
+
+// the chats give up on a webhook after a few seconds
+const handlerTimeout = 10 * time.Second
+
+// Payload is what Mattermost and Rocket.Chat post, as JSON or as a form.
+type Payload struct {
+	Token       string `json:"token"`
+	ChannelId   string `json:"channel_id"`
+	UserId      string `json:"user_id"`
+	UserName    string `json:"user_name"`
+	Text        string `json:"text"`
+	TriggerWord string `json:"trigger_word"`
+	// PostId is set by Mattermost, MessageId by Rocket.Chat
+	PostId    string `json:"post_id"`
+	MessageId string `json:"message_id"`
+}
+
+// Response is the answer the chat posts on behalf of the bot. A "comment"
+// response type makes Mattermost post it as a reply in the thread.
+type Response struct {
+	Text         string `json:"text"`
+	ResponseType string `json:"response_type,omitempty"`
+}
+
+type Webhook struct {
+	addr    string
+	path    string
+	token   string
+	trigger string
+	handler chat.Handler
+}
+
+// New returns the adapter for webhooks posted to path on addr. Requests
+// must carry token, the one the chat generated for the webhook.
+func New(addr, path, token, trigger string) *Webhook {
+	return &Webhook{addr: addr, path: path, token: token, trigger: trigger}
+}
+
+func (wh *Webhook) Prefix() string {
+	return wh.trigger
+}
+
+func (wh *Webhook) Run(ctx context.Context, h chat.Handler) error {
+	wh.handler = h
+	var mux = http.NewServeMux()
+	mux.HandleFunc("POST "+wh.path, wh.handle)
+	var server = &http.Server{Addr: wh.addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	log.Printf("Listening for webhooks on %s%s", wh.addr, wh.path)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (wh *Webhook) handle(w http.ResponseWriter, r *http.Request) {
+	payload, err := parsePayload(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if wh.token == "" || subtle.ConstantTimeCompare([]byte(payload.Token), []byte(wh.token)) != 1 {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	var trigger = payload.TriggerWord
+	if trigger == "" {
+		trigger = wh.trigger
+	}
+	var user = payload.UserName
+	if user == "" {
+		user = payload.UserId
+	}
+	var id = payload.PostId
+	if id == "" {
+		id = payload.MessageId
+	}
+	var msg = chat.Message{
+		Id:     id,
+		Text:   strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(payload.Text), trigger)),
+		Caller: chat.Caller{User: user, Channel: payload.ChannelId},
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), handlerTimeout)
+	defer cancel()
+	var conv = &conversation{}
+	wh.handler.HandleMessage(ctx, conv, msg)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conv.response())
+}
+
+func parsePayload(r *http.Request) (Payload, error) {
+	var payload = Payload{}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&payload); err != nil {
+			return payload, fmt.Errorf("invalid payload: %w", err)
+		}
+		return payload, nil
+	}
+	if err := r.ParseForm(); err != nil {
+		return payload, fmt.Errorf("invalid form: %w", err)
+	}
+	payload.Token = r.PostForm.Get("token")
+	payload.ChannelId = r.PostForm.Get("channel_id")
+	payload.UserId = r.PostForm.Get("user_id")
+	payload.UserName = r.PostForm.Get("user_name")
+	payload.Text = r.PostForm.Get("text")
+	payload.TriggerWord = r.PostForm.Get("trigger_word")
+	payload.PostId = r.PostForm.Get("post_id")
+	payload.MessageId = r.PostForm.Get("message_id")
+	return payload, nil
+}
+
+// conversation gathers the answers to one message, they are all sent back
+// in the response.
+type conversation struct {
+	mu       sync.Mutex
+	replies  []string
+	threaded bool
+}
+
+func (c *conversation) Reply(ctx context.Context, text string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.replies = append(c.replies, text)
+	return nil
+}
+
+func (c *conversation) ReplyInThread(ctx context.Context, text string) error {
+	c.mu.Lock()
+	c.threaded = true
+	c.mu.Unlock()
+	return c.Reply(ctx, text)
+}
+
+func (c *conversation) PostCards(ctx context.Context, post chat.Post) error {
+	return c.Reply(ctx, post.Markdown())
+}
+
+func (c *conversation) OpenForm(ctx context.Context, form chat.Form) error {
+	return chat.ErrNotSupported
+}
+
+func (c *conversation) response() Response {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var resp = Response{Text: strings.Join(c.replies, "\n\n")}
+	if c.threaded {
+		resp.ResponseType = "comment"
+	}
+	return resp
+}
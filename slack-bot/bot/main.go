@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/chat"
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/contacts"
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/repl"
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/slackbot"
+	"github.com/CatalinCaprita/SPO/slack-bot/bot/internal/webhook"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/rpcauth"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/rpcmetrics"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/tracing"
+	"github.com/joho/godotenv"
+	"github.com/slack-go/slack"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	SLACK_BOT_TOKEN      = "SLACK_BOT_TOKEN"
+	SLACK_SIGNING_SECRET = "SLACK_SIGNING_SECRET"
+	WEBHOOK_TOKEN        = "WEBHOOK_TOKEN"
+	OPS_TOKEN            = "OPS_TOKEN"
+	OPS_CLIENT           = "OPS_CLIENT"
+	OPS_HMAC_SECRET      = "OPS_HMAC_SECRET"
+	OPS_TENANT           = "OPS_TENANT"
+	INSTALL_LINK_SECRET  = "INSTALL_LINK_SECRET"
+)
+
+var (
+	verbose     = flag.Bool("v", false, "verbose")
+	adapterName = flag.String("adapter", "slack", "Chat the bot answers in: slack, repl (this terminal) or webhook (Mattermost, Rocket.Chat)")
+	addr        = flag.String("addr", ":3000", "Address slack or the webhooks post to")
+	opsAddr     = flag.String("ops", "localhost:8080", "Address of the ops gRPC service")
+	slackAPI    = flag.String("slack-api", "", "Slack Web API URL, e.g. a local stand-in; the real API if empty")
+	webhookPath = flag.String("webhook-path", "/webhook", "Path the outgoing webhook posts to")
+	trigger     = flag.String("trigger", "@contact", "Trigger word of the outgoing webhook")
+	policyPath  = flag.String("authz", "", "YAML policy of who may do what on which boards, left to ops if empty")
+	opsTLS      = flag.Bool("ops-tls", false, "Connect to ops over TLS, implied by the other -ops-* flags")
+	opsCA       = flag.String("ops-ca", "", "CA the ops certificate is signed by, the system CAs if empty")
+	opsCert     = flag.String("ops-cert", "", "Client certificate to present to ops (mutual TLS)")
+	opsKey      = flag.String("ops-key", "", "Key of -ops-cert")
+	opsName     = flag.String("ops-server-name", "", "Name to check the ops certificate against, the host of -ops if empty")
+	traceTo     = flag.String("trace", "", "Export traces to stdout or otlp (OTEL_EXPORTER_OTLP_ENDPOINT, localhost:4317 by default); none if empty")
+	metricsAddr = flag.String("metrics-addr", "", "Address to serve Prometheus metrics on, at /metrics; none if empty")
+	installURL  = flag.String("install-url", "", "Public URL of the install page of ops (oauth.addr), for admins to get signed install links; none if empty")
+)
+
+// dialOptions secures the connection to ops as the -ops-* flags and the
+// OPS_* variables ask: a bearer token in OPS_TOKEN, or an HMAC secret in
+// OPS_HMAC_SECRET signing calls as OPS_CLIENT.
+func dialOptions() []grpc.DialOption {
+	var options = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if *opsTLS || *opsCA != "" || *opsCert != "" || *opsName != "" {
+		creds, err := rpcauth.ClientTLS(*opsCA, *opsCert, *opsKey, *opsName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	}
+	if token := os.Getenv(OPS_TOKEN); token != "" {
+		options = append(options, rpcauth.WithBearerToken(token)...)
+	} else if secret := os.Getenv(OPS_HMAC_SECRET); secret != "" {
+		if os.Getenv(OPS_CLIENT) == "" {
+			log.Fatalf("%s must name the client %s signs for", OPS_CLIENT, OPS_HMAC_SECRET)
+		}
+		options = append(options, rpcauth.WithHMAC(os.Getenv(OPS_CLIENT), secret)...)
+	}
+	options = append(options, tracing.DialOptions()...)
+	return append(options, rpcmetrics.DialOptions()...)
+}
+
+func main() {
+	godotenv.Load("../.env")
+	flag.Parse()
+	if *verbose {
+		slog.SetLogLoggerLevel(slog.LevelDebug)
+	}
+	shutdown, err := tracing.Setup(context.Background(), "bot", *traceTo)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer shutdown(context.Background())
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle(rpcmetrics.METRICS_PATH, rpcmetrics.Handler())
+		go func() {
+			log.Printf("Serving metrics on %s%s", *metricsAddr, rpcmetrics.METRICS_PATH)
+			log.Fatal(http.ListenAndServe(*metricsAddr, mux))
+		}()
+	}
+	conn, err := grpc.NewClient(*opsAddr, dialOptions()...)
+	if err != nil {
+		log.Fatal(fmt.Errorf("Failed to connect to ops at %s: %w", *opsAddr, err))
+	}
+	defer conn.Close()
+
+	var adapter chat.Adapter
+	switch *adapterName {
+	case "slack":
+		var options = []slack.Option{}
+		if *slackAPI != "" {
+			options = append(options, slack.OptionAPIURL(*slackAPI))
+		}
+		api := slack.New(os.Getenv(SLACK_BOT_TOKEN), options...)
+		adapter = slackbot.New(api, os.Getenv(SLACK_SIGNING_SECRET), *addr)
+	case "repl":
+		adapter = repl.New(os.Stdin, os.Stdout)
+	case "webhook":
+		if os.Getenv(WEBHOOK_TOKEN) == "" {
+			log.Fatalf("%s must be set to the token of the outgoing webhook", WEBHOOK_TOKEN)
+		}
+		adapter = webhook.New(*addr, *webhookPath, os.Getenv(WEBHOOK_TOKEN), *trigger)
+	default:
+		log.Fatalf("Unknown adapter %s, expected slack, repl or webhook", *adapterName)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var handler = contacts.New(pb.NewMondayServiceClient(conn), adapter.Prefix())
+	if *policyPath != "" {
+		policy, err := authz.Load(*policyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		handler.UsePolicy(policy)
+	}
+	if tenant := os.Getenv(OPS_TENANT); tenant != "" {
+		handler.UseTenant(tenant)
+	}
+	if *installURL != "" {
+		if os.Getenv(INSTALL_LINK_SECRET) == "" {
+			log.Fatalf("-install-url needs %s, the secret ops verifies install links with", INSTALL_LINK_SECRET)
+		}
+		handler.UseInstallLinks([]byte(os.Getenv(INSTALL_LINK_SECRET)), *installURL)
+	}
+	if err := adapter.Run(ctx, handler); err != nil {
+		log.Fatal(err)
+	}
+}
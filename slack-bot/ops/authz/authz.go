@@ -0,0 +1,140 @@
+// Package authz decides which contact boards a caller may search, add to,
+// update or delete from. The bot checks a policy before calling ops and the
+// ops server checks it again with the caller the bot forwarded.
+package authz
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Operation string
+
+const (
+	FIND   Operation = "find"
+	ADD    Operation = "add"
+	UPDATE Operation = "update"
+	DELETE Operation = "delete"
+	// ANY in a rule stands for every operation, as in boards
+	ANY = "*"
+)
+
+// Policy allows an operation when any of its rules does. A nil policy
+// allows everything.
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule applies to the callers matching one of its users, groups or
+// channels, or to every caller when it lists none of them.
+type Rule struct {
+	Users      []string    `yaml:"users"`
+	Groups     []string    `yaml:"groups"`
+	Channels   []string    `yaml:"channels"`
+	Boards     []string    `yaml:"boards"`
+	Operations []Operation `yaml:"operations"`
+}
+
+// DeniedError is returned for operations no rule allows.
+type DeniedError struct {
+	Caller    Caller
+	Board     string
+	Operation Operation
+}
+
+func (e *DeniedError) Error() string {
+	var who = e.Caller.User
+	if who == "" {
+		who = "anonymous caller"
+	}
+	return fmt.Sprintf("%s may not %s contacts on board %s", who, e.Operation, e.Board)
+}
+
+// Load reads a policy from a YAML file.
+func Load(path string) (*Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	var policy = &Policy{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(policy); err != nil {
+		return nil, fmt.Errorf("failed to decode policy %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+func (p *Policy) Validate() error {
+	for i, r := range p.Rules {
+		if len(r.Boards) == 0 {
+			return fmt.Errorf("rule %d lists no boards, use \"*\" for every board", i+1)
+		}
+		if len(r.Operations) == 0 {
+			return fmt.Errorf("rule %d lists no operations, use \"*\" for every operation", i+1)
+		}
+		for _, op := range r.Operations {
+			switch op {
+			case FIND, ADD, UPDATE, DELETE, ANY:
+			default:
+				return fmt.Errorf("rule %d has unknown operation %q, expected find, add, update, delete or *", i+1, op)
+			}
+		}
+	}
+	return nil
+}
+
+// Allowed tells whether caller may do op on the contacts of board.
+func (p *Policy) Allowed(caller Caller, board string, op Operation) bool {
+	if p == nil {
+		return true
+	}
+	return slices.ContainsFunc(p.Rules, func(r Rule) bool {
+		return r.matches(caller) && r.allows(board, op)
+	})
+}
+
+// Check is Allowed returning a *DeniedError instead of false.
+func (p *Policy) Check(caller Caller, board string, op Operation) error {
+	if !p.Allowed(caller, board, op) {
+		return &DeniedError{Caller: caller, Board: board, Operation: op}
+	}
+	return nil
+}
+
+// Boards returns which of boards caller may do op on.
+func (p *Policy) Boards(caller Caller, boards []string, op Operation) []string {
+	return slices.DeleteFunc(slices.Clone(boards), func(board string) bool {
+		return !p.Allowed(caller, board, op)
+	})
+}
+
+func (r Rule) matches(caller Caller) bool {
+	if len(r.Users) == 0 && len(r.Groups) == 0 && len(r.Channels) == 0 {
+		return true
+	}
+	if caller.User != "" && slices.Contains(r.Users, caller.User) {
+		return true
+	}
+	if caller.Channel != "" && slices.Contains(r.Channels, caller.Channel) {
+		return true
+	}
+	return slices.ContainsFunc(caller.Groups, func(g string) bool {
+		return slices.Contains(r.Groups, g)
+	})
+}
+
+func (r Rule) allows(board string, op Operation) bool {
+	var boardListed = slices.ContainsFunc(r.Boards, func(b string) bool {
+		return b == ANY || strings.EqualFold(b, board)
+	})
+	return boardListed && (slices.Contains(r.Operations, op) || slices.Contains(r.Operations, ANY))
+}
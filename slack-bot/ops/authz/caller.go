@@ -0,0 +1,72 @@
+package authz
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// metadata keys the bot forwards the caller in
+const (
+	USER_KEY    = "x-caller-user"
+	GROUPS_KEY  = "x-caller-groups"
+	CHANNEL_KEY = "x-caller-channel"
+	TEAM_KEY    = "x-caller-team"
+	TENANT_KEY  = "x-tenant"
+)
+
+// Caller is who a request is made for: a chat user, the groups they belong
+// to, the channel they wrote in, the workspace (Slack team) they are in and
+// the tenant of ops serving them, the one of the team when empty.
+type Caller struct {
+	User    string
+	Groups  []string
+	Channel string
+	Team    string
+	Tenant  string
+}
+
+// OutgoingContext returns ctx carrying caller to the gRPC calls made with it.
+func OutgoingContext(ctx context.Context, caller Caller) context.Context {
+	var pairs = []string{}
+	if caller.User != "" {
+		pairs = append(pairs, USER_KEY, caller.User)
+	}
+	if len(caller.Groups) > 0 {
+		pairs = append(pairs, GROUPS_KEY, strings.Join(caller.Groups, ","))
+	}
+	if caller.Channel != "" {
+		pairs = append(pairs, CHANNEL_KEY, caller.Channel)
+	}
+	if caller.Team != "" {
+		pairs = append(pairs, TEAM_KEY, caller.Team)
+	}
+	if caller.Tenant != "" {
+		pairs = append(pairs, TENANT_KEY, caller.Tenant)
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// FromIncomingContext returns the caller a gRPC request was made for, the
+// zero Caller when it carries none.
+func FromIncomingContext(ctx context.Context) Caller {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return Caller{}
+	}
+	var caller = Caller{User: first(md, USER_KEY), Channel: first(md, CHANNEL_KEY), Team: first(md, TEAM_KEY), Tenant: first(md, TENANT_KEY)}
+	for _, g := range strings.Split(first(md, GROUPS_KEY), ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			caller.Groups = append(caller.Groups, g)
+		}
+	}
+	return caller
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+// Command oauthstub is a local stand-in for monday's OAuth server to try
+// installing ops without a monday app. It approves every authorization
+// right away, exchanges the codes it gave for tokens, and answers the `me`
+// query ops identifies the account with:
+//
+//	go run ./ops/cmd/oauthstub -account 1001 -name "Stub account"
+//
+// Point oauth.auth_url and oauth.token_url of ops to /oauth2/authorize and
+// /oauth2/token of the stub, monday.url to its /v2, and use the same client
+// id and secret.
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var (
+	addr         = flag.String("addr", ":9998", "Address to serve the OAuth and GraphQL stand-ins on")
+	clientId     = flag.String("client-id", "stub-client", "Client id of the monday app")
+	clientSecret = flag.String("client-secret", "stub-secret", "Client secret of the monday app")
+	accountId    = flag.String("account", "1001", "Id of the account every installation is for")
+	accountName  = flag.String("name", "Stub account", "Name of that account")
+	deny         = flag.Bool("deny", false, "Deny every authorization instead of approving it")
+)
+
+type stub struct {
+	mu     sync.Mutex
+	codes  map[string]string
+	tokens map[string]bool
+}
+
+func main() {
+	flag.Parse()
+	var s = &stub{codes: map[string]string{}, tokens: map[string]bool{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /oauth2/authorize", s.authorize)
+	mux.HandleFunc("POST /oauth2/token", s.token)
+	mux.HandleFunc("POST /v2", s.graphql)
+	log.Printf("monday OAuth stand-in listening on %s for client %s", *addr, *clientId)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+// authorize sends the user back to the app right away, as if they had
+// granted access.
+func (s *stub) authorize(w http.ResponseWriter, r *http.Request) {
+	var query = r.URL.Query()
+	if query.Get("client_id") != *clientId {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirect.Host == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	var back = url.Values{"state": {query.Get("state")}}
+	if *deny {
+		back.Set("error", "access_denied")
+	} else {
+		var code = random()
+		s.mu.Lock()
+		s.codes[code] = query.Get("scope")
+		s.mu.Unlock()
+		back.Set("code", code)
+	}
+	redirect.RawQuery = back.Encode()
+	log.Printf("Authorized %s with scope %q, back to %s", *clientId, query.Get("scope"), redirect.Redacted())
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// token exchanges a code, once, for a token.
+func (s *stub) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	var id, secret, ok = r.BasicAuth()
+	if !ok {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if id != *clientId || secret != *clientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scope, ok := s.codes[r.PostForm.Get("code")]
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	delete(s.codes, r.PostForm.Get("code"))
+	var token = "stub-" + random()
+	s.tokens[token] = true
+	log.Printf("Exchanged a code for a token of account %s", *accountId)
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": token, "token_type": "Bearer", "scope": scope})
+}
+
+// graphql answers the `me` query of the tokens it gave, and nothing else.
+func (s *stub) graphql(w http.ResponseWriter, r *http.Request) {
+	var token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.mu.Lock()
+	var known = s.tokens[token]
+	s.mu.Unlock()
+	if !known {
+		http.Error(w, "unknown token", http.StatusUnauthorized)
+		return
+	}
+	var body = struct {
+		Query string `json:"query"`
+	}{}
+	json.NewDecoder(r.Body).Decode(&body)
+	if !strings.Contains(body.Query, "me{") {
+		writeJSON(w, http.StatusOK, map[string]any{"errors": []map[string]string{{"message": "the stub only answers the me query"}}})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{
+		"me":         map[string]any{"account": map[string]string{"id": *accountId, "name": *accountName, "slug": "stub"}},
+		"complexity": map[string]int{"after": 5000000, "reset_in_x_seconds": 60},
+	}})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(fmt.Errorf("failed to answer: %w", err))
+	}
+}
+
+func random() string {
+	var b = make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
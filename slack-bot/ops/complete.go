@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/catalog"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/spf13/cobra"
+)
+
+// COMPLETION_TIMEOUT bounds how long a completion waits for monday to
+// describe the boards, for the shell not to hang on a tab.
+const COMPLETION_TIMEOUT = 5 * time.Second
+
+// registerCompletions suggests the board names, group names and column
+// titles of the catalog to the flags taking one, and files to the flags
+// taking a file.
+func registerCompletions() {
+	var completions = []struct {
+		cmd  *cobra.Command
+		flag string
+		fn   cobra.CompletionFunc
+	}{
+		{searchCmd, "col", completeColumns},
+		{addCmd, "board", completeBoards},
+		{addCmd, "group", completeGroups},
+		{describeCmd, "board", completeBoards},
+		{mirrorCmd, "boards", completeBoardList},
+		{serveCmd, "mirror-boards", completeBoardList},
+	}
+	for _, c := range completions {
+		if err := c.cmd.RegisterFlagCompletionFunc(c.flag, c.fn); err != nil {
+			panic(err)
+		}
+	}
+	type fileFlag struct {
+		cmd        *cobra.Command
+		flag       string
+		extensions []string
+	}
+	var files = []fileFlag{
+		{provisionCmd, "spec", []string{"yaml", "yml", "json"}},
+		{searchCmd, "index", []string{"db"}},
+		{searchCmd, "mirror", []string{"db"}},
+		{syncCmd, "index", []string{"db"}},
+		{mirrorCmd, "db", []string{"db"}},
+		{auditCmd, "log", []string{"db", "jsonl"}},
+		{tuiCmd, "log", nil},
+		{serveCmd, "index", []string{"db"}},
+		{serveCmd, "mirror", []string{"db"}},
+		{serveCmd, "authz", []string{"yaml", "yml"}},
+		{serveCmd, "tenants", []string{"yaml", "yml"}},
+		{serveCmd, "clients", []string{"yaml", "yml"}},
+		{serveCmd, "tls-cert", []string{"crt", "pem"}},
+		{serveCmd, "tls-key", []string{"key", "pem"}},
+		{serveCmd, "tls-client-ca", []string{"crt", "pem"}},
+		{serveCmd, "audit", []string{"db", "jsonl"}},
+		{serveCmd, "idempotency", []string{"db"}},
+		{serveCmd, "undo", []string{"db"}},
+	}
+	for _, f := range files {
+		if err := f.cmd.MarkFlagFilename(f.flag, f.extensions...); err != nil {
+			panic(err)
+		}
+	}
+	if err := certsCmd.MarkFlagDirname("dir"); err != nil {
+		panic(err)
+	}
+}
+
+// loadCatalog reads the catalog of the boards of the config, described
+// again once it is older than the boards are cached for.
+func loadCatalog() (*catalog.Catalog, monday.Settings, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, monday.Settings{}, err
+	}
+	var settings = cfg.Settings()
+	path, err := catalog.Path(cfg.Monday.URL, settings.Workspaces)
+	if err != nil {
+		return nil, settings, err
+	}
+	var ttl = settings.BoardsTTL
+	if ttl == 0 {
+		ttl = catalog.DEFAULT_TTL
+	}
+	client := monday.New(cfg.Monday.URL, cfg.TokenSource())
+	client.Configure(settings)
+	ctx, cancel := context.WithTimeout(context.Background(), COMPLETION_TIMEOUT)
+	defer cancel()
+	found, err := catalog.Load(ctx, client, path, ttl)
+	return found, settings, err
+}
+
+func completeBoards(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	found, _, err := loadCatalog()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var suggestions = []cobra.Completion{}
+	for _, b := range found.Boards {
+		suggestions = append(suggestions, cobra.CompletionWithDesc(b.Name, boardDescription(b)))
+	}
+	return matching(suggestions, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBoardList completes the last board of a comma separated list.
+func completeBoardList(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var listed, last = "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		listed, last = toComplete[:i+1], toComplete[i+1:]
+	}
+	suggestions, directive := completeBoards(cmd, args, strings.TrimLeft(last, " "))
+	for i, s := range suggestions {
+		suggestions[i] = listed + s
+	}
+	return suggestions, directive | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeGroups suggests the groups of the board of --board, or of the
+// default board, or of every board when neither is known.
+func completeGroups(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	found, settings, err := loadCatalog()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	boardName, _ := cmd.Flags().GetString("board")
+	if boardName == "" {
+		boardName = settings.DefaultBoard
+	}
+	var boards = found.Boards
+	if b := found.Board(boardName); b != nil {
+		boards = []monday.BoardDescription{*b}
+	}
+	var suggestions, seen = []cobra.Completion{}, map[string]bool{}
+	for _, b := range boards {
+		for _, g := range b.Groups {
+			if seen[strings.ToLower(g.Title)] {
+				continue
+			}
+			seen[strings.ToLower(g.Title)] = true
+			suggestions = append(suggestions, cobra.CompletionWithDesc(g.Title, "group of "+b.Name))
+		}
+	}
+	return matching(suggestions, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeColumns suggests the column titles of every board, as search
+// looks in all of them.
+func completeColumns(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	found, _, err := loadCatalog()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var suggestions, seen = []cobra.Completion{}, map[string]bool{}
+	for _, b := range found.Boards {
+		for _, c := range b.Columns {
+			if seen[strings.ToLower(c.Title)] {
+				continue
+			}
+			seen[strings.ToLower(c.Title)] = true
+			suggestions = append(suggestions, cobra.CompletionWithDesc(c.Title, c.Type+" column"))
+		}
+	}
+	return matching(suggestions, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func boardDescription(b monday.BoardDescription) string {
+	if b.Description != "" {
+		return b.Description
+	}
+	return fmt.Sprintf("%s board, %d groups", b.Kind, len(b.Groups))
+}
+
+// matching keeps the suggestions starting with prefix, whatever their case.
+func matching(suggestions []cobra.Completion, prefix string) []cobra.Completion {
+	var kept = []cobra.Completion{}
+	for _, s := range suggestions {
+		value, _, _ := strings.Cut(s, "\t")
+		if strings.HasPrefix(strings.ToLower(value), strings.ToLower(prefix)) {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
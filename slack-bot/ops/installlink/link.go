@@ -0,0 +1,81 @@
+// Package installlink signs the links the bot gives Slack workspace admins
+// to install ops in a monday account. The OAuth installer of ops only routes
+// a Slack team to the account installed through a link signed for it, so
+// nobody can route a team they do not administer.
+package installlink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// query parameters of a link
+const (
+	TEAM_PARAM      = "team"
+	USER_PARAM      = "user"
+	EXPIRES_PARAM   = "expires"
+	SIGNATURE_PARAM = "signature"
+	// DEFAULT_TTL is how long the links the bot gives can be followed.
+	DEFAULT_TTL = 15 * time.Minute
+)
+
+var ErrInvalid = errors.New("invalid install link")
+
+// Link lets User, an admin of the Slack team Team, install ops for it until
+// Expiry.
+type Link struct {
+	Team   string
+	User   string
+	Expiry time.Time
+}
+
+// Sign returns installURL, the public URL of the install page of ops, with
+// link signed with secret.
+func Sign(secret []byte, installURL string, link Link) (string, error) {
+	u, err := url.Parse(installURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse install URL: %w", err)
+	}
+	var expires = strconv.FormatInt(link.Expiry.Unix(), 10)
+	var query = u.Query()
+	query.Set(TEAM_PARAM, link.Team)
+	query.Set(USER_PARAM, link.User)
+	query.Set(EXPIRES_PARAM, expires)
+	query.Set(SIGNATURE_PARAM, signature(secret, link.Team, link.User, expires))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Verify returns the link of query, if it was signed with secret and has not
+// expired at now.
+func Verify(secret []byte, query url.Values, now time.Time) (Link, error) {
+	var team, user, expires = query.Get(TEAM_PARAM), query.Get(USER_PARAM), query.Get(EXPIRES_PARAM)
+	if len(secret) == 0 || team == "" || user == "" {
+		return Link{}, ErrInvalid
+	}
+	if !hmac.Equal([]byte(signature(secret, team, user, expires)), []byte(query.Get(SIGNATURE_PARAM))) {
+		return Link{}, fmt.Errorf("%w: bad signature", ErrInvalid)
+	}
+	seconds, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return Link{}, fmt.Errorf("%w: bad expiry", ErrInvalid)
+	}
+	var link = Link{Team: team, User: user, Expiry: time.Unix(seconds, 0)}
+	if !now.Before(link.Expiry) {
+		return Link{}, fmt.Errorf("%w: expired at %s", ErrInvalid, link.Expiry.Format(time.RFC3339))
+	}
+	return link, nil
+}
+
+func signature(secret []byte, team, user, expires string) string {
+	var mac = hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join([]string{team, user, expires}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
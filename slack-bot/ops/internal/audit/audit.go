@@ -0,0 +1,105 @@
+// Package audit records every change made to the contact boards through
+// ops: who asked for it, on whose behalf, what was asked, and how it went.
+// Records are only ever appended.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	OUTCOME_OK = "OK"
+	// how many records a query returns when no limit is given
+	defaultLimit = 100
+)
+
+// Record is one mutation, successful or not.
+type Record struct {
+	Time time.Time `json:"time"`
+	// Method is the RPC called, e.g. CreateItem.
+	Method string `json:"method"`
+	// Client is the authenticated client that made the call, e.g. bot.
+	Client string `json:"client,omitempty"`
+	// User, Groups and Channel are the chat caller the client called for.
+	User    string   `json:"user,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+	Channel string   `json:"channel,omitempty"`
+	// Tenant is the tenant the call was served for, if the server has any.
+	Tenant  string          `json:"tenant,omitempty"`
+	Board   string          `json:"board,omitempty"`
+	ItemId  string          `json:"item_id,omitempty"`
+	Request json.RawMessage `json:"request"`
+	// Outcome is OUTCOME_OK or the gRPC code the call failed with, Error
+	// the failure message.
+	Outcome  string        `json:"outcome"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+func (r Record) String() string {
+	var who = r.User
+	if who == "" {
+		who = "-"
+	}
+	if r.Channel != "" {
+		who += " in " + r.Channel
+	}
+	if r.Client != "" {
+		who += " via " + r.Client
+	}
+	if r.Tenant != "" {
+		who += " for " + r.Tenant
+	}
+	var line = fmt.Sprintf("%s %s %s board=%q item=%s %s (%s)",
+		r.Time.Local().Format(time.DateTime), r.Method, who, r.Board, r.ItemId, r.Outcome, r.Duration.Round(time.Millisecond))
+	if r.Error != "" {
+		line += ": " + r.Error
+	}
+	return line + " " + string(r.Request)
+}
+
+// Filter selects records, every empty field matching all of them.
+type Filter struct {
+	User  string
+	Board string
+	Since time.Time
+	Until time.Time
+	// Limit is how many of the newest matching records are returned,
+	// defaultLimit when 0 or less.
+	Limit int
+}
+
+func (f Filter) matches(r Record) bool {
+	return (f.User == "" || r.User == f.User) &&
+		(f.Board == "" || strings.EqualFold(r.Board, f.Board)) &&
+		(f.Since.IsZero() || !r.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || r.Time.Before(f.Until))
+}
+
+func (f Filter) limit() int {
+	if f.Limit <= 0 {
+		return defaultLimit
+	}
+	return f.Limit
+}
+
+// Log is where records are appended to.
+type Log interface {
+	Append(ctx context.Context, record Record) error
+	// Query returns the records matching filter, oldest first.
+	Query(ctx context.Context, filter Filter) ([]Record, error)
+	Close() error
+}
+
+// Open opens the audit log at path, a JSON lines file when it ends with
+// .jsonl and a SQLite database otherwise.
+func Open(path string) (Log, error) {
+	if strings.HasSuffix(path, ".jsonl") {
+		return OpenFile(path)
+	}
+	return OpenDB(path)
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	_ "modernc.org/sqlite"
+)
+
+// time is stored as RFC 3339 with nanoseconds in UTC, which sorts as text
+const schema = `
+CREATE TABLE IF NOT EXISTS records (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	time TEXT NOT NULL,
+	user TEXT NOT NULL,
+	board TEXT NOT NULL COLLATE NOCASE,
+	doc TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS records_time ON records(time);
+CREATE INDEX IF NOT EXISTS records_user ON records(user, time);
+CREATE INDEX IF NOT EXISTS records_board ON records(board, time);
+CREATE TRIGGER IF NOT EXISTS records_append_only_update BEFORE UPDATE ON records
+BEGIN SELECT RAISE(ABORT, 'audit records cannot be changed'); END;
+CREATE TRIGGER IF NOT EXISTS records_append_only_delete BEFORE DELETE ON records
+BEGIN SELECT RAISE(ABORT, 'audit records cannot be deleted'); END;`
+
+const timeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// DB is an audit log kept in SQLite, whose triggers refuse to change or
+// delete records.
+type DB struct {
+	db *sql.DB
+}
+
+func OpenDB(path string) (*DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+	// a single connection serializes writers and avoids SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create audit schema: %w", err)
+	}
+	return &DB{db: db}, nil
+}
+
+func (d *DB) Append(ctx context.Context, record Record) error {
+	doc, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	_, err = d.db.ExecContext(ctx, `INSERT INTO records(time, user, board, doc) VALUES(?, ?, ?, ?)`,
+		record.Time.UTC().Format(timeFormat), record.User, record.Board, string(doc))
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) Query(ctx context.Context, filter Filter) ([]Record, error) {
+	var query = `SELECT doc FROM records WHERE 1 = 1`
+	var args = []any{}
+	if filter.User != "" {
+		query += ` AND user = ?`
+		args = append(args, filter.User)
+	}
+	if filter.Board != "" {
+		query += ` AND board = ?`
+		args = append(args, filter.Board)
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND time >= ?`
+		args = append(args, filter.Since.UTC().Format(timeFormat))
+	}
+	if !filter.Until.IsZero() {
+		query += ` AND time < ?`
+		args = append(args, filter.Until.UTC().Format(timeFormat))
+	}
+	query += ` ORDER BY time DESC, id DESC LIMIT ?`
+	args = append(args, filter.limit())
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+	var records = []Record{}
+	for rows.Next() {
+		var doc string
+		if err := rows.Scan(&doc); err != nil {
+			return nil, fmt.Errorf("failed to read audit record: %w", err)
+		}
+		var record = Record{}
+		if err := json.Unmarshal([]byte(doc), &record); err != nil {
+			return nil, fmt.Errorf("invalid audit record: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	slices.Reverse(records)
+	return records, nil
+}
+
+func (d *DB) Close() error {
+	return d.db.Close()
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// File is an audit log kept as one JSON record per line.
+type File struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+func OpenFile(path string) (*File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+	return &File{path: path, file: file}, nil
+}
+
+func (f *File) Append(ctx context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// Query reads the whole file, keeping the newest matching records.
+func (f *File) Query(ctx context.Context, filter Filter) ([]Record, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", f.path, err)
+	}
+	defer file.Close()
+	var records = []Record{}
+	var scanner = bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var record = Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid audit record at %s:%d: %w", f.path, line, err)
+		}
+		if !filter.matches(record) {
+			continue
+		}
+		records = append(records, record)
+		if len(records) > filter.limit() {
+			records = records[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log %s: %w", f.path, err)
+	}
+	return records, nil
+}
+
+func (f *File) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,109 @@
+// Package catalog keeps the boards of the contacts workspaces, with their
+// groups and columns, in a file. The shell completions of ops suggest from
+// it rather than asking monday on every tab.
+package catalog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+)
+
+// DEFAULT_TTL is how long a catalog is used before the boards are described
+// again, when the boards are not cached by the config.
+const DEFAULT_TTL = 10 * time.Minute
+
+type Catalog struct {
+	Boards []monday.BoardDescription `json:"boards"`
+	At     time.Time                 `json:"at"`
+}
+
+// Path is the file of the catalog of the workspaces at url, in the cache
+// directory of the user. Each account and set of workspaces has its own.
+func Path(url string, workspaces []string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the cache directory: %w", err)
+	}
+	var sum = sha256.Sum256([]byte(url + "\n" + strings.Join(workspaces, "\n")))
+	return filepath.Join(dir, "ops", fmt.Sprintf("catalog-%x.json", sum[:8])), nil
+}
+
+// Load returns the catalog in path, described again with client once it is
+// older than ttl. The older catalog is returned when monday cannot describe
+// the boards.
+func Load(ctx context.Context, client *monday.ApiClient, path string, ttl time.Duration) (*Catalog, error) {
+	cached, readErr := read(path)
+	if readErr == nil && time.Since(cached.At) < ttl {
+		return cached, nil
+	}
+	boards, err := client.DescribeBoards(ctx, "")
+	if err != nil {
+		if readErr == nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("failed to describe the boards: %w", err)
+	}
+	var catalog = &Catalog{Boards: boards, At: time.Now()}
+	if err := catalog.write(path); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+func read(path string) (*Catalog, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var catalog = &Catalog{}
+	if err := json.Unmarshal(content, catalog); err != nil {
+		return nil, fmt.Errorf("failed to decode catalog %s: %w", path, err)
+	}
+	return catalog, nil
+}
+
+// write replaces the file at path with the catalog, for a completion
+// running at the same time to never read half of it.
+func (c *Catalog) write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create the catalog directory: %w", err)
+	}
+	content, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode catalog: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write catalog: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write catalog: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write catalog: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write catalog: %w", err)
+	}
+	return nil
+}
+
+// Board returns the board called name, nil when there is none.
+func (c *Catalog) Board(name string) *monday.BoardDescription {
+	for i, board := range c.Boards {
+		if strings.EqualFold(board.Name, name) {
+			return &c.Boards[i]
+		}
+	}
+	return nil
+}
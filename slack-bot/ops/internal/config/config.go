@@ -0,0 +1,461 @@
+// Package config reads the settings of ops in layers: the defaults, then a
+// YAML or TOML file, then OPS_* environment variables, then the flags given
+// on the command line, each overriding the one before.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/idempotency"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/oauth"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/secret"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/undo"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/tracing"
+	"github.com/joho/godotenv"
+	"github.com/spf13/pflag"
+	"golang.org/x/oauth2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DEFAULT_PATH is read when no file is given with -config or OPS_CONFIG
+	// and it exists.
+	DEFAULT_PATH      = "ops.yaml"
+	DEFAULT_URL       = "https://api.monday.com/v2"
+	DEFAULT_TOKEN_ENV = "MONDAY_TOKEN"
+	// DEFAULT_KEYRING is the keyring service the token is kept under, for
+	// DEFAULT_KEYRING_USER.
+	DEFAULT_KEYRING      = "ops"
+	DEFAULT_KEYRING_USER = "monday"
+	// DEFAULT_CLIENT_SECRET_ENV holds the client secret of the monday app
+	// ops is installed as.
+	DEFAULT_CLIENT_SECRET_ENV = "MONDAY_CLIENT_SECRET"
+	// DEFAULT_SIGNING_SECRET_ENV holds the signing secret of the monday app,
+	// which the webhooks it posts are signed with.
+	DEFAULT_SIGNING_SECRET_ENV = "MONDAY_SIGNING_SECRET"
+	// DEFAULT_LINK_SECRET_ENV holds the secret the bot signs install links
+	// with.
+	DEFAULT_LINK_SECRET_ENV = "INSTALL_LINK_SECRET"
+	// ENV_PREFIX starts the variables overriding the file, e.g.
+	// OPS_MONDAY_API_VERSION for monday.api_version.
+	ENV_PREFIX = "OPS_"
+)
+
+var apiVersion = regexp.MustCompile(`^\d{4}-\d{2}$`)
+
+type Config struct {
+	// EnvFile completes the environment, without overriding it, before the
+	// OPS_* variables are read. There is none by default.
+	EnvFile string `yaml:"env_file" toml:"env_file" file:"true"`
+	Monday  Monday `yaml:"monday" toml:"monday"`
+	Cache   Cache  `yaml:"cache" toml:"cache"`
+	Serve   Serve  `yaml:"serve" toml:"serve"`
+	OAuth   OAuth  `yaml:"oauth" toml:"oauth"`
+	// path is the file the config was read from, if any.
+	path string
+}
+
+// Monday is how monday is reached. The settings tagged reload are applied
+// again on SIGHUP, the others need a restart.
+type Monday struct {
+	URL               string   `yaml:"url" toml:"url"`
+	APIVersion        string   `yaml:"api_version" toml:"api_version" reload:"true"`
+	Token             Token    `yaml:"token" toml:"token"`
+	Workspaces        []string `yaml:"workspaces" toml:"workspaces" reload:"true"`
+	DefaultBoard      string   `yaml:"default_board" toml:"default_board" reload:"true"`
+	DefaultGroup      string   `yaml:"default_group" toml:"default_group" reload:"true"`
+	SearchConcurrency int      `yaml:"search_concurrency" toml:"search_concurrency" reload:"true"`
+}
+
+// Token is where the monday API token comes from: the Env variable, File,
+// the output of Command, or the Keyring with Fallback where there is none.
+type Token struct {
+	Source  string   `yaml:"source" toml:"source"`
+	Env     string   `yaml:"env" toml:"env"`
+	File    string   `yaml:"file" toml:"file" file:"true"`
+	Command []string `yaml:"command" toml:"command"`
+	Keyring string   `yaml:"keyring" toml:"keyring"`
+	// KeyringUser is who the token is kept for in the Keyring service.
+	KeyringUser string `yaml:"keyring_user" toml:"keyring_user"`
+	Fallback    string `yaml:"fallback" toml:"fallback" file:"true"`
+	// Refresh is how long a token from Command or the Keyring is reused
+	// before it is read again.
+	Refresh time.Duration `yaml:"refresh" toml:"refresh"`
+}
+
+type Cache struct {
+	// Boards is how long the workspaces and boards listed are reused.
+	Boards time.Duration `yaml:"boards" toml:"boards" reload:"true"`
+}
+
+// Serve is what serve listens on and serves. The flag tags name the serve
+// flags overriding each setting.
+//
+// The settings tagged file are files; when set in the config file, they are
+// relative to it rather than to the working directory.
+type Serve struct {
+	Addr        string `yaml:"addr" toml:"addr" flag:"addr"`
+	HTTPAddr    string `yaml:"http_addr" toml:"http_addr" flag:"http-addr"`
+	WebhookAddr string `yaml:"webhook_addr" toml:"webhook_addr" flag:"webhook-addr"`
+	// WebhookSecretEnv is the variable holding the signing secret the
+	// webhooks received on WebhookAddr are verified with.
+	WebhookSecretEnv string        `yaml:"webhook_secret_env" toml:"webhook_secret_env"`
+	MetricsAddr      string        `yaml:"metrics_addr" toml:"metrics_addr" flag:"metrics-addr"`
+	TLS              TLS           `yaml:"tls" toml:"tls"`
+	Clients          string        `yaml:"clients" toml:"clients" flag:"clients" file:"true"`
+	Authz            string        `yaml:"authz" toml:"authz" flag:"authz" file:"true"`
+	Tenants          string        `yaml:"tenants" toml:"tenants" flag:"tenants" file:"true"`
+	Audit            string        `yaml:"audit" toml:"audit" flag:"audit" file:"true"`
+	Trace            string        `yaml:"trace" toml:"trace" flag:"trace"`
+	Index            string        `yaml:"index" toml:"index" flag:"index" file:"true"`
+	SyncInterval     time.Duration `yaml:"sync_interval" toml:"sync_interval" flag:"sync-interval"`
+	Mirror           string        `yaml:"mirror" toml:"mirror" flag:"mirror" file:"true"`
+	MirrorBoards     []string      `yaml:"mirror_boards" toml:"mirror_boards" flag:"mirror-boards"`
+	MirrorInterval   time.Duration `yaml:"mirror_interval" toml:"mirror_interval" flag:"mirror-interval"`
+	Idempotency      string        `yaml:"idempotency" toml:"idempotency" flag:"idempotency" file:"true"`
+	IdempotencyTTL   time.Duration `yaml:"idempotency_ttl" toml:"idempotency_ttl" flag:"idempotency-ttl"`
+	Undo             string        `yaml:"undo" toml:"undo" flag:"undo" file:"true"`
+	UndoWindow       time.Duration `yaml:"undo_window" toml:"undo_window" flag:"undo-window"`
+}
+
+// OAuth lets monday accounts install ops on Addr, and serves the Slack
+// teams that did from their account. It is off when Addr is empty.
+type OAuth struct {
+	Addr     string `yaml:"addr" toml:"addr"`
+	ClientID string `yaml:"client_id" toml:"client_id"`
+	// ClientSecretEnv is the variable holding the client secret of the
+	// monday app.
+	ClientSecretEnv string `yaml:"client_secret_env" toml:"client_secret_env"`
+	// LinkSecretEnv is the variable holding the secret the install links
+	// the bot gives are signed with, the same as in the bot.
+	LinkSecretEnv string `yaml:"link_secret_env" toml:"link_secret_env"`
+	// RedirectURL is where monday sends installing users back to, the
+	// public URL of oauth.CALLBACK_PATH on Addr.
+	RedirectURL string   `yaml:"redirect_url" toml:"redirect_url"`
+	AuthURL     string   `yaml:"auth_url" toml:"auth_url"`
+	TokenURL    string   `yaml:"token_url" toml:"token_url"`
+	Scopes      []string `yaml:"scopes" toml:"scopes"`
+	// Accounts keeps the installed accounts and their tokens.
+	Accounts string `yaml:"accounts" toml:"accounts" file:"true"`
+}
+
+type TLS struct {
+	Cert     string `yaml:"cert" toml:"cert" flag:"tls-cert" file:"true"`
+	Key      string `yaml:"key" toml:"key" flag:"tls-key" file:"true"`
+	ClientCA string `yaml:"client_ca" toml:"client_ca" flag:"tls-client-ca" file:"true"`
+}
+
+func Default() *Config {
+	var settings = monday.DefaultSettings()
+	return &Config{
+		Monday: Monday{
+			URL: DEFAULT_URL,
+			Token: Token{
+				Source:      secret.SOURCE_ENV,
+				Env:         DEFAULT_TOKEN_ENV,
+				Keyring:     DEFAULT_KEYRING,
+				KeyringUser: DEFAULT_KEYRING_USER,
+				Fallback:    defaultFallback(),
+				Refresh:     5 * time.Minute,
+			},
+			Workspaces:        settings.Workspaces,
+			SearchConcurrency: settings.SearchConcurrency,
+		},
+		Serve: Serve{
+			Addr:             ":8080",
+			WebhookSecretEnv: DEFAULT_SIGNING_SECRET_ENV,
+			SyncInterval:     15 * time.Minute,
+			MirrorInterval:   5 * time.Minute,
+			IdempotencyTTL:   idempotency.DEFAULT_TTL,
+			UndoWindow:       undo.DEFAULT_WINDOW,
+		},
+		OAuth: OAuth{
+			ClientSecretEnv: DEFAULT_CLIENT_SECRET_ENV,
+			LinkSecretEnv:   DEFAULT_LINK_SECRET_ENV,
+			AuthURL:         oauth.DEFAULT_AUTH_URL,
+			TokenURL:        oauth.DEFAULT_TOKEN_URL,
+			Scopes:          []string{"me:read", "workspaces:read", "boards:read", "boards:write", "updates:write"},
+			Accounts:        "accounts.db",
+		},
+	}
+}
+
+// Load reads the file at path, OPS_CONFIG or DEFAULT_PATH over the defaults,
+// then the environment over the file. The config still has to be validated.
+func Load(path string) (*Config, error) {
+	var cfg = Default()
+	if path == "" {
+		path = os.Getenv(ENV_PREFIX + "CONFIG")
+	}
+	if path == "" {
+		if _, err := os.Stat(DEFAULT_PATH); err == nil {
+			path = DEFAULT_PATH
+		}
+	}
+	if path != "" {
+		if err := cfg.read(path); err != nil {
+			return nil, err
+		}
+	}
+	if envFile, ok := os.LookupEnv(ENV_PREFIX + "ENV_FILE"); ok {
+		cfg.EnvFile = envFile
+	}
+	if cfg.EnvFile != "" {
+		if err := godotenv.Load(cfg.EnvFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to load %s: %w", cfg.EnvFile, err)
+		}
+	}
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// read decodes a .toml file as TOML and any other as YAML, refusing the
+// settings it does not know.
+func (c *Config) read(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	// files are cleared to tell the ones set by the file apart
+	var files = map[string]string{}
+	walk(c, func(s setting) error {
+		if s.field.Tag.Get("file") == "true" {
+			files[s.key] = s.value.String()
+			s.value.SetString("")
+		}
+		return nil
+	})
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		meta, err := toml.NewDecoder(bytes.NewReader(content)).Decode(c)
+		if err != nil {
+			return fmt.Errorf("failed to decode config %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("failed to decode config %s: unknown settings %v", path, undecoded)
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to decode config %s: %w", path, err)
+		}
+	}
+	walk(c, func(s setting) error {
+		previous, ok := files[s.key]
+		switch {
+		case !ok:
+		case s.value.String() == "":
+			s.value.SetString(previous)
+		case !filepath.IsAbs(s.value.String()):
+			s.value.SetString(filepath.Join(filepath.Dir(path), s.value.String()))
+		}
+		return nil
+	})
+	c.path = path
+	return nil
+}
+
+// Path is the file the config was read from, empty when there was none.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// applyEnv overrides every setting with its OPS_* variable, if set.
+func (c *Config) applyEnv() error {
+	return walk(c, func(s setting) error {
+		value, ok := os.LookupEnv(s.env())
+		if !ok {
+			return nil
+		}
+		if err := s.set(value); err != nil {
+			return fmt.Errorf("invalid %s: %w", s.env(), err)
+		}
+		return nil
+	})
+}
+
+// ApplyFlags overrides the settings with the flags of flags that were given.
+func (c *Config) ApplyFlags(flags *pflag.FlagSet) error {
+	var given = map[string]string{}
+	flags.Visit(func(f *pflag.Flag) {
+		given[f.Name] = f.Value.String()
+	})
+	return walk(c, func(s setting) error {
+		var name = s.field.Tag.Get("flag")
+		value, ok := given[name]
+		if name == "" || !ok {
+			return nil
+		}
+		if err := s.set(value); err != nil {
+			return fmt.Errorf("invalid --%s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// Validate returns every problem with the config at once.
+func (c *Config) Validate() error {
+	var errs = []error{}
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	u, err := url.Parse(c.Monday.URL)
+	check(err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "", "monday.url %q is not an http(s) URL", c.Monday.URL)
+	check(c.Monday.APIVersion == "" || apiVersion.MatchString(c.Monday.APIVersion), "monday.api_version %q is not a version such as 2024-10", c.Monday.APIVersion)
+	var token = c.Monday.Token
+	check(slices.Contains([]string{secret.SOURCE_ENV, secret.SOURCE_FILE, secret.SOURCE_EXEC, secret.SOURCE_KEYRING}, token.Source),
+		"monday.token.source %q is not %s, %s, %s or %s", token.Source, secret.SOURCE_ENV, secret.SOURCE_FILE, secret.SOURCE_EXEC, secret.SOURCE_KEYRING)
+	check(token.Source != secret.SOURCE_ENV || token.Env != "", "monday.token.env is required")
+	check(token.Source != secret.SOURCE_FILE || token.File != "", "monday.token.file is required")
+	check(token.Source != secret.SOURCE_EXEC || len(token.Command) > 0, "monday.token.command is required")
+	check(token.Source != secret.SOURCE_KEYRING || (token.Keyring != "" && token.KeyringUser != "" && token.Fallback != ""),
+		"monday.token.keyring, monday.token.keyring_user and monday.token.fallback are required")
+	check(token.Refresh > 0, "monday.token.refresh must be positive")
+	check(len(c.Monday.Workspaces) > 0, "monday.workspaces needs at least one workspace")
+	check(!slices.Contains(c.Monday.Workspaces, ""), "monday.workspaces has an empty name")
+	check(c.Monday.DefaultGroup == "" || c.Monday.DefaultBoard != "", "monday.default_group needs monday.default_board")
+	check(c.Monday.SearchConcurrency >= 1, "monday.search_concurrency must be at least 1, not %d", c.Monday.SearchConcurrency)
+	check(c.Cache.Boards >= 0, "cache.boards cannot be negative")
+	check(c.Serve.Addr != "", "serve.addr is required")
+	check(c.Serve.WebhookAddr == "" || c.Serve.Index != "", "serve.webhook_addr needs serve.index")
+	check(c.Serve.WebhookAddr == "" || c.Serve.WebhookSecretEnv != "", "serve.webhook_addr needs serve.webhook_secret_env")
+	check(len(c.Serve.MirrorBoards) == 0 || c.Serve.Mirror != "", "serve.mirror_boards needs serve.mirror")
+	check(c.Serve.Tenants == "" || (c.Serve.Index == "" && c.Serve.Mirror == ""), "serve.index and serve.mirror only hold the boards of monday.token, they cannot serve serve.tenants")
+	check(c.Serve.SyncInterval > 0, "serve.sync_interval must be positive")
+	check(c.Serve.MirrorInterval > 0, "serve.mirror_interval must be positive")
+	check(c.Serve.IdempotencyTTL > 0, "serve.idempotency_ttl must be positive")
+	check(c.Serve.UndoWindow > 0, "serve.undo_window must be positive")
+	check((c.Serve.TLS.Cert == "") == (c.Serve.TLS.Key == ""), "serve.tls.cert and serve.tls.key go together")
+	check(c.Serve.TLS.ClientCA == "" || c.Serve.TLS.Cert != "", "serve.tls.client_ca needs serve.tls.cert and serve.tls.key")
+	check(slices.Contains([]string{tracing.EXPORTER_NONE, tracing.EXPORTER_STDOUT, tracing.EXPORTER_OTLP}, c.Serve.Trace),
+		"serve.trace %q is not %s or %s", c.Serve.Trace, tracing.EXPORTER_STDOUT, tracing.EXPORTER_OTLP)
+	if c.OAuth.Addr != "" {
+		check(c.OAuth.ClientID != "", "oauth.client_id is required")
+		check(c.OAuth.ClientSecretEnv != "", "oauth.client_secret_env is required")
+		check(c.OAuth.LinkSecretEnv != "", "oauth.link_secret_env is required")
+		check(c.OAuth.Accounts != "", "oauth.accounts is required")
+		for _, setting := range [][2]string{{"oauth.redirect_url", c.OAuth.RedirectURL}, {"oauth.auth_url", c.OAuth.AuthURL}, {"oauth.token_url", c.OAuth.TokenURL}} {
+			u, err := url.Parse(setting[1])
+			check(err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "", "%s %q is not an http(s) URL", setting[0], setting[1])
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TokenSource is where the monday API token is read from, on every
+// request.
+func (c *Config) TokenSource() secret.Source {
+	var token = c.Monday.Token
+	switch token.Source {
+	case secret.SOURCE_FILE:
+		return secret.File(token.File)
+	case secret.SOURCE_EXEC:
+		return secret.Exec(token.Command, token.Refresh)
+	case secret.SOURCE_KEYRING:
+		return secret.Keyring(token.Keyring, token.KeyringUser, token.Refresh, token.Fallback)
+	}
+	return secret.Env(token.Env)
+}
+
+// OAuthConfig is the monday app ops is installed as, with the client secret
+// read from the environment.
+func (c *Config) OAuthConfig() (*oauth2.Config, error) {
+	var secret = os.Getenv(c.OAuth.ClientSecretEnv)
+	if secret == "" {
+		return nil, fmt.Errorf("no monday client secret in %s", c.OAuth.ClientSecretEnv)
+	}
+	return &oauth2.Config{
+		ClientID:     c.OAuth.ClientID,
+		ClientSecret: secret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   c.OAuth.AuthURL,
+			TokenURL:  c.OAuth.TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		RedirectURL: c.OAuth.RedirectURL,
+		Scopes:      c.OAuth.Scopes,
+	}, nil
+}
+
+// LinkSecret is the secret install links are signed with, read from the
+// environment.
+func (c *Config) LinkSecret() ([]byte, error) {
+	var secret = os.Getenv(c.OAuth.LinkSecretEnv)
+	if secret == "" {
+		return nil, fmt.Errorf("no install link secret in %s", c.OAuth.LinkSecretEnv)
+	}
+	return []byte(secret), nil
+}
+
+// WebhookSecret is the signing secret of the monday app, read from the
+// environment.
+func (c *Config) WebhookSecret() ([]byte, error) {
+	var secret = os.Getenv(c.Serve.WebhookSecretEnv)
+	if secret == "" {
+		return nil, fmt.Errorf("no monday signing secret in %s", c.Serve.WebhookSecretEnv)
+	}
+	return []byte(secret), nil
+}
+
+// defaultFallback is where the keyring token is kept when there is no
+// keyring, next to the other settings of the user.
+func defaultFallback() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "ops", "monday.token")
+}
+
+// Settings are the settings of the monday client.
+func (c *Config) Settings() monday.Settings {
+	return monday.Settings{
+		APIVersion:        c.Monday.APIVersion,
+		Workspaces:        c.Monday.Workspaces,
+		DefaultBoard:      c.Monday.DefaultBoard,
+		DefaultGroup:      c.Monday.DefaultGroup,
+		SearchConcurrency: c.Monday.SearchConcurrency,
+		BoardsTTL:         c.Cache.Boards,
+	}
+}
+
+// Reload takes the settings tagged reload from next. It returns the ones
+// that changed, and the other changed settings, which need a restart.
+func (c *Config) Reload(next *Config) (applied, restart []string) {
+	var current = map[string]setting{}
+	walk(c, func(s setting) error {
+		current[s.key] = s
+		return nil
+	})
+	walk(next, func(s setting) error {
+		var old = current[s.key]
+		if reflect.DeepEqual(old.value.Interface(), s.value.Interface()) {
+			return nil
+		}
+		if s.field.Tag.Get("reload") != "true" {
+			restart = append(restart, s.key)
+			return nil
+		}
+		applied = append(applied, s.key)
+		old.value.Set(s.value)
+		return nil
+	})
+	return applied, restart
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ENV_TEST is set by the env files of the tests only.
+const ENV_TEST = "OPS_CONFIG_TEST_ENV"
+
+// writeEnv writes an env file setting ENV_TEST to value at path, and unsets
+// ENV_TEST at the end of the test.
+func writeEnv(t *testing.T, path, value string) {
+	if err := os.WriteFile(path, []byte(ENV_TEST+"="+value+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ENV_TEST, "")
+	os.Unsetenv(ENV_TEST)
+}
+
+func TestLoadReadsNoEnvFileByDefault(t *testing.T) {
+	var dir = t.TempDir()
+	writeEnv(t, filepath.Join(dir, ".env"), "parent")
+	var cwd = filepath.Join(dir, "ops")
+	if err := os.Mkdir(cwd, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	writeEnv(t, filepath.Join(cwd, ".env"), "cwd")
+	t.Chdir(cwd)
+	t.Setenv(ENV_PREFIX+"CONFIG", "")
+	t.Setenv(ENV_PREFIX+"ENV_FILE", "")
+	os.Unsetenv(ENV_PREFIX + "ENV_FILE")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.EnvFile != "" {
+		t.Errorf("EnvFile = %q, want none", cfg.EnvFile)
+	}
+	if value, ok := os.LookupEnv(ENV_TEST); ok {
+		t.Errorf("env file setting %s=%s read from %s", ENV_TEST, value, cwd)
+	}
+}
+
+func TestLoadEnvFileRelativeToConfig(t *testing.T) {
+	var dir = t.TempDir()
+	writeEnv(t, filepath.Join(dir, ".env"), "config")
+	var path = filepath.Join(dir, "ops.yaml")
+	if err := os.WriteFile(path, []byte("env_file: .env\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+	t.Setenv(ENV_PREFIX+"ENV_FILE", "")
+	os.Unsetenv(ENV_PREFIX + "ENV_FILE")
+
+	if _, err := Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if value := os.Getenv(ENV_TEST); value != "config" {
+		t.Errorf("%s = %q, want the one of the env file next to the config", ENV_TEST, value)
+	}
+}
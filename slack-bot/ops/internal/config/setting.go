@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting is one leaf of the config, e.g. serve.tls.cert.
+type setting struct {
+	key   string
+	field reflect.StructField
+	value reflect.Value
+}
+
+// walk calls fn with every setting of cfg, stopping at the first error.
+func walk(cfg *Config, fn func(s setting) error) error {
+	return walkStruct(reflect.ValueOf(cfg).Elem(), "", fn)
+}
+
+func walkStruct(v reflect.Value, prefix string, fn func(s setting) error) error {
+	for i := 0; i < v.NumField(); i++ {
+		var field = v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		var key = prefix + field.Tag.Get("yaml")
+		if field.Type.Kind() == reflect.Struct {
+			if err := walkStruct(v.Field(i), key+".", fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(setting{key: key, field: field, value: v.Field(i)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// env is the variable overriding the setting, e.g. OPS_SERVE_TLS_CERT.
+func (s setting) env() string {
+	return ENV_PREFIX + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+// set parses value into the setting, lists being comma separated.
+func (s setting) set(value string) error {
+	switch s.value.Interface().(type) {
+	case string:
+		s.value.SetString(value)
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s is not a number", value)
+		}
+		s.value.SetInt(int64(n))
+	case time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s is not a duration", value)
+		}
+		s.value.SetInt(int64(d))
+	case []string:
+		var values = []string{}
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		s.value.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("cannot set %s from text", s.key)
+	}
+	return nil
+}
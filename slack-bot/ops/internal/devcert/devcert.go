@@ -0,0 +1,134 @@
+// Package devcert generates a throwaway CA with a server and a client
+// certificate, to try TLS and mutual TLS between the bot and ops locally.
+package devcert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	CA_CERT     = "ca.crt"
+	CA_KEY      = "ca.key"
+	SERVER_CERT = "server.crt"
+	SERVER_KEY  = "server.key"
+	CLIENT_CERT = "client.crt"
+	CLIENT_KEY  = "client.key"
+)
+
+type pair struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// Generate writes a CA, a server certificate valid for hosts (names or IP
+// addresses) and a client certificate with client as common name to dir.
+func Generate(dir string, hosts []string, client string, validFor time.Duration) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	var notAfter = time.Now().Add(validFor)
+	ca, err := issue(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "SPO dev CA"},
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	var serverTemplate = &x509.Certificate{
+		Subject:     pkix.Name{CommonName: hosts[0]},
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, h)
+		}
+	}
+	server, err := issue(serverTemplate, ca)
+	if err != nil {
+		return err
+	}
+	clientPair, err := issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: client},
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+	if err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		cert, key string
+		pair      *pair
+	}{{CA_CERT, CA_KEY, ca}, {SERVER_CERT, SERVER_KEY, server}, {CLIENT_CERT, CLIENT_KEY, clientPair}} {
+		if err := write(dir, f.cert, f.key, f.pair); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// issue signs template with parent, or self-signs it when parent is nil.
+func issue(template *x509.Certificate, parent *pair) (*pair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	template.SerialNumber = serial
+	// tolerate clocks a little behind
+	template.NotBefore = time.Now().Add(-time.Hour)
+	var signer, signerKey = template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign %s: %w", template.Subject.CommonName, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &pair{cert: cert, key: key}, nil
+}
+
+// write saves the certificate of p and its key, readable by the owner only,
+// to dir.
+func write(dir, certName, keyName string, p *pair) error {
+	var certPath = filepath.Join(dir, certName)
+	var certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: p.cert.Raw})
+	if err := os.WriteFile(certPath, certPEM, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", certPath, err)
+	}
+	der, err := x509.MarshalECPrivateKey(p.key)
+	if err != nil {
+		return err
+	}
+	var keyPath = filepath.Join(dir, keyName)
+	var keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", keyPath, err)
+	}
+	return nil
+}
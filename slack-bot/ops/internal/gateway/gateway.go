@@ -0,0 +1,364 @@
+// Package gateway serves MondayService as REST/JSON for the tools that
+// cannot speak gRPC. Every route calls the gRPC service, so requests are
+// authenticated and authorized as gRPC calls are, client certificates
+// included when the gateway uses a ForwardedPeer.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/rpcauth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const OPENAPI_PATH = "/openapi.json"
+
+// MAX_BODY is the largest request body read, the largest message the gRPC
+// server receives by default; larger ones are answered 413.
+const MAX_BODY = 4 << 20
+
+// FORWARDED_HEADERS are passed on to the gRPC service as metadata: the
+// client credentials, the caller the request is made for and the trace the
+// request is part of.
+var FORWARDED_HEADERS = []string{
+	rpcauth.AUTHORIZATION_KEY,
+	rpcauth.CLIENT_KEY,
+	rpcauth.TIMESTAMP_KEY,
+	rpcauth.SIGNATURE_KEY,
+	rpcauth.NONCE_KEY,
+	authz.USER_KEY,
+	authz.GROUPS_KEY,
+	authz.CHANNEL_KEY,
+	authz.TEAM_KEY,
+	authz.TENANT_KEY,
+	"traceparent",
+	"tracestate",
+}
+
+var (
+	marshaler   = protojson.MarshalOptions{}
+	unmarshaler = protojson.UnmarshalOptions{}
+)
+
+// route maps an HTTP method and path to an RPC. Path wildcards and, without
+// a body, query parameters fill in the request fields of the same name.
+type route struct {
+	method  string
+	path    string
+	rpc     string
+	body    bool
+	summary string
+	call    func(ctx context.Context, client pb.MondayServiceClient, req proto.Message) (proto.Message, error)
+	stream  func(ctx context.Context, client pb.MondayServiceClient, req proto.Message) (grpc.ServerStreamingClient[pb.FindItemResponse], error)
+	request func() proto.Message
+}
+
+var routes = []route{
+	{
+		method: http.MethodGet, path: "/v1/items", rpc: "FindItem",
+		summary: "Find items, streamed as NDJSON or, when asked with Accept: text/event-stream, as server-sent events",
+		request: func() proto.Message { return &pb.FindItemRequest{} },
+		stream: func(ctx context.Context, client pb.MondayServiceClient, req proto.Message) (grpc.ServerStreamingClient[pb.FindItemResponse], error) {
+			return client.FindItem(ctx, req.(*pb.FindItemRequest))
+		},
+	},
+	{
+		method: http.MethodPost, path: "/v1/items", rpc: "CreateItem", body: true,
+		summary: "Create an item",
+		request: func() proto.Message { return &pb.CreateItemRequest{} },
+		call: func(ctx context.Context, client pb.MondayServiceClient, req proto.Message) (proto.Message, error) {
+			return client.CreateItem(ctx, req.(*pb.CreateItemRequest))
+		},
+	},
+	{
+		method: http.MethodPatch, path: "/v1/items/{id}", rpc: "UpdateItem", body: true,
+		summary: "Rename an item or change its column values",
+		request: func() proto.Message { return &pb.UpdateItemRequest{} },
+		call: func(ctx context.Context, client pb.MondayServiceClient, req proto.Message) (proto.Message, error) {
+			return client.UpdateItem(ctx, req.(*pb.UpdateItemRequest))
+		},
+	},
+	{
+		method: http.MethodPost, path: "/v1/items/{id}/archive", rpc: "ArchiveItem",
+		summary: "Archive an item",
+		request: func() proto.Message { return &pb.ArchiveItemRequest{} },
+		call: func(ctx context.Context, client pb.MondayServiceClient, req proto.Message) (proto.Message, error) {
+			return client.ArchiveItem(ctx, req.(*pb.ArchiveItemRequest))
+		},
+	},
+	{
+		method: http.MethodPost, path: "/v1/items/{item_id}/notes", rpc: "AddNote", body: true,
+		summary: "Post an update on an item",
+		request: func() proto.Message { return &pb.AddNoteRequest{} },
+		call: func(ctx context.Context, client pb.MondayServiceClient, req proto.Message) (proto.Message, error) {
+			return client.AddNote(ctx, req.(*pb.AddNoteRequest))
+		},
+	},
+	{
+		method: http.MethodPost, path: "/v1/operations/{operation_id}/revert", rpc: "Revert",
+		summary: "Revert a change of the caller: delete the item created or the note posted, or put back what an update changed",
+		request: func() proto.Message { return &pb.RevertRequest{} },
+		call: func(ctx context.Context, client pb.MondayServiceClient, req proto.Message) (proto.Message, error) {
+			return client.Revert(ctx, req.(*pb.RevertRequest))
+		},
+	},
+	{
+		method: http.MethodGet, path: "/v1/boards", rpc: "DescribeBoard",
+		summary: "Describe every board in the workspace",
+		request: func() proto.Message { return &pb.DescribeBoardRequest{} },
+		call: func(ctx context.Context, client pb.MondayServiceClient, req proto.Message) (proto.Message, error) {
+			return client.DescribeBoard(ctx, req.(*pb.DescribeBoardRequest))
+		},
+	},
+	{
+		method: http.MethodGet, path: "/v1/boards/{board}", rpc: "DescribeBoard",
+		summary: "Describe a board",
+		request: func() proto.Message { return &pb.DescribeBoardRequest{} },
+		call: func(ctx context.Context, client pb.MondayServiceClient, req proto.Message) (proto.Message, error) {
+			return client.DescribeBoard(ctx, req.(*pb.DescribeBoardRequest))
+		},
+	},
+}
+
+type Gateway struct {
+	client  pb.MondayServiceClient
+	mux     *http.ServeMux
+	openapi []byte
+	peer    *rpcauth.ForwardedPeer
+}
+
+// New returns the REST/JSON gateway calling client, along with its OpenAPI
+// document at OPENAPI_PATH.
+func New(client pb.MondayServiceClient) (*Gateway, error) {
+	openapi, err := OpenAPI()
+	if err != nil {
+		return nil, err
+	}
+	var g = &Gateway{client: client, mux: http.NewServeMux(), openapi: openapi}
+	for _, r := range routes {
+		g.mux.HandleFunc(r.method+" "+r.path, g.handle(r))
+	}
+	g.mux.HandleFunc(http.MethodGet+" "+OPENAPI_PATH, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(g.openapi)
+	})
+	return g, nil
+}
+
+// UseForwardedPeer passes the client certificates of mutual TLS requests on
+// to the gRPC service, which only trusts them with the server options of
+// peer.
+func (g *Gateway) UseForwardedPeer(peer *rpcauth.ForwardedPeer) {
+	g.peer = peer
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+func (g *Gateway) handle(rt route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req = rt.request()
+		r.Body = http.MaxBytesReader(w, r.Body, MAX_BODY)
+		if err := decodeRequest(r, rt, req); err != nil {
+			writeError(w, err)
+			return
+		}
+		var ctx = g.forwardHeaders(r)
+		if rt.stream != nil {
+			stream, err := rt.stream(ctx, g.client, req)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			writeStream(w, r, stream)
+			return
+		}
+		resp, err := rt.call(ctx, g.client, req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeMessage(w, http.StatusOK, resp)
+	}
+}
+
+// decodeRequest fills req from the body of r, then from its path wildcards
+// and, for requests without a body, its query parameters.
+func decodeRequest(r *http.Request, rt route, req proto.Message) error {
+	if rt.body {
+		content, err := io.ReadAll(r.Body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return err
+		}
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "failed to read body: %s", err)
+		}
+		if len(strings.TrimSpace(string(content))) > 0 {
+			if err := unmarshaler.Unmarshal(content, req); err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid body: %s", err)
+			}
+		}
+	}
+	var fields = req.ProtoReflect().Descriptor().Fields()
+	for _, name := range pathParams(rt.path) {
+		if err := setField(req, fields.ByName(protoreflect.Name(name)), r.PathValue(name)); err != nil {
+			return err
+		}
+	}
+	if rt.body {
+		return nil
+	}
+	for key, values := range r.URL.Query() {
+		var field = fieldByName(fields, key)
+		if field == nil {
+			return status.Errorf(codes.InvalidArgument, "unknown parameter %s", key)
+		}
+		if err := setField(req, field, values[len(values)-1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByName finds a field by its proto name, e.g. item_id, or JSON name.
+func fieldByName(fields protoreflect.FieldDescriptors, name string) protoreflect.FieldDescriptor {
+	if field := fields.ByName(protoreflect.Name(name)); field != nil {
+		return field
+	}
+	return fields.ByJSONName(name)
+}
+
+// setField sets a scalar field of req from its text value.
+func setField(req proto.Message, field protoreflect.FieldDescriptor, value string) error {
+	if field.IsList() || field.IsMap() {
+		return status.Errorf(codes.InvalidArgument, "cannot set %s from the URL", field.Name())
+	}
+	var v protoreflect.Value
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		v = protoreflect.ValueOfString(value)
+	case protoreflect.BoolKind:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "%s must be true or false", field.Name())
+		}
+		v = protoreflect.ValueOfBool(parsed)
+	case protoreflect.Int32Kind:
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "%s must be a number", field.Name())
+		}
+		v = protoreflect.ValueOfInt32(int32(parsed))
+	case protoreflect.EnumKind:
+		// SORT_RELEVANCE can be given as relevance
+		var values = field.Enum().Values()
+		enum := values.ByName(protoreflect.Name(strings.ToUpper(value)))
+		if enum == nil {
+			enum = values.ByName(protoreflect.Name(strings.ToUpper(string(field.Enum().Name()) + "_" + value)))
+		}
+		if enum == nil {
+			return status.Errorf(codes.InvalidArgument, "invalid %s %s", field.Name(), value)
+		}
+		v = protoreflect.ValueOfEnum(enum.Number())
+	default:
+		return status.Errorf(codes.InvalidArgument, "cannot set %s from the URL", field.Name())
+	}
+	req.ProtoReflect().Set(field, v)
+	return nil
+}
+
+// pathParams returns the names of the wildcards of path, e.g. id for
+// /v1/items/{id}.
+func pathParams(path string) []string {
+	var names = []string{}
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			names = append(names, strings.Trim(segment, "{}"))
+		}
+	}
+	return names
+}
+
+// forwardHeaders returns the context of a call made on behalf of r.
+func (g *Gateway) forwardHeaders(r *http.Request) context.Context {
+	var md = metadata.MD{}
+	for _, key := range FORWARDED_HEADERS {
+		if value := r.Header.Get(key); value != "" {
+			md.Set(key, value)
+		}
+	}
+	var ctx = metadata.NewOutgoingContext(r.Context(), md)
+	if g.peer != nil {
+		ctx = g.peer.OutgoingContext(ctx, r.TLS)
+	}
+	return ctx
+}
+
+func writeMessage(w http.ResponseWriter, code int, msg proto.Message) {
+	content, err := marshaler.Marshal(msg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(content)
+}
+
+// writeError answers with the HTTP status matching the gRPC one of err and
+// the status itself, e.g. {"code":5, "message":"..."}.
+func writeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		var s = status.New(codes.InvalidArgument, fmt.Sprintf("body larger than %d bytes", tooLarge.Limit))
+		writeMessage(w, http.StatusRequestEntityTooLarge, s.Proto())
+		return
+	}
+	var s = status.Convert(err)
+	writeMessage(w, httpStatus(s.Code()), s.Proto())
+}
+
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
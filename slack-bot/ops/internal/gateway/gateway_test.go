@@ -0,0 +1,153 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/devcert"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/rpcauth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// whoServer answers with the name of the client calling it.
+type whoServer struct {
+	pb.UnimplementedMondayServiceServer
+}
+
+func (whoServer) DescribeBoard(ctx context.Context, req *pb.DescribeBoardRequest) (*pb.DescribeBoardResponse, error) {
+	return &pb.DescribeBoardResponse{Boards: []*pb.BoardDescription{{Name: rpcauth.ClientFromContext(ctx)}}}, nil
+}
+
+func (whoServer) FindItem(req *pb.FindItemRequest, stream grpc.ServerStreamingServer[pb.FindItemResponse]) error {
+	return stream.Send(&pb.FindItemResponse{Name: rpcauth.ClientFromContext(stream.Context())})
+}
+
+// inProcess serves whoServer in memory, trusting the names peer forwards,
+// and returns a client of it.
+func inProcess(t *testing.T, peer *rpcauth.ForwardedPeer) pb.MondayServiceClient {
+	var listener = bufconn.Listen(1 << 20)
+	var server = grpc.NewServer(peer.ServerOptions()...)
+	pb.RegisterMondayServiceServer(server, whoServer{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///gateway",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewMondayServiceClient(conn)
+}
+
+// serveMutualTLS serves handler asking for client certificates, and returns
+// a client presenting the one named reporting.
+func serveMutualTLS(t *testing.T, handler http.Handler) (*httptest.Server, *http.Client) {
+	var dir = t.TempDir()
+	if err := devcert.Generate(dir, []string{"127.0.0.1"}, "reporting", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	var server = httptest.NewUnstartedServer(handler)
+	config, err := rpcauth.ServerTLSConfig(filepath.Join(dir, devcert.SERVER_CERT), filepath.Join(dir, devcert.SERVER_KEY), filepath.Join(dir, devcert.CA_CERT))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.TLS = config
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, devcert.CLIENT_CERT), filepath.Join(dir, devcert.CLIENT_KEY))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := os.ReadFile(filepath.Join(dir, devcert.CA_CERT))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pool = x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca)
+	var client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, Certificates: []tls.Certificate{cert}}}}
+	return server, client
+}
+
+func get(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	// a client of the gateway cannot name itself
+	req.Header.Set(rpcauth.FORWARDED_PEER_KEY, "admin")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s answered %d %s", url, resp.StatusCode, body)
+	}
+	return strings.TrimSpace(string(body))
+}
+
+func TestClientCertificatesReachTheService(t *testing.T) {
+	var peer = rpcauth.NewForwardedPeer()
+	var service = inProcess(t, peer)
+	gw, err := New(service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw.UseForwardedPeer(peer)
+	server, client := serveMutualTLS(t, gw)
+
+	var boards = pb.DescribeBoardResponse{}
+	if err := json.Unmarshal([]byte(get(t, client, server.URL+"/v1/boards")), &boards); err != nil {
+		t.Fatal(err)
+	}
+	if len(boards.Boards) != 1 || boards.Boards[0].Name != "reporting" {
+		t.Errorf("DescribeBoard called by %v, want the client certificate reporting", boards.Boards)
+	}
+	var found = pb.FindItemResponse{}
+	if err := json.Unmarshal([]byte(get(t, client, server.URL+"/v1/items?value=ann")), &found); err != nil {
+		t.Fatal(err)
+	}
+	if found.Name != "reporting" {
+		t.Errorf("FindItem called by %q, want the client certificate reporting", found.Name)
+	}
+
+	// without the secret of the process the name is not trusted
+	var forged = metadata.AppendToOutgoingContext(context.Background(),
+		rpcauth.FORWARDED_PEER_KEY, "admin", rpcauth.FORWARDED_SECRET_KEY, "guess")
+	resp, err := service.DescribeBoard(forged, &pb.DescribeBoardRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := resp.Boards[0].Name; name != "" {
+		t.Errorf("forged peer trusted as %q", name)
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	gw, err := New(inProcess(t, rpcauth.NewForwardedPeer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body = `{"name":"` + strings.Repeat("a", MAX_BODY) + `"}`
+	var w = httptest.NewRecorder()
+	gw.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/items", strings.NewReader(body)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST of %d bytes answered %d, want %d", len(body), w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
@@ -0,0 +1,229 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// OpenAPI returns the OpenAPI 3 document of the gateway. Schemas are
+// generated from the messages of ops.proto, so they follow the proto as it
+// changes.
+func OpenAPI() ([]byte, error) {
+	var service = pb.File_ops_proto.Services().ByName("MondayService")
+	var schemas = map[string]any{}
+	var paths = map[string]map[string]any{}
+	for _, rt := range routes {
+		var method = service.Methods().ByName(protoreflect.Name(rt.rpc))
+		if method == nil {
+			return nil, fmt.Errorf("no rpc %s in %s", rt.rpc, service.FullName())
+		}
+		addSchema(schemas, method.Input())
+		addSchema(schemas, method.Output())
+		var operation = map[string]any{
+			"operationId": operationId(rt),
+			"summary":     rt.summary,
+			"tags":        []string{string(service.Name())},
+			"parameters":  parameters(rt, method.Input()),
+			"responses":   responses(rt, method.Output()),
+		}
+		if rt.body {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": ref(method.Input())}},
+			}
+		}
+		if paths[rt.path] == nil {
+			paths[rt.path] = map[string]any{}
+		}
+		paths[rt.path][strings.ToLower(rt.method)] = operation
+	}
+	addSchema(schemas, statusDescriptor())
+	var document = map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   string(service.FullName()),
+			"version": "v1",
+		},
+		"paths": paths,
+		// a bearer token when the server has a -clients file
+		"security": []any{map[string]any{"bearer": []string{}}, map[string]any{}},
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// operationId is the rpc name, suffixed for the rpcs served on several paths.
+func operationId(rt route) string {
+	var count = 0
+	for _, other := range routes {
+		if other.rpc == rt.rpc {
+			count++
+		}
+	}
+	if count == 1 {
+		return rt.rpc
+	}
+	var params = pathParams(rt.path)
+	if len(params) == 0 {
+		return rt.rpc + "All"
+	}
+	return rt.rpc + "By" + camel(params[len(params)-1])
+}
+
+// parameters lists the path wildcards and, for routes without a body, the
+// other request fields as query parameters.
+func parameters(rt route, input protoreflect.MessageDescriptor) []any {
+	var params = []any{}
+	var inPath = pathParams(rt.path)
+	for _, name := range inPath {
+		params = append(params, map[string]any{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   fieldSchema(input.Fields().ByName(protoreflect.Name(name))),
+		})
+	}
+	if rt.body {
+		return params
+	}
+	var fields = input.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		var field = fields.Get(i)
+		if slices.Contains(inPath, string(field.Name())) || field.IsList() || field.IsMap() || field.Message() != nil {
+			continue
+		}
+		params = append(params, map[string]any{
+			"name":   string(field.Name()),
+			"in":     "query",
+			"schema": fieldSchema(field),
+		})
+	}
+	return params
+}
+
+func responses(rt route, output protoreflect.MessageDescriptor) map[string]any {
+	var ok = map[string]any{"description": "OK"}
+	if rt.stream != nil {
+		ok["content"] = map[string]any{
+			NDJSON_TYPE: map[string]any{"schema": ref(output)},
+			SSE_TYPE:    map[string]any{"schema": map[string]any{"type": "string", "description": "item events, then an end or error event"}},
+		}
+	} else {
+		ok["content"] = map[string]any{"application/json": map[string]any{"schema": ref(output)}}
+	}
+	return map[string]any{
+		"200": ok,
+		"default": map[string]any{
+			"description": "The gRPC status of the failed call",
+			"content":     map[string]any{"application/json": map[string]any{"schema": ref(statusDescriptor())}},
+		},
+	}
+}
+
+// addSchema adds the schema of msg, and of the messages it refers to, to
+// schemas.
+func addSchema(schemas map[string]any, msg protoreflect.MessageDescriptor) {
+	var name = schemaName(msg)
+	if _, ok := schemas[name]; ok || isWellKnown(msg) {
+		return
+	}
+	var properties = map[string]any{}
+	schemas[name] = map[string]any{"type": "object", "properties": properties}
+	var fields = msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		var field = fields.Get(i)
+		properties[field.JSONName()] = fieldSchema(field)
+		if field.IsMap() {
+			field = field.MapValue()
+		}
+		if field.Message() != nil {
+			addSchema(schemas, field.Message())
+		}
+	}
+}
+
+func fieldSchema(field protoreflect.FieldDescriptor) map[string]any {
+	if field.IsMap() {
+		return map[string]any{"type": "object", "additionalProperties": scalarSchema(field.MapValue())}
+	}
+	if field.IsList() {
+		return map[string]any{"type": "array", "items": scalarSchema(field)}
+	}
+	return scalarSchema(field)
+}
+
+func scalarSchema(field protoreflect.FieldDescriptor) map[string]any {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson writes 64 bit integers as strings
+		return map[string]any{"type": "string", "format": "int64"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]any{"type": "number"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "format": "byte"}
+	case protoreflect.EnumKind:
+		var names = []string{}
+		var values = field.Enum().Values()
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return map[string]any{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return ref(field.Message())
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+func ref(msg protoreflect.MessageDescriptor) map[string]any {
+	if msg.FullName() == "google.protobuf.Timestamp" {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if isWellKnown(msg) {
+		return map[string]any{"type": "object"}
+	}
+	return map[string]any{"$ref": "#/components/schemas/" + schemaName(msg)}
+}
+
+func isWellKnown(msg protoreflect.MessageDescriptor) bool {
+	return msg.ParentFile().Package() == "google.protobuf"
+}
+
+func schemaName(msg protoreflect.MessageDescriptor) string {
+	if msg.ParentFile().Package() == pb.File_ops_proto.Package() {
+		return string(msg.Name())
+	}
+	return string(msg.FullName())
+}
+
+// statusDescriptor is the message errors are answered with.
+func statusDescriptor() protoreflect.MessageDescriptor {
+	return (&spb.Status{}).ProtoReflect().Descriptor()
+}
+
+func camel(name string) string {
+	var parts = strings.Split(name, "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	NDJSON_TYPE = "application/x-ndjson"
+	SSE_TYPE    = "text/event-stream"
+)
+
+// writeStream sends every item of stream as soon as it is received, one JSON
+// object per line or, when the client accepts it, as server-sent events. An
+// error before the first item is answered with its HTTP status; once items
+// were sent, it is sent as a last {"error": status} line or "error" event.
+func writeStream(w http.ResponseWriter, r *http.Request, stream grpc.ServerStreamingClient[pb.FindItemResponse]) {
+	var sse = strings.Contains(r.Header.Get("Accept"), SSE_TYPE)
+	flusher, _ := w.(http.Flusher)
+	item, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		writeError(w, err)
+		return
+	}
+	if sse {
+		w.Header().Set("Content-Type", SSE_TYPE)
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Content-Type", NDJSON_TYPE)
+	}
+	w.WriteHeader(http.StatusOK)
+	for ; err == nil; item, err = stream.Recv() {
+		content, marshalErr := marshaler.Marshal(item)
+		if marshalErr != nil {
+			err = marshalErr
+			break
+		}
+		if sse {
+			fmt.Fprintf(w, "event: item\ndata: %s\n\n", content)
+		} else {
+			fmt.Fprintf(w, "%s\n", content)
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if err == io.EOF {
+		if sse {
+			fmt.Fprint(w, "event: end\ndata: {}\n\n")
+		}
+		return
+	}
+	content, _ := marshaler.Marshal(status.Convert(err).Proto())
+	if sse {
+		fmt.Fprintf(w, "event: error\ndata: %s\n\n", content)
+	} else {
+		fmt.Fprintf(w, "{\"error\":%s}\n", content)
+	}
+}
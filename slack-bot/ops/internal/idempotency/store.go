@@ -0,0 +1,147 @@
+// Package idempotency remembers the items created for the idempotency keys
+// of the callers, for a retried request to return the item its first try
+// created rather than creating another.
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// DEFAULT_TTL is how long a key is remembered when the config does not say.
+const DEFAULT_TTL = 24 * time.Hour
+
+// CLAIM_TIMEOUT is how long a key whose item is still being created holds
+// off its retries. Past it, the server is taken to have stopped before
+// monday answered and the key is free again.
+const CLAIM_TIMEOUT = 2 * time.Minute
+
+var (
+	// ErrKeyReused is returned for a key seen before with another request.
+	ErrKeyReused = errors.New("idempotency key already used for another request")
+	// ErrInProgress is returned for a key whose item is still being created.
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// item_id is empty while the item is being created; created_at is stored
+// as RFC 3339 with nanoseconds in UTC, which sorts as text
+const schema = `
+CREATE TABLE IF NOT EXISTS keys (
+	scope TEXT NOT NULL,
+	key TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	item_id TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	PRIMARY KEY (scope, key)
+);
+CREATE INDEX IF NOT EXISTS keys_created_at ON keys(created_at);`
+
+const timeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// Store keeps the keys of the last ttl and the items created for them, in a
+// SQLite file only its owner may read.
+type Store struct {
+	db  *sql.DB
+	ttl time.Duration
+}
+
+func OpenStore(path string, ttl time.Duration) (*Store, error) {
+	if ttl <= 0 {
+		ttl = DEFAULT_TTL
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open idempotency keys %s: %w", path, err)
+	}
+	// a single connection serializes the claims of a key
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create idempotency schema: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to restrict idempotency keys %s: %w", path, err)
+	}
+	return &Store{db: db, ttl: ttl}, nil
+}
+
+// Do calls create once for the key of scope and remembers the id it
+// returns. Later calls with the key return that id with replayed set,
+// unless their fingerprint differs from the first one, which is refused
+// with ErrKeyReused. A failed create forgets the key, for a retry to try
+// again. create gets ctx without its cancellation: a create under way is
+// finished even when the caller goes away, its retry is the one to get it.
+func (s *Store) Do(ctx context.Context, scope, key, fingerprint string, create func(ctx context.Context) (string, error)) (id string, replayed bool, err error) {
+	claimed, err := s.claim(ctx, scope, key, fingerprint)
+	if err != nil {
+		return "", false, err
+	}
+	if !claimed {
+		return s.replay(ctx, scope, key, fingerprint)
+	}
+	// the outcome is kept even when the caller went away meanwhile, its
+	// retry is the one to get it
+	ctx = context.WithoutCancel(ctx)
+	id, err = create(ctx)
+	if err != nil {
+		if _, forgetErr := s.db.ExecContext(ctx, `DELETE FROM keys WHERE scope = ? AND key = ?`, scope, key); forgetErr != nil {
+			return "", false, errors.Join(err, fmt.Errorf("failed to release idempotency key: %w", forgetErr))
+		}
+		return "", false, err
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE keys SET item_id = ? WHERE scope = ? AND key = ?`, id, scope, key); err != nil {
+		return id, false, fmt.Errorf("failed to remember item %s of idempotency key: %w", id, err)
+	}
+	return id, false, nil
+}
+
+// claim forgets the expired keys and the abandoned claims, then claims key,
+// telling whether it was free.
+func (s *Store) claim(ctx context.Context, scope, key, fingerprint string) (bool, error) {
+	var now = time.Now().UTC()
+	_, err := s.db.ExecContext(ctx, `DELETE FROM keys WHERE created_at < ? OR (item_id = '' AND created_at < ?)`,
+		now.Add(-s.ttl).Format(timeFormat), now.Add(-CLAIM_TIMEOUT).Format(timeFormat))
+	if err != nil {
+		return false, fmt.Errorf("failed to expire idempotency keys: %w", err)
+	}
+	result, err := s.db.ExecContext(ctx, `INSERT INTO keys(scope, key, fingerprint, item_id, created_at) VALUES(?, ?, ?, '', ?)
+		ON CONFLICT(scope, key) DO NOTHING`, scope, key, fingerprint, now.Format(timeFormat))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	return claimed == 1, nil
+}
+
+func (s *Store) replay(ctx context.Context, scope, key, fingerprint string) (string, bool, error) {
+	var seen, id string
+	err := s.db.QueryRowContext(ctx, `SELECT fingerprint, item_id FROM keys WHERE scope = ? AND key = ?`, scope, key).Scan(&seen, &id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the first request failed in between, this one is the retry
+		return "", false, ErrInProgress
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+	switch {
+	case seen != fingerprint:
+		return "", false, ErrKeyReused
+	case id == "":
+		return "", false, ErrInProgress
+	}
+	return id, true, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,276 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/rank"
+	_ "modernc.org/sqlite"
+)
+
+var (
+	// ErrUnsupportedColumn is returned for searches on columns the index does
+	// not cover; callers should fall back to a live query.
+	ErrUnsupportedColumn = errors.New("column is not indexed")
+	// ErrNotSynced is returned until the first full pull completed.
+	ErrNotSynced = errors.New("index was never synced")
+)
+
+// maxCandidates bounds how many full text matches are ranked in memory.
+const maxCandidates = 200
+
+const schema = `
+CREATE TABLE IF NOT EXISTS items (
+	id TEXT PRIMARY KEY,
+	board_id TEXT NOT NULL,
+	doc TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS items_board ON items(board_id);
+CREATE VIRTUAL TABLE IF NOT EXISTS items_fts USING fts5(id UNINDEXED, name, email, phone, tokenize='trigram');
+CREATE TABLE IF NOT EXISTS meta (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);`
+
+// Index is an on disk full text index of the items of the contacts
+// workspace. Lookups are typo tolerant: candidates sharing trigrams with the
+// searched value are ranked and the ones that do not match closely enough
+// are dropped.
+type Index struct {
+	db *sql.DB
+}
+
+func Open(path string) (*Index, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index %s: %w", path, err)
+	}
+	// a single connection serializes writers and avoids SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create index schema: %w", err)
+	}
+	return &Index{db: db}, nil
+}
+
+func (idx *Index) Close() error {
+	return idx.db.Close()
+}
+
+func (idx *Index) Upsert(ctx context.Context, items ...monday.Item) error {
+	tx, err := idx.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, item := range items {
+		if err := upsert(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (idx *Index) Delete(ctx context.Context, ids ...string) error {
+	tx, err := idx.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, id := range ids {
+		if err := remove(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ReplaceBoard makes items the only indexed items of the board.
+func (idx *Index) ReplaceBoard(ctx context.Context, boardId string, items []monday.Item) error {
+	tx, err := idx.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM items_fts WHERE id IN (SELECT id FROM items WHERE board_id = ?)`, boardId); err != nil {
+		return fmt.Errorf("failed to clear board %s: %w", boardId, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM items WHERE board_id = ?`, boardId); err != nil {
+		return fmt.Errorf("failed to clear board %s: %w", boardId, err)
+	}
+	for _, item := range items {
+		if err := upsert(ctx, tx, item); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RetainBoards drops every item whose board is not in boardIds, e.g. after
+// a board was deleted or moved out of the workspace.
+func (idx *Index) RetainBoards(ctx context.Context, boardIds []string) error {
+	var placeholders = strings.TrimSuffix(strings.Repeat("?,", len(boardIds)), ",")
+	var args = make([]any, 0, len(boardIds))
+	for _, id := range boardIds {
+		args = append(args, id)
+	}
+	var filter = "1 = 1"
+	if len(boardIds) > 0 {
+		filter = "board_id NOT IN (" + placeholders + ")"
+	}
+	tx, err := idx.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM items_fts WHERE id IN (SELECT id FROM items WHERE `+filter+`)`, args...); err != nil {
+		return fmt.Errorf("failed to drop stale boards: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM items WHERE `+filter, args...); err != nil {
+		return fmt.Errorf("failed to drop stale boards: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (idx *Index) SetLastSync(ctx context.Context, at time.Time) error {
+	_, err := idx.db.ExecContext(ctx, `INSERT INTO meta(key, value) VALUES('last_sync', ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, at.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (idx *Index) LastSync(ctx context.Context) (time.Time, error) {
+	var value string
+	err := idx.db.QueryRowContext(ctx, `SELECT value FROM meta WHERE key = 'last_sync'`).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, ErrNotSynced
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// Search looks value up in the name, email or phone of the indexed items,
+// depending on column, and returns the close matches by relevance.
+func (idx *Index) Search(ctx context.Context, column, value string, limit int) ([]monday.Item, error) {
+	var field = strings.ToLower(strings.TrimSpace(column))
+	switch field {
+	case "name", "email", "phone":
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedColumn, column)
+	}
+	if _, err := idx.LastSync(ctx); err != nil {
+		return nil, err
+	}
+	var needle = strings.ToLower(strings.TrimSpace(value))
+	if field == "phone" {
+		needle = digits(needle)
+	}
+	if needle == "" {
+		return nil, nil
+	}
+
+	var rows *sql.Rows
+	var err error
+	if trigrams := trigrams(needle); len(trigrams) > 0 {
+		rows, err = idx.db.QueryContext(ctx, `SELECT items.doc FROM items_fts JOIN items ON items.id = items_fts.id
+			WHERE items_fts MATCH ? ORDER BY rank LIMIT ?`, field+" : ("+strings.Join(trigrams, " OR ")+")", maxCandidates)
+	} else {
+		// too short for trigrams, the trigram tokenizer still speeds up LIKE
+		rows, err = idx.db.QueryContext(ctx, `SELECT items.doc FROM items_fts JOIN items ON items.id = items_fts.id
+			WHERE items_fts.`+field+` LIKE ? LIMIT ?`, "%"+needle+"%", maxCandidates)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search index: %w", err)
+	}
+	defer rows.Close()
+	var candidates = []monday.Item{}
+	for rows.Next() {
+		var doc string
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var item = monday.Item{}
+		if err := json.Unmarshal([]byte(doc), &item); err != nil {
+			return nil, fmt.Errorf("failed to decode indexed item: %w", err)
+		}
+		candidates = append(candidates, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var items = []monday.Item{}
+	for _, result := range rank.Rank(value, candidates, rank.SORT_RELEVANCE) {
+		if result.Match == rank.MATCH_NONE {
+			break
+		}
+		items = append(items, result.Item)
+		if limit > 0 && len(items) == limit {
+			break
+		}
+	}
+	return items, nil
+}
+
+func upsert(ctx context.Context, tx *sql.Tx, item monday.Item) error {
+	var id = fmt.Sprint(item.Id)
+	doc, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode item %s: %w", id, err)
+	}
+	if err := remove(ctx, tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO items(id, board_id, doc) VALUES(?, ?, ?)`, id, fmt.Sprint(item.Board.Id), string(doc)); err != nil {
+		return fmt.Errorf("failed to index item %s: %w", id, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO items_fts(id, name, email, phone) VALUES(?, ?, ?, ?)`,
+		id, strings.ToLower(string(item.Name)), strings.ToLower(item.Email()), digits(item.Phone())); err != nil {
+		return fmt.Errorf("failed to index item %s: %w", id, err)
+	}
+	return nil
+}
+
+func remove(ctx context.Context, tx *sql.Tx, id string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM items_fts WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to remove item %s: %w", id, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM items WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to remove item %s: %w", id, err)
+	}
+	return nil
+}
+
+// trigrams splits s in quoted FTS5 trigram phrases.
+func trigrams(s string) []string {
+	var runes = []rune(s)
+	var seen = map[string]bool{}
+	var out = []string{}
+	for i := 0; i+3 <= len(runes); i++ {
+		var t = string(runes[i : i+3])
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, `"`+strings.ReplaceAll(t, `"`, `""`)+`"`)
+	}
+	return out
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,209 @@
+package index
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+)
+
+// Syncer keeps an Index up to date with monday, through periodic full pulls
+// and the item events monday posts to its webhook handler in between.
+type Syncer struct {
+	client *monday.ApiClient
+	index  *Index
+	// signingSecret verifies the webhooks received, which are all refused
+	// without it.
+	signingSecret []byte
+}
+
+func NewSyncer(client *monday.ApiClient, index *Index) *Syncer {
+	return &Syncer{client: client, index: index}
+}
+
+// UseSigningSecret has the webhooks verified with the signing secret of the
+// monday app posting them.
+func (s *Syncer) UseSigningSecret(secret []byte) {
+	s.signingSecret = secret
+}
+
+// FullSync re-indexes every item of every board in the contacts workspaces.
+func (s *Syncer) FullSync(ctx context.Context) error {
+	var start = time.Now()
+	boards, err := s.client.ListContactBoards(ctx)
+	if err != nil {
+		return fmt.Errorf("could not list all boards: %w", err)
+	}
+	var boardIds = make([]string, 0, len(boards))
+	var total = 0
+	for _, board := range boards {
+		var items = []monday.Item{}
+		err := s.client.ForEachItem(ctx, board.Id, nil, func(page []monday.Item) error {
+			items = append(items, page...)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to pull board %s: %w", board.Name, err)
+		}
+		var boardId = fmt.Sprint(board.Id)
+		if err := s.index.ReplaceBoard(ctx, boardId, items); err != nil {
+			return err
+		}
+		boardIds = append(boardIds, boardId)
+		total += len(items)
+	}
+	if err := s.index.RetainBoards(ctx, boardIds); err != nil {
+		return err
+	}
+	if err := s.index.SetLastSync(ctx, start); err != nil {
+		return fmt.Errorf("failed to record sync: %w", err)
+	}
+	log.Printf("Indexed %d items from %d boards in %s", total, len(boards), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// Run does a full sync right away and then every interval, until ctx is done.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	var ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.FullSync(ctx); err != nil {
+			log.Println(fmt.Errorf("failed to sync index: %w", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type webhookEvent struct {
+	Type    string      `json:"type"`
+	BoardId json.Number `json:"boardId"`
+	PulseId json.Number `json:"pulseId"`
+}
+
+type webhookPayload struct {
+	Challenge string        `json:"challenge,omitempty"`
+	Event     *webhookEvent `json:"event,omitempty"`
+}
+
+// ServeHTTP receives monday webhooks. It answers the challenge sent when the
+// webhook is registered and applies item events to the index, once the JWT
+// monday signs them with is verified.
+func (s *Syncer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := verifyJWT(r.Header.Get("Authorization"), s.signingSecret, time.Now()); err != nil {
+		slog.Debug(fmt.Errorf("refused webhook: %w", err).Error())
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var payload = webhookPayload{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if payload.Challenge != "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(webhookPayload{Challenge: payload.Challenge})
+		return
+	}
+	if payload.Event == nil || payload.Event.PulseId == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err := s.apply(r.Context(), *payload.Event); err != nil {
+		// the next full sync catches up, monday retries on errors anyway
+		slog.Debug(fmt.Errorf("failed to apply webhook event: %w", err).Error(), "type", payload.Event.Type)
+		http.Error(w, "failed to apply event", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Syncer) apply(ctx context.Context, event webhookEvent) error {
+	var id = event.PulseId.String()
+	switch event.Type {
+	case "delete_pulse", "archive_pulse":
+		return s.index.Delete(ctx, id)
+	}
+	items, err := s.client.GetItemsByIds(ctx, id)
+	if err != nil {
+		return err
+	}
+	boards, err := s.client.ListContactBoards(ctx)
+	if err != nil {
+		return fmt.Errorf("could not list all boards: %w", err)
+	}
+	// an item moved out of the contacts workspaces leaves the index too
+	items = slices.DeleteFunc(items, func(item monday.Item) bool {
+		return !slices.ContainsFunc(boards, func(board monday.BoardListing) bool { return board.Id == item.Board.Id })
+	})
+	if len(items) == 0 {
+		return s.index.Delete(ctx, id)
+	}
+	return s.index.Upsert(ctx, items...)
+}
+
+// verifyJWT checks that token, with or without its Bearer prefix, is an
+// HS256 JWT signed with secret that has not expired at now.
+func verifyJWT(token string, secret []byte, now time.Time) error {
+	if len(secret) == 0 {
+		return errors.New("no signing secret")
+	}
+	var parts = strings.Split(strings.TrimSpace(strings.TrimPrefix(token, "Bearer ")), ".")
+	if len(parts) != 3 {
+		return errors.New("authorization is not a JWT")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return fmt.Errorf("invalid JWT header: %w", err)
+	}
+	if header.Alg != "HS256" {
+		return fmt.Errorf("JWT signed with %q, not HS256", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("invalid JWT signature: %w", err)
+	}
+	var mac = hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return errors.New("JWT signature does not match")
+	}
+	var claims struct {
+		Exp *int64 `json:"exp"`
+	}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return fmt.Errorf("invalid JWT claims: %w", err)
+	}
+	if claims.Exp != nil && !now.Before(time.Unix(*claims.Exp, 0)) {
+		return errors.New("JWT expired")
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	content, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
@@ -0,0 +1,113 @@
+package index
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday/mondaytest"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/secret"
+)
+
+var signingSecret = []byte("signing secret")
+
+var clients = mondaytest.Board{Id: "10", Name: "Clients", Columns: []mondaytest.Column{{Id: "name", Title: "Name", Type: "name"}}}
+
+// sign is an HS256 JWT of claims signed with secret, as monday signs its
+// webhooks.
+func sign(secret []byte, claims string) string {
+	var encode = base64.RawURLEncoding.EncodeToString
+	var unsigned = encode([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + encode([]byte(claims))
+	var mac = hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + encode(mac.Sum(nil))
+}
+
+// newSyncer is a Syncer of an empty index, synced, and of a fake holding
+// fake's boards.
+func newSyncer(t *testing.T, fake *mondaytest.Server) (*Syncer, *Index) {
+	idx, err := Open(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { idx.Close() })
+	if err := idx.SetLastSync(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	var syncer = NewSyncer(monday.New(fake.URL(), secret.Static("token", "test")), idx)
+	syncer.UseSigningSecret(signingSecret)
+	return syncer, idx
+}
+
+// post posts the event of item to syncer, authorized with token, and
+// returns the status answered.
+func post(syncer *Syncer, id, token string) int {
+	var r = httptest.NewRequest(http.MethodPost, "/webhooks/monday", strings.NewReader(fmt.Sprintf(`{"event":{"type":"create_pulse","boardId":10,"pulseId":%s}}`, id)))
+	if token != "" {
+		r.Header.Set("Authorization", token)
+	}
+	var w = httptest.NewRecorder()
+	syncer.ServeHTTP(w, r)
+	return w.Code
+}
+
+func indexed(t *testing.T, idx *Index, name string) int {
+	found, err := idx.Search(context.Background(), "name", name, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(found)
+}
+
+func TestWebhookAuthorization(t *testing.T) {
+	var fake = mondaytest.New(t, clients)
+	var id = fake.AddItem(mondaytest.Item{Name: "Ann Lee", Board: "10"})
+	var syncer, idx = newSyncer(t, fake)
+	var expiry = time.Now().Add(time.Minute).Unix()
+	for _, tc := range []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"not a JWT", "Bearer forged", http.StatusUnauthorized},
+		{"other secret", sign([]byte("forged"), fmt.Sprintf(`{"exp":%d}`, expiry)), http.StatusUnauthorized},
+		{"expired", sign(signingSecret, fmt.Sprintf(`{"exp":%d}`, time.Now().Add(-time.Minute).Unix())), http.StatusUnauthorized},
+		{"unsigned", strings.Join(strings.Split(sign(signingSecret, "{}"), ".")[:2], ".") + ".", http.StatusUnauthorized},
+	} {
+		if got := post(syncer, id, tc.token); got != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, got, tc.want)
+		}
+	}
+	if n := indexed(t, idx, "Ann Lee"); n != 0 {
+		t.Fatalf("refused webhooks indexed %d items", n)
+	}
+	if got := post(syncer, id, "Bearer "+sign(signingSecret, fmt.Sprintf(`{"exp":%d}`, expiry))); got != http.StatusOK {
+		t.Fatalf("signed webhook: status %d, want %d", got, http.StatusOK)
+	}
+	if n := indexed(t, idx, "Ann Lee"); n != 1 {
+		t.Fatalf("signed webhook indexed %d items, want 1", n)
+	}
+}
+
+func TestWebhookForeignBoard(t *testing.T) {
+	var fake = mondaytest.New(t, clients)
+	// board 99 is not in the contacts workspace
+	var id = fake.AddItem(mondaytest.Item{Name: "Ann Lee", Board: "99"})
+	var syncer, idx = newSyncer(t, fake)
+	if got := post(syncer, id, sign(signingSecret, "{}")); got != http.StatusOK {
+		t.Fatalf("status %d, want %d", got, http.StatusOK)
+	}
+	if n := indexed(t, idx, "Ann Lee"); n != 0 {
+		t.Fatalf("item of a foreign board indexed %d times", n)
+	}
+}
@@ -0,0 +1,311 @@
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	_ "modernc.org/sqlite"
+)
+
+// ErrEmpty is returned by searches before any board was mirrored.
+var ErrEmpty = errors.New("mirror holds no boards yet")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS boards (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	schema TEXT NOT NULL,
+	last_sync TEXT NOT NULL DEFAULT '',
+	last_full_sync TEXT NOT NULL DEFAULT '',
+	high_water TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS items (
+	id TEXT PRIMARY KEY,
+	board_id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	updated_at TEXT NOT NULL,
+	doc TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS items_board ON items(board_id);
+CREATE TABLE IF NOT EXISTS item_values (
+	item_id TEXT NOT NULL,
+	column_id TEXT NOT NULL,
+	text TEXT NOT NULL,
+	PRIMARY KEY (item_id, column_id)
+);`
+
+// Mirror is a local copy of the contact boards (their columns, groups and
+// items) that searches can be served from while monday is unreachable.
+type Mirror struct {
+	db *sql.DB
+}
+
+// BoardState is what the mirror knows about the freshness of a board.
+type BoardState struct {
+	Id           string
+	Name         string
+	LastSync     time.Time
+	LastFullSync time.Time
+	// HighWater is the newest updated_at of the mirrored items.
+	HighWater time.Time
+}
+
+func Open(path string) (*Mirror, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mirror %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create mirror schema: %w", err)
+	}
+	return &Mirror{db: db}, nil
+}
+
+func (m *Mirror) Close() error {
+	return m.db.Close()
+}
+
+// SaveBoard stores the structure of a board, keeping its sync state.
+func (m *Mirror) SaveBoard(ctx context.Context, board monday.BoardDescription) error {
+	doc, err := json.Marshal(board)
+	if err != nil {
+		return fmt.Errorf("failed to encode board %s: %w", board.Name, err)
+	}
+	_, err = m.db.ExecContext(ctx, `INSERT INTO boards(id, name, schema) VALUES(?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name, schema = excluded.schema`,
+		board.Id, board.Name, string(doc))
+	if err != nil {
+		return fmt.Errorf("failed to mirror board %s: %w", board.Name, err)
+	}
+	return nil
+}
+
+func (m *Mirror) Board(ctx context.Context, id string) (BoardState, error) {
+	var state = BoardState{Id: id}
+	var lastSync, lastFull, highWater string
+	err := m.db.QueryRowContext(ctx, `SELECT name, last_sync, last_full_sync, high_water FROM boards WHERE id = ?`, id).
+		Scan(&state.Name, &lastSync, &lastFull, &highWater)
+	if errors.Is(err, sql.ErrNoRows) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	state.LastSync = parseTime(lastSync)
+	state.LastFullSync = parseTime(lastFull)
+	state.HighWater = parseTime(highWater)
+	return state, nil
+}
+
+// Boards returns the structure of every mirrored board.
+func (m *Mirror) Boards(ctx context.Context) ([]monday.BoardDescription, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT schema FROM boards ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var boards = []monday.BoardDescription{}
+	for rows.Next() {
+		var doc string
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var board = monday.BoardDescription{}
+		if err := json.Unmarshal([]byte(doc), &board); err != nil {
+			return nil, fmt.Errorf("failed to decode mirrored board: %w", err)
+		}
+		boards = append(boards, board)
+	}
+	return boards, rows.Err()
+}
+
+// ApplyItems upserts items of a board. A full pull also drops the items of
+// the board it did not see, which is how deletions reach the mirror.
+func (m *Mirror) ApplyItems(ctx context.Context, boardId string, items []monday.Item, full bool, syncedAt time.Time) error {
+	state, err := m.Board(ctx, boardId)
+	if err != nil {
+		return err
+	}
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if full {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM item_values WHERE item_id IN (SELECT id FROM items WHERE board_id = ?)`, boardId); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM items WHERE board_id = ?`, boardId); err != nil {
+			return err
+		}
+		state.HighWater = time.Time{}
+	}
+	for _, item := range items {
+		if err := upsert(ctx, tx, boardId, item); err != nil {
+			return err
+		}
+		if updated := parseTime(string(item.UpdatedAt)); updated.After(state.HighWater) {
+			state.HighWater = updated
+		}
+	}
+	var fullSync = formatTime(state.LastFullSync)
+	if full {
+		fullSync = formatTime(syncedAt)
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE boards SET last_sync = ?, last_full_sync = ?, high_water = ? WHERE id = ?`,
+		formatTime(syncedAt), fullSync, formatTime(state.HighWater), boardId)
+	if err != nil {
+		return fmt.Errorf("failed to record sync of board %s: %w", boardId, err)
+	}
+	return tx.Commit()
+}
+
+// RetainBoards forgets every board, and its items, not in boardIds.
+func (m *Mirror) RetainBoards(ctx context.Context, boardIds []string) error {
+	var args = make([]any, 0, len(boardIds))
+	for _, id := range boardIds {
+		args = append(args, id)
+	}
+	var filter = "1 = 1"
+	if len(boardIds) > 0 {
+		filter = "board_id NOT IN (" + strings.TrimSuffix(strings.Repeat("?,", len(boardIds)), ",") + ")"
+	}
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range []string{
+		`DELETE FROM item_values WHERE item_id IN (SELECT id FROM items WHERE ` + filter + `)`,
+		`DELETE FROM items WHERE ` + filter,
+		`DELETE FROM boards WHERE ` + strings.Replace(filter, "board_id", "id", 1),
+	} {
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return fmt.Errorf("failed to drop stale boards: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Search finds the items whose column titled column contains value, the way
+// monday's contains_text does. It also returns when the oldest of the
+// searched boards was last synced, i.e. how stale the answer may be.
+func (m *Mirror) Search(ctx context.Context, column, value string, limit int) ([]monday.Item, time.Time, error) {
+	boards, err := m.Boards(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if len(boards) == 0 {
+		return nil, time.Time{}, ErrEmpty
+	}
+	var syncedAt time.Time
+	var items = []monday.Item{}
+	var needle = "%" + strings.ToLower(value) + "%"
+	for _, board := range boards {
+		state, err := m.Board(ctx, board.Id)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if state.LastSync.IsZero() {
+			continue
+		}
+		if syncedAt.IsZero() || state.LastSync.Before(syncedAt) {
+			syncedAt = state.LastSync
+		}
+		for _, col := range board.Columns {
+			if !strings.EqualFold(col.Title, column) {
+				continue
+			}
+			var rows *sql.Rows
+			if col.Type == "name" {
+				rows, err = m.db.QueryContext(ctx, `SELECT doc FROM items WHERE board_id = ? AND lower(name) LIKE ? ORDER BY id`, board.Id, needle)
+			} else {
+				rows, err = m.db.QueryContext(ctx, `SELECT doc FROM items JOIN item_values ON item_values.item_id = items.id
+					WHERE items.board_id = ? AND item_values.column_id = ? AND lower(item_values.text) LIKE ? ORDER BY items.id`, board.Id, col.Id, needle)
+			}
+			if err != nil {
+				return nil, time.Time{}, fmt.Errorf("failed to search mirror: %w", err)
+			}
+			found, err := scanItems(rows)
+			if err != nil {
+				return nil, time.Time{}, err
+			}
+			items = append(items, found...)
+			if limit > 0 && len(items) >= limit {
+				return items[:limit], syncedAt, nil
+			}
+		}
+	}
+	if syncedAt.IsZero() {
+		return nil, time.Time{}, ErrEmpty
+	}
+	return items, syncedAt, nil
+}
+
+func scanItems(rows *sql.Rows) ([]monday.Item, error) {
+	defer rows.Close()
+	var items = []monday.Item{}
+	for rows.Next() {
+		var doc string
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var item = monday.Item{}
+		if err := json.Unmarshal([]byte(doc), &item); err != nil {
+			return nil, fmt.Errorf("failed to decode mirrored item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func upsert(ctx context.Context, tx *sql.Tx, boardId string, item monday.Item) error {
+	var id = fmt.Sprint(item.Id)
+	doc, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode item %s: %w", id, err)
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO items(id, board_id, name, updated_at, doc) VALUES(?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET board_id = excluded.board_id, name = excluded.name,
+		updated_at = excluded.updated_at, doc = excluded.doc`,
+		id, boardId, string(item.Name), string(item.UpdatedAt), string(doc))
+	if err != nil {
+		return fmt.Errorf("failed to mirror item %s: %w", id, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM item_values WHERE item_id = ?`, id); err != nil {
+		return err
+	}
+	for _, c := range item.ColumnValues {
+		if c.Text == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO item_values(item_id, column_id, text) VALUES(?, ?, ?)`,
+			id, fmt.Sprint(c.Id), string(c.Text)); err != nil {
+			return fmt.Errorf("failed to mirror item %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,138 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+)
+
+// FULL_SYNC_EVERY is how often a board is pulled entirely instead of only
+// its recently updated items; full pulls are the only way to notice deletions.
+const FULL_SYNC_EVERY = 24 * time.Hour
+
+// Syncer copies the configured boards of the contacts workspace into a
+// Mirror, pulling only the items updated since the previous pull.
+type Syncer struct {
+	client *monday.ApiClient
+	mirror *Mirror
+	boards []string
+}
+
+// NewSyncer mirrors the boards with the given names, or every board of the
+// workspace when boards is empty.
+func NewSyncer(client *monday.ApiClient, mirror *Mirror, boards []string) *Syncer {
+	return &Syncer{client: client, mirror: mirror, boards: boards}
+}
+
+// Sync pulls every configured board, going on past the ones that fail, and
+// drops the mirrored boards no longer listed.
+func (s *Syncer) Sync(ctx context.Context) error {
+	descriptions, err := s.client.DescribeBoards(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list boards: %w", err)
+	}
+	// a board failing to sync is still listed, its last copy is kept
+	var boardIds = []string{}
+	var errs = []error{}
+	for _, board := range descriptions {
+		if !s.wants(board.Name) {
+			continue
+		}
+		boardIds = append(boardIds, board.Id)
+		if err := s.syncBoard(ctx, board); err != nil {
+			errs = append(errs, fmt.Errorf("failed to mirror board %s: %w", board.Name, err))
+		}
+	}
+	for _, name := range s.boards {
+		if !containsBoard(descriptions, name) {
+			log.Printf("Board %s is configured for mirroring but does not exist", name)
+		}
+	}
+	if err := s.mirror.RetainBoards(ctx, boardIds); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Run syncs right away and then every interval, until ctx is done.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	var ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Sync(ctx); err != nil {
+			log.Println(fmt.Errorf("failed to sync mirror: %w", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Syncer) syncBoard(ctx context.Context, board monday.BoardDescription) error {
+	var start = time.Now()
+	state, err := s.mirror.Board(ctx, board.Id)
+	if err != nil {
+		return err
+	}
+	if err := s.mirror.SaveBoard(ctx, board); err != nil {
+		return err
+	}
+	var full = state.LastFullSync.IsZero() || state.HighWater.IsZero() || start.Sub(state.LastFullSync) > FULL_SYNC_EVERY
+	var params *monday.ItemsQuery
+	if !full {
+		// newest first, so paging can stop at the first item already mirrored
+		params = &monday.ItemsQuery{}
+		params.AddOrderBy(monday.LAST_UPDATED_COLUMN, monday.ORDER_DESC)
+	}
+	var items = []monday.Item{}
+	err = s.client.ForEachItem(ctx, board.Id, params, func(page []monday.Item) error {
+		for _, item := range page {
+			// items updated in the same second as the high water mark are pulled again
+			if !full && parseTime(string(item.UpdatedAt)).Before(state.HighWater) {
+				return monday.ErrStopPaging
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.mirror.ApplyItems(ctx, board.Id, items, full, start); err != nil {
+		return err
+	}
+	var kind = "incremental"
+	if full {
+		kind = "full"
+	}
+	log.Printf("Mirrored %d items of board %s (%s pull)", len(items), board.Name, kind)
+	return nil
+}
+
+func (s *Syncer) wants(name string) bool {
+	if len(s.boards) == 0 {
+		return true
+	}
+	for _, b := range s.boards {
+		if strings.EqualFold(b, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsBoard(boards []monday.BoardDescription, name string) bool {
+	for _, b := range boards {
+		if strings.EqualFold(b.Name, name) {
+			return true
+		}
+	}
+	return false
+}
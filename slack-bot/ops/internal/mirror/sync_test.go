@@ -0,0 +1,60 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday/mondaytest"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/secret"
+)
+
+var columns = []mondaytest.Column{{Id: "name", Title: "Name", Type: "name"}}
+
+func mirrored(t *testing.T, m *Mirror, name string) int {
+	found, _, err := m.Search(context.Background(), "name", name, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(found)
+}
+
+func TestSyncGoesOnPastFailedBoards(t *testing.T) {
+	var fake = mondaytest.New(t,
+		mondaytest.Board{Id: "10", Name: "Clients", Columns: columns},
+		mondaytest.Board{Id: "20", Name: "Suppliers", Columns: columns})
+	fake.AddItem(mondaytest.Item{Name: "Ann Client", Board: "10"})
+	m, err := Open(filepath.Join(t.TempDir(), "mirror.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	var syncer = NewSyncer(monday.New(fake.URL(), secret.Static("token", "test")), m, nil)
+	if err := syncer.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the items of Clients fail to be pulled, the ones of Suppliers still are
+	fake.Hook = func(r mondaytest.Request) error {
+		if strings.Contains(r.Query, "items_page") && fmt.Sprint(r.Variables["ids"]) == "10" {
+			return errors.New("Rate limit exceeded")
+		}
+		return nil
+	}
+	fake.AddItem(mondaytest.Item{Name: "Bob Supplier", Board: "20"})
+	err = syncer.Sync(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Clients") {
+		t.Fatalf("Sync() = %v, want the Clients board failing", err)
+	}
+	if n := mirrored(t, m, "Bob Supplier"); n != 1 {
+		t.Errorf("Suppliers mirrored %d items after Clients failed, want 1", n)
+	}
+	// the last copy of the failed board is kept
+	if n := mirrored(t, m, "Ann Client"); n != 1 {
+		t.Errorf("Clients kept %d items after failing, want 1", n)
+	}
+}
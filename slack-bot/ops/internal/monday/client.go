@@ -3,56 +3,88 @@ package monday
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/secret"
 	"github.com/shurcooL/graphql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 )
 
+var (
+	ErrBoardNotFound = errors.New("board not found")
+	ErrItemNotFound  = errors.New("item not found")
+)
+
+const (
+	DEFAULT_SEARCH_CONCURRENCY = 4
+	DEFAULT_PAGE_SIZE          = 100
+	MAX_PAGE_SIZE              = 500
+)
+
 type ApiClient struct {
-	token  string
+	tokens secret.Source
 	client *graphql.Client
 	url    string
+	mu     sync.RWMutex
+	// current are the settings calls follow, listed the workspaces and
+	// boards last listed, if still cached.
+	current Settings
+	listed  *listing
 }
 
-func New(url, token string) *ApiClient {
-	src := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	httpClient := oauth2.NewClient(context.Background(), src)
-	gqlClient := graphql.NewClient(url, httpClient)
-	return &ApiClient{
-		token:  token,
-		client: gqlClient,
-		url:    url,
+// New calls monday at url with the token of tokens, asked for on every
+// request.
+func New(url string, tokens secret.Source) *ApiClient {
+	var api = &ApiClient{
+		tokens:  tokens,
+		url:     url,
+		current: DefaultSettings(),
 	}
+	// dry runs build their mutations without sending them, nor asking for a
+	// token
+	httpClient := &http.Client{Transport: captureTransport{
+		base: &oauth2.Transport{Source: tokens, Base: versionTransport{api: api, base: http.DefaultTransport}},
+	}}
+	api.client = graphql.NewClient(url, httpClient)
+	return api
 }
 
-func (api *ApiClient) GetContactsWorkspace(ctx context.Context) (*WorkspaceListing, error) {
-	var workspaceQuery = WorkpacesQuery{}
-	select {
-	case <-ctx.Done():
-		log.Println("Context closed")
-		return nil, fmt.Errorf("context closed")
-	default:
-	}
-	if err := api.client.Query(ctx, &workspaceQuery, nil); err != nil {
+// SetSearchConcurrency bounds how many boards GetItemsInAllBoards queries at once.
+func (api *ApiClient) SetSearchConcurrency(n int) {
+	var settings = api.Settings()
+	settings.SearchConcurrency = n
+	api.Configure(settings)
+}
+
+// GetAccount returns the monday account the token of the client belongs to.
+func (api *ApiClient) GetAccount(ctx context.Context) (*AccountListing, error) {
+	var query = MeQuery{}
+	if err := api.query(ctx, "GetAccount", &query, nil); err != nil {
 		return nil, fmt.Errorf("failed to query: %w", err)
 	}
-	var contactsWs *WorkspaceListing
-	for _, ws := range workspaceQuery.Workspaces {
-		if strings.Contains(string(ws.Name), "Contacts Management") {
-			contactsWs = &ws
-		}
-	}
-	if contactsWs == nil {
-		return nil, fmt.Errorf("could not find 'Contacts Management' workspace")
+	return &query.Me.Account, nil
+}
+
+// GetContactsWorkspace returns the first of the contacts workspaces, the one
+// boards are provisioned in.
+func (api *ApiClient) GetContactsWorkspace(ctx context.Context) (*WorkspaceListing, error) {
+	listed, err := api.list(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return contactsWs, nil
+	return &listed.workspaces[0], nil
 }
 
 func (api *ApiClient) ListBoards(ctx context.Context, ws *WorkspaceListing) ([]BoardListing, error) {
@@ -60,24 +92,20 @@ func (api *ApiClient) ListBoards(ctx context.Context, ws *WorkspaceListing) ([]B
 	select {
 	case <-ctx.Done():
 		log.Println("Context closed")
-		return nil, fmt.Errorf("context closed")
+		return nil, fmt.Errorf("context closed: %w", ctx.Err())
 	default:
 	}
 	var variables = map[string]any{
 		"wsId": ws.Id,
 	}
-	if err := api.client.Query(ctx, &simpleBoardsQuery, variables); err != nil {
+	if err := api.query(ctx, "ListBoards", &simpleBoardsQuery, variables); err != nil {
 		return nil, fmt.Errorf("failed to query: %w", err)
 	}
 	return simpleBoardsQuery.Boards, nil
 }
 
 func (api *ApiClient) FindBoardByName(ctx context.Context, name string) (*BoardListing, error) {
-	ws, err := api.GetContactsWorkspace(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find workspace %w", err)
-	}
-	boards, err := api.ListBoards(ctx, ws)
+	boards, err := api.ListContactBoards(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("could not list all boards: %w", err)
 	}
@@ -87,7 +115,42 @@ func (api *ApiClient) FindBoardByName(ctx context.Context, name string) (*BoardL
 			return &board, nil
 		}
 	}
-	return nil, fmt.Errorf("board not found")
+	return nil, ErrBoardNotFound
+}
+
+// DescribeBoards returns the structure of every board in the contacts
+// workspaces, or only of the board called name when it is not empty.
+func (api *ApiClient) DescribeBoards(ctx context.Context, name string) ([]BoardDescription, error) {
+	listed, err := api.list(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find workspace %w", err)
+	}
+	var boards = []BoardSchema{}
+	for _, ws := range listed.workspaces {
+		var query = BoardSchemaQuery{}
+		var variables = map[string]any{
+			"wsId": ws.Id,
+		}
+		if err := api.query(ctx, "DescribeBoards", &query, variables); err != nil {
+			return nil, fmt.Errorf("failed to query: %w", err)
+		}
+		boards = append(boards, query.Boards...)
+	}
+	var descriptions = []BoardDescription{}
+	for _, board := range boards {
+		if name != "" && !strings.EqualFold(string(board.Name), name) {
+			continue
+		}
+		desc, err := board.Describe()
+		if err != nil {
+			return nil, err
+		}
+		descriptions = append(descriptions, desc)
+	}
+	if name != "" && len(descriptions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBoardNotFound, name)
+	}
+	return descriptions, nil
 }
 
 func (api *ApiClient) GetBoardWithGroups(ctx context.Context, id string) (*BoardWithGroups, error) {
@@ -95,7 +158,7 @@ func (api *ApiClient) GetBoardWithGroups(ctx context.Context, id string) (*Board
 	var variables = map[string]any{
 		"ids": id,
 	}
-	if err := api.client.Query(ctx, &byIdQuery, variables); err != nil {
+	if err := api.query(ctx, "GetBoardWithGroups", &byIdQuery, variables); err != nil {
 		return nil, fmt.Errorf("failed to query: %w", err)
 	}
 	if len(byIdQuery.Boards) == 0 {
@@ -111,105 +174,352 @@ func (api *ApiClient) GetBoardItemsFiltered(ctx context.Context, boardId graphql
 		"queryParams": params,
 		"ids":         boardId,
 	}
-	if err := api.client.Query(ctx, &query, variables); err != nil {
+	if err := api.query(ctx, "GetBoardItemsFiltered", &query, variables); err != nil {
 		return nil, fmt.Errorf("failed to query: %w", err)
 	}
 	return query.Boards[0].ItemsPage.Items, nil
 
 }
 
-func (api *ApiClient) GetItemsInAllBoards(ctx context.Context, params ItemsQuery) (chan Item, error) {
+// GetGroupItems returns the first limit items of the group with groupId on
+// the board with boardId.
+func (api *ApiClient) GetGroupItems(ctx context.Context, boardId, groupId string, limit int) ([]Item, error) {
+	var query = GroupItemsQuery{}
+	var variables = map[string]any{
+		"limit":   graphql.Int(limit),
+		"groupId": graphql.String(groupId),
+		"ids":     graphql.ID(boardId),
+	}
+	if err := api.query(ctx, "GetGroupItems", &query, variables); err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	if len(query.Boards) == 0 || len(query.Boards[0].Groups) == 0 {
+		return nil, fmt.Errorf("no group %s on board %s could be found", groupId, boardId)
+	}
+	return query.Boards[0].Groups[0].ItemsPage.Items, nil
+}
+
+// GetItemUpdates returns the newest limit updates posted on the item with
+// id, newest first.
+func (api *ApiClient) GetItemUpdates(ctx context.Context, id string, limit int) ([]Update, error) {
+	var query = ItemUpdatesQuery{}
+	var variables = map[string]any{
+		"limit": graphql.Int(limit),
+		"ids":   []graphql.ID{id},
+	}
+	if err := api.query(ctx, "GetItemUpdates", &query, variables); err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	if len(query.Items) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrItemNotFound, id)
+	}
+	return query.Items[0].Updates, nil
+}
+
+// ErrStopPaging can be returned by the callback of ForEachItem to stop
+// before the last page without failing.
+var ErrStopPaging = errors.New("stop paging")
+
+// ForEachItem pages through the items of a board matching params, or all of
+// them when params is nil, calling fn once per page.
+func (api *ApiClient) ForEachItem(ctx context.Context, boardId graphql.ID, params *ItemsQuery, fn func([]Item) error) error {
+	var page ItemsPage
+	if params == nil {
+		var first = BoardItemsPageQuery{}
+		var variables = map[string]any{
+			"limit": graphql.Int(MAX_PAGE_SIZE),
+			"ids":   boardId,
+		}
+		if err := api.query(ctx, "ForEachItem", &first, variables); err != nil {
+			return fmt.Errorf("failed to query: %w", err)
+		}
+		if len(first.Boards) == 0 {
+			return fmt.Errorf("no board with id %s could be found", boardId)
+		}
+		page = first.Boards[0].ItemsPage
+	} else {
+		var first = BoardByIdWithFilterItemsQuery{}
+		var variables = map[string]any{
+			"limit":       graphql.Int(MAX_PAGE_SIZE),
+			"queryParams": *params,
+			"ids":         boardId,
+		}
+		if err := api.query(ctx, "ForEachItem", &first, variables); err != nil {
+			return fmt.Errorf("failed to query: %w", err)
+		}
+		if len(first.Boards) == 0 {
+			return fmt.Errorf("no board with id %s could be found", boardId)
+		}
+		page = first.Boards[0].ItemsPage
+	}
+	for {
+		if err := fn(page.Items); err != nil {
+			if errors.Is(err, ErrStopPaging) {
+				return nil
+			}
+			return err
+		}
+		if page.Cursor == "" {
+			return nil
+		}
+		var next = NextItemsPageQuery{}
+		var variables = map[string]any{
+			"limit":  graphql.Int(MAX_PAGE_SIZE),
+			"cursor": page.Cursor,
+		}
+		if err := api.query(ctx, "ForEachItem", &next, variables); err != nil {
+			return fmt.Errorf("failed to query: %w", err)
+		}
+		page = next.NextItemsPage
+	}
+}
+
+func (api *ApiClient) GetItemsByIds(ctx context.Context, ids ...string) ([]Item, error) {
+	var query = ItemsByIdQuery{}
+	var itemIds = make([]graphql.ID, 0, len(ids))
+	for _, id := range ids {
+		itemIds = append(itemIds, id)
+	}
+	var variables = map[string]any{
+		"ids": itemIds,
+	}
+	if err := api.query(ctx, "GetItemsByIds", &query, variables); err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	return query.Items, nil
+}
+
+// Search is a search of every contact board, as started by
+// GetItemsInAllBoards.
+type Search struct {
+	// Items streams the items found, it is closed once the search is over.
+	Items chan Item
+	mu    sync.Mutex
+	errs  []error
+}
+
+// Err returns why boards could not be searched, once Items is closed: a
+// BoardError per board, joined. The items of those boards are missing.
+func (s *Search) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return errors.Join(s.errs...)
+}
+
+func (s *Search) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs = append(s.errs, err)
+}
+
+// BoardError is a board a search could not search, e.g. because monday
+// rate limited it.
+type BoardError struct {
+	Board BoardListing
+	Err   error
+}
+
+func (e *BoardError) Error() string {
+	return fmt.Sprintf("failed to search board %s: %s", e.Board.Name, e.Err)
+}
+
+func (e *BoardError) Unwrap() error {
+	return e.Err
+}
+
+// FailedBoards returns the boards of the BoardErrors err holds, e.g. the
+// Err of a Search.
+func FailedBoards(err error) []BoardListing {
+	var boards = []BoardListing{}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			boards = append(boards, FailedBoards(e)...)
+		}
+		return boards
+	}
+	var boardErr *BoardError
+	if errors.As(err, &boardErr) {
+		boards = append(boards, boardErr.Board)
+	}
+	return boards
+}
+
+// GetItemsInAllBoards searches every board of the contacts workspaces and
+// streams the matching items. At most SearchConcurrency boards are queried at
+// once. Items is closed once every board was searched, limit items were
+// sent (when limit > 0) or ctx is done; boards not yet queried by then are
+// skipped. The boards that failed to be searched are told by Err. Callers
+// that stop reading early must cancel ctx.
+func (api *ApiClient) GetItemsInAllBoards(ctx context.Context, params ItemsQuery, limit int) (*Search, error) {
+	return api.GetItemsInBoards(ctx, nil, params, limit)
+}
+
+// GetItemsInBoards is GetItemsInAllBoards for the boards called one of
+// names only, every board when names is empty. The limit applies to the
+// items of those boards.
+func (api *ApiClient) GetItemsInBoards(ctx context.Context, names []string, params ItemsQuery, limit int) (*Search, error) {
 	type Response struct {
+		Board BoardListing
 		Items []Item
 		Error error
 	}
-	ws, err := api.GetContactsWorkspace(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get `Contacts Management` workspace")
-	}
-	boards, err := api.ListBoards(ctx, ws)
+	// the span lasts until the last item is sent
+	ctx, span := tracer.Start(ctx, "monday GetItemsInAllBoards", trace.WithAttributes(attribute.Int("limit", limit)))
+	boards, err := api.ListContactBoards(ctx)
 	if err != nil {
+		span.RecordError(err)
+		span.End()
 		return nil, err
 	}
+	if len(names) > 0 {
+		boards = slices.DeleteFunc(slices.Clone(boards), func(board BoardListing) bool {
+			return !slices.ContainsFunc(names, func(name string) bool { return strings.EqualFold(name, string(board.Name)) })
+		})
+		if len(boards) == 0 {
+			span.End()
+			return nil, fmt.Errorf("%w: %s", ErrBoardNotFound, strings.Join(names, ", "))
+		}
+	}
+	span.SetAttributes(attribute.Int("boards", len(boards)))
+	var pageSize = DEFAULT_PAGE_SIZE
+	if limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
 
-	var listenChan = make(chan Response, len(boards))
+	ctx, cancel := context.WithCancel(ctx)
+	var boardsChan = make(chan BoardListing)
+	go func() {
+		defer close(boardsChan)
+		for _, board := range boards {
+			select {
+			case boardsChan <- board:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
+	var listenChan = make(chan Response)
 	var wg = sync.WaitGroup{}
 	log.Printf("Searching in %d boards", len(boards))
 
-	wg.Add(len(boards))
-	for _, board := range boards {
+	for range min(api.Settings().SearchConcurrency, len(boards)) {
+		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			// for each board, map the column_id to the column title
-			var sb = &strings.Builder{}
-			fmt.Fprintf(sb, "Available columns in board %s\n", board.Name)
-
-			var columnNameToColumn = map[string]*Column{}
-
-			for _, col := range board.Columns {
-				fmt.Fprintf(sb, "%s[%s], %s ", col.Title, col.Id, col)
-				columnNameToColumn[strings.ToLower(string(col.Title))] = &col
-			}
-			// fmt.Println(sb.String(), "\n")
-			var innerParams = &ItemsQuery{
-				Rules: []ItemsQueryRule{},
-			}
-			innerParams.SetOperator(params.Operator)
-			for _, rule := range params.Rules {
-				var strId = rule.ColumnId.(string)
-				column, ok := columnNameToColumn[strings.ToLower(strId)]
-				if !ok {
-					return
-				}
-				var colId = column.Id
-				var operator = rule.Operator
-				if column.Type == "status" {
-					operator = CONTAINS_TERMS
+			for board := range boardsChan {
+				items, err := api.searchBoard(ctx, board, params, pageSize)
+				if err == nil && len(items) == 0 {
+					continue
 				}
-				slog.Debug("Replacing name with id", "columnName", rule.ColumnId, "id", colId, "board", board.Name)
-				innerParams.AddRule(colId, rule.CompareValue, operator)
-			}
-			items, err := api.GetBoardItemsFiltered(ctx, board.Id, 100, *innerParams)
-			if err != nil {
-				listenChan <- Response{Items: nil, Error: err}
-			} else {
-				if len(items) > 0 {
-					slog.Debug(fmt.Sprintf("Found %d entries in Board: %s\n", len(items), board.Name))
-					listenChan <- Response{Items: items, Error: nil}
+				select {
+				case listenChan <- Response{Board: board, Items: items, Error: err}:
+				case <-ctx.Done():
+					return
 				}
 			}
 		}()
 	}
 	go func() { wg.Wait(); close(listenChan) }()
 
-	var itemsChan = make(chan Item, 1)
+	var search = &Search{Items: make(chan Item, 1)}
 	go func() {
-		defer close(itemsChan)
+		defer close(search.Items)
+		// stops the producers once we are done, whatever the reason
+		defer cancel()
+		var sent = 0
+		defer func() {
+			span.SetAttributes(attribute.Int("items", sent))
+			span.End()
+		}()
 		for resp := range listenChan {
-			if resp.Error != nil {
+			// the boards cut short by the end of the search did not fail
+			if resp.Error != nil && ctx.Err() == nil {
 				slog.Debug(fmt.Errorf("failed to query monday: %s", resp.Error).Error())
+				span.RecordError(resp.Error)
+				search.fail(&BoardError{Board: resp.Board, Err: resp.Error})
 			}
 			for _, i := range resp.Items {
-				itemsChan <- i
+				select {
+				case search.Items <- i:
+				case <-ctx.Done():
+					return
+				}
+				sent++
+				if limit > 0 && sent >= limit {
+					slog.Debug("Search limit reached", "limit", limit)
+					return
+				}
 			}
 		}
 	}()
-	return itemsChan, nil
+	return search, nil
+}
+
+// searchBoard resolves the column titles used in params to the column ids of
+// board and returns its matching items. A board missing one of the columns
+// has no matches.
+func (api *ApiClient) searchBoard(ctx context.Context, board BoardListing, params ItemsQuery, pageSize int) (items []Item, err error) {
+	ctx, span := tracer.Start(ctx, "monday search board", trace.WithAttributes(
+		attribute.String("board.id", fmt.Sprint(board.Id)), attribute.String("board.name", string(board.Name))))
+	defer func() {
+		span.SetAttributes(attribute.Int("items", len(items)))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	// for each board, map the column_id to the column title
+	var columnNameToColumn = map[string]*Column{}
+	for _, col := range board.Columns {
+		columnNameToColumn[strings.ToLower(string(col.Title))] = &col
+	}
+	var innerParams = &ItemsQuery{
+		Rules: []ItemsQueryRule{},
+	}
+	innerParams.SetOperator(params.Operator)
+	for _, rule := range params.Rules {
+		var strId = rule.ColumnId.(string)
+		column, ok := columnNameToColumn[strings.ToLower(strId)]
+		if !ok {
+			slog.Debug("Column not found, use `describe` to list the available ones", "column", strId, "board", board.Name)
+			return nil, nil
+		}
+		var colId = column.Id
+		var operator = rule.Operator
+		if ColumnType(column.Type) == COLUMN_TYPE_STATUS {
+			operator = CONTAINS_TERMS
+		}
+		slog.Debug("Replacing name with id", "columnName", rule.ColumnId, "id", colId, "board", board.Name)
+		innerParams.AddRule(colId, rule.CompareValue, operator)
+	}
+	items, err = api.GetBoardItemsFiltered(ctx, board.Id, pageSize, *innerParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		slog.Debug(fmt.Sprintf("Found %d entries in Board: %s\n", len(items), board.Name))
+	}
+	return items, nil
 }
 
-func (api *ApiClient) CreateItem(ctx context.Context, req CreateItemRequest) error {
+// CreateItem adds an item to the board and group of req and returns its id.
+func (api *ApiClient) CreateItem(ctx context.Context, req CreateItemRequest) (string, error) {
+	if req.IdempotencyKey != "" {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("ops.idempotency_key", req.IdempotencyKey))
+	}
 	board, err := api.FindBoardByName(ctx, req.BoardName)
 	if err != nil {
-		return err
+		return "", err
 	}
 	boardId, ok := board.Id.(string)
 	if !ok {
-		return fmt.Errorf("board id cannot be cast to string")
+		return "", fmt.Errorf("board id cannot be cast to string")
 	}
 	groupId, err := api.getGroupId(ctx, req.GroupName, boardId)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	var columnValuesParam = map[string]any{}
@@ -221,12 +531,20 @@ func (api *ApiClient) CreateItem(ctx context.Context, req CreateItemRequest) err
 			columnValuesParam[col.Id.(string)] = NewPhoneColumnValue(req.Phone)
 		}
 	}
+	for key, value := range req.Columns {
+		col, err := board.column(key)
+		if err != nil {
+			return "", err
+		}
+		columnValuesParam[col.Id.(string)] = NewColumnValue(ColumnType(col.Type), value)
+	}
 
 	encodedCols, err := json.Marshal(columnValuesParam)
 	if err != nil {
-		return fmt.Errorf("failed to encode param values: %w", err)
+		return "", fmt.Errorf("failed to encode param values: %w", err)
 	}
-	slog.Debug(string(encodedCols))
+	// values are contact details, only the columns set are logged
+	slog.Debug("Encoded column values", "board", board.Name, "columns", slices.Sorted(maps.Keys(columnValuesParam)))
 
 	var mutateRequest = CreateItemMutation{}
 	var variables = map[string]any{
@@ -235,7 +553,173 @@ func (api *ApiClient) CreateItem(ctx context.Context, req CreateItemRequest) err
 		"itemName": graphql.String(req.Name),
 		"cols":     JSON(encodedCols),
 	}
-	if err := api.client.Mutate(ctx, &mutateRequest, variables); err != nil {
+	if err := api.mutate(ctx, "CreateItem", &mutateRequest, variables); err != nil {
+		return "", fmt.Errorf("failed to mutate: %w", err)
+	}
+	if IsDryRun(ctx) {
+		return DRY_RUN_ID, nil
+	}
+	id, ok := mutateRequest.CreateItem.Id.(string)
+	if !ok {
+		return "", fmt.Errorf("item id cannot be cast to string")
+	}
+	return id, nil
+}
+
+// UpdateItem changes the name and column values of an existing item.
+func (api *ApiClient) UpdateItem(ctx context.Context, req UpdateItemRequest) error {
+	items, err := api.GetItemsByIds(ctx, req.ItemId)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("%w: %s", ErrItemNotFound, req.ItemId)
+	}
+	board, err := api.FindBoardByName(ctx, string(items[0].Board.Name))
+	if err != nil {
+		return err
+	}
+	var columnValuesParam = map[string]any{}
+	if req.Name != "" {
+		columnValuesParam["name"] = req.Name
+	}
+	for key, value := range req.Columns {
+		col, err := board.column(key)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			columnValuesParam[col.Id.(string)] = ""
+			continue
+		}
+		columnValuesParam[col.Id.(string)] = NewColumnValue(ColumnType(col.Type), value)
+	}
+	for key, value := range req.Values {
+		col, err := board.column(key)
+		if err != nil {
+			return err
+		}
+		if len(value) == 0 || string(value) == "null" {
+			columnValuesParam[col.Id.(string)] = ""
+			continue
+		}
+		columnValuesParam[col.Id.(string)] = value
+	}
+	if len(columnValuesParam) == 0 {
+		return nil
+	}
+	encodedCols, err := json.Marshal(columnValuesParam)
+	if err != nil {
+		return fmt.Errorf("failed to encode param values: %w", err)
+	}
+	// values are contact details, only the columns set are logged
+	slog.Debug("Encoded column values", "board", board.Name, "columns", slices.Sorted(maps.Keys(columnValuesParam)))
+
+	var mutateRequest = UpdateItemMutation{}
+	var variables = map[string]any{
+		"itemId":  graphql.ID(req.ItemId),
+		"boardId": board.Id,
+		"cols":    JSON(encodedCols),
+	}
+	if err := api.mutate(ctx, "UpdateItem", &mutateRequest, variables); err != nil {
+		return fmt.Errorf("failed to mutate: %w", err)
+	}
+	return nil
+}
+
+// Snapshot returns what req is about to change on its item: the name, when
+// req renames it, and the raw values of the columns req sets.
+func (api *ApiClient) Snapshot(ctx context.Context, req UpdateItemRequest) (ItemSnapshot, error) {
+	items, err := api.GetItemsByIds(ctx, req.ItemId)
+	if err != nil {
+		return ItemSnapshot{}, err
+	}
+	if len(items) == 0 {
+		return ItemSnapshot{}, fmt.Errorf("%w: %s", ErrItemNotFound, req.ItemId)
+	}
+	var item = items[0]
+	var snapshot = ItemSnapshot{ItemId: req.ItemId, Board: string(item.Board.Name), Values: map[string]json.RawMessage{}}
+	if req.Name != "" {
+		snapshot.Name = string(item.Name)
+	}
+	if len(req.Columns) == 0 && len(req.Values) == 0 {
+		return snapshot, nil
+	}
+	board, err := api.FindBoardByName(ctx, string(item.Board.Name))
+	if err != nil {
+		return ItemSnapshot{}, err
+	}
+	var values = map[string]json.RawMessage{}
+	for _, c := range item.ColumnValues {
+		if c.Value != "" {
+			values[fmt.Sprint(c.Id)] = json.RawMessage(c.Value)
+		}
+	}
+	for _, key := range slices.Concat(slices.Collect(maps.Keys(req.Columns)), slices.Collect(maps.Keys(req.Values))) {
+		col, err := board.column(key)
+		if err != nil {
+			return ItemSnapshot{}, err
+		}
+		var id = fmt.Sprint(col.Id)
+		if value, ok := values[id]; ok {
+			snapshot.Values[id] = value
+		} else {
+			snapshot.Values[id] = json.RawMessage("null")
+		}
+	}
+	return snapshot, nil
+}
+
+func (api *ApiClient) ArchiveItem(ctx context.Context, itemId string) error {
+	var mutateRequest = ArchiveItemMutation{}
+	var variables = map[string]any{
+		"itemId": graphql.ID(itemId),
+	}
+	if err := api.mutate(ctx, "ArchiveItem", &mutateRequest, variables); err != nil {
+		return fmt.Errorf("failed to mutate: %w", err)
+	}
+	return nil
+}
+
+// AddNote posts body as an update on the item and returns the update's id.
+func (api *ApiClient) AddNote(ctx context.Context, itemId, body string) (string, error) {
+	var mutateRequest = CreateUpdateMutation{}
+	var variables = map[string]any{
+		"itemId": graphql.ID(itemId),
+		"body":   graphql.String(body),
+	}
+	if err := api.mutate(ctx, "AddNote", &mutateRequest, variables); err != nil {
+		return "", fmt.Errorf("failed to mutate: %w", err)
+	}
+	if IsDryRun(ctx) {
+		return DRY_RUN_ID, nil
+	}
+	id, ok := mutateRequest.CreateUpdate.Id.(string)
+	if !ok {
+		return "", fmt.Errorf("update id cannot be cast to string")
+	}
+	return id, nil
+}
+
+// DeleteItem deletes the item for good, unlike ArchiveItem.
+func (api *ApiClient) DeleteItem(ctx context.Context, itemId string) error {
+	var mutateRequest = DeleteItemMutation{}
+	var variables = map[string]any{
+		"itemId": graphql.ID(itemId),
+	}
+	if err := api.mutate(ctx, "DeleteItem", &mutateRequest, variables); err != nil {
+		return fmt.Errorf("failed to mutate: %w", err)
+	}
+	return nil
+}
+
+// DeleteUpdate deletes an update posted on an item, e.g. by AddNote.
+func (api *ApiClient) DeleteUpdate(ctx context.Context, updateId string) error {
+	var mutateRequest = DeleteUpdateMutation{}
+	var variables = map[string]any{
+		"updateId": graphql.ID(updateId),
+	}
+	if err := api.mutate(ctx, "DeleteUpdate", &mutateRequest, variables); err != nil {
 		return fmt.Errorf("failed to mutate: %w", err)
 	}
 	return nil
@@ -263,3 +747,108 @@ func (api *ApiClient) getGroupId(ctx context.Context, groupName string, boardId
 	return "", fmt.Errorf("group %s not found in board %s", groupName, boardWithGroups.Name)
 
 }
+
+func (api *ApiClient) CreateBoard(ctx context.Context, req CreateBoardRequest) (string, error) {
+	if req.WorkspaceId == nil {
+		return "", fmt.Errorf("a workspace is required to create board %s", req.Name)
+	}
+	var kind = req.Kind
+	if kind == "" {
+		kind = BOARD_KIND_PUBLIC
+	}
+	var mutateRequest = CreateBoardMutation{}
+	var variables = map[string]any{
+		"boardName":   graphql.String(req.Name),
+		"boardKind":   kind,
+		"wsId":        req.WorkspaceId,
+		"description": graphql.String(req.Description),
+	}
+	if err := api.mutate(ctx, "CreateBoard", &mutateRequest, variables); err != nil {
+		return "", fmt.Errorf("failed to mutate: %w", err)
+	}
+	if IsDryRun(ctx) {
+		return DRY_RUN_ID, nil
+	}
+	api.forgetBoards()
+	id, ok := mutateRequest.CreateBoard.Id.(string)
+	if !ok {
+		return "", fmt.Errorf("board id cannot be cast to string")
+	}
+	return id, nil
+}
+
+func (api *ApiClient) CreateGroup(ctx context.Context, req CreateGroupRequest) (string, error) {
+	var mutateRequest = CreateGroupMutation{}
+	var variables = map[string]any{
+		"boardId":   req.BoardId,
+		"groupName": graphql.String(req.Name),
+	}
+	if err := api.mutate(ctx, "CreateGroup", &mutateRequest, variables); err != nil {
+		return "", fmt.Errorf("failed to mutate: %w", err)
+	}
+	if IsDryRun(ctx) {
+		return DRY_RUN_ID, nil
+	}
+	id, ok := mutateRequest.CreateGroup.Id.(string)
+	if !ok {
+		return "", fmt.Errorf("group id cannot be cast to string")
+	}
+	return id, nil
+}
+
+func (api *ApiClient) CreateColumn(ctx context.Context, req CreateColumnRequest) (string, error) {
+	defaults, err := columnDefaults(req.Type, req.Labels)
+	if err != nil {
+		return "", err
+	}
+	var mutateRequest = CreateColumnMutation{}
+	var variables = map[string]any{
+		"boardId":    req.BoardId,
+		"title":      graphql.String(req.Title),
+		"columnType": req.Type,
+		"defaults":   defaults,
+	}
+	if err := api.mutate(ctx, "CreateColumn", &mutateRequest, variables); err != nil {
+		return "", fmt.Errorf("failed to mutate: %w", err)
+	}
+	if IsDryRun(ctx) {
+		return DRY_RUN_ID, nil
+	}
+	api.forgetBoards()
+	id, ok := mutateRequest.CreateColumn.Id.(string)
+	if !ok {
+		return "", fmt.Errorf("column id cannot be cast to string")
+	}
+	return id, nil
+}
+
+// columnDefaults encodes the labels of a status or dropdown column in the
+// shape monday expects for the `defaults` argument of create_column.
+func columnDefaults(colType ColumnType, labels []string) (*JSON, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	var defaults = map[string]any{}
+	switch colType {
+	case COLUMN_TYPE_STATUS:
+		var byIndex = map[string]string{}
+		for i, l := range labels {
+			byIndex[strconv.Itoa(i)] = l
+		}
+		defaults["labels"] = byIndex
+	case COLUMN_TYPE_DROPDOWN:
+		var options = make([]DropdownOption, 0, len(labels))
+		for i, l := range labels {
+			options = append(options, DropdownOption{Id: i + 1, Name: l})
+		}
+		defaults["settings"] = map[string]any{"labels": options}
+	default:
+		return nil, fmt.Errorf("labels are not supported for columns of type %s", colType)
+	}
+	encoded, err := json.Marshal(defaults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode column defaults: %w", err)
+	}
+	var value = JSON(encoded)
+	return &value, nil
+}
@@ -0,0 +1,83 @@
+package monday_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday/mondaytest"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/secret"
+)
+
+var contactColumns = []mondaytest.Column{
+	{Id: "name", Title: "Name", Type: "name"},
+	{Id: "email", Title: "Email", Type: "email"},
+	{Id: "phone", Title: "Phone", Type: "phone"},
+}
+
+func newClient(fake *mondaytest.Server) *monday.ApiClient {
+	return monday.New(fake.URL(), secret.Static("token", "test"))
+}
+
+func TestGetItemsInAllBoardsReportsFailedBoards(t *testing.T) {
+	var fake = mondaytest.New(t,
+		mondaytest.Board{Id: "10", Name: "Clients", Columns: contactColumns},
+		mondaytest.Board{Id: "20", Name: "Suppliers", Columns: contactColumns},
+	)
+	fake.AddItem(mondaytest.Item{Name: "Ann Client", Board: "10"})
+	fake.AddItem(mondaytest.Item{Name: "Ann Supplier", Board: "20"})
+	fake.Hook = func(r mondaytest.Request) error {
+		if strings.Contains(r.Query, "items_page") && r.Variables["ids"] == "20" {
+			return errors.New("Rate limit exceeded")
+		}
+		return nil
+	}
+	var params = monday.ItemsQuery{Operator: "and"}
+	params.AddRule("Name", "ann", monday.CONTAINS_TEXT)
+
+	search, err := newClient(fake).GetItemsInAllBoards(context.Background(), params, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names = []string{}
+	for item := range search.Items {
+		names = append(names, string(item.Name))
+	}
+	if fmt.Sprint(names) != "[Ann Client]" {
+		t.Errorf("found %v, want the item of the board that answered", names)
+	}
+	err = search.Err()
+	if !errors.Is(err, monday.ErrRateLimited) {
+		t.Fatalf("Err() = %v, want a rate limit", err)
+	}
+	var failed = monday.FailedBoards(err)
+	if len(failed) != 1 || failed[0].Name != "Suppliers" {
+		t.Errorf("FailedBoards() = %v, want Suppliers", failed)
+	}
+}
+
+func TestGetItemsInAllBoardsWithoutFailures(t *testing.T) {
+	var fake = mondaytest.New(t, mondaytest.Board{Id: "10", Name: "Clients", Columns: contactColumns})
+	fake.AddItem(mondaytest.Item{Name: "Ann", Board: "10"})
+	fake.AddItem(mondaytest.Item{Name: "Bob", Board: "10"})
+	var params = monday.ItemsQuery{Operator: "and"}
+	params.AddRule("Name", "ann", monday.CONTAINS_TEXT)
+
+	search, err := newClient(fake).GetItemsInAllBoards(context.Background(), params, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found = 0
+	for range search.Items {
+		found++
+	}
+	if found != 1 {
+		t.Errorf("found %d items, want 1", found)
+	}
+	if err := search.Err(); err != nil {
+		t.Errorf("Err() = %v, want none", err)
+	}
+}
@@ -0,0 +1,127 @@
+package monday
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DRY_RUN_ID is the id returned for what a dry run would have created, e.g.
+// a board, for the mutations that follow to refer to it.
+const DRY_RUN_ID = "dry-run"
+
+// Mutation is a GraphQL mutation as it would have been sent to monday.
+type Mutation struct {
+	Operation string          `json:"operation"`
+	Query     string          `json:"query"`
+	Variables json.RawMessage `json:"variables"`
+}
+
+func (m Mutation) String() string {
+	var variables = bytes.Buffer{}
+	if err := json.Indent(&variables, m.Variables, "", "  "); err != nil {
+		variables.Write(m.Variables)
+	}
+	return fmt.Sprintf("# %s\n%s\n%s", m.Operation, m.Query, variables.String())
+}
+
+// DryRun holds the mutations the calls made with its context did not send.
+type DryRun struct {
+	mu        sync.Mutex
+	mutations []Mutation
+}
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context whose calls resolve the boards, groups and
+// columns they need as usual but record their mutations in the returned
+// DryRun rather than sending them.
+func WithDryRun(ctx context.Context) (context.Context, *DryRun) {
+	var dryRun = &DryRun{}
+	return context.WithValue(ctx, dryRunKey{}, dryRun), dryRun
+}
+
+// IsDryRun tells whether the mutations of the calls made with ctx are only
+// recorded.
+func IsDryRun(ctx context.Context) bool {
+	return dryRunFrom(ctx) != nil
+}
+
+func dryRunFrom(ctx context.Context) *DryRun {
+	dryRun, _ := ctx.Value(dryRunKey{}).(*DryRun)
+	return dryRun
+}
+
+// Mutations returns the mutations recorded, in the order they were made.
+func (d *DryRun) Mutations() []Mutation {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Mutation{}, d.mutations...)
+}
+
+// Last returns the last mutation recorded, nil when there is none.
+func (d *DryRun) Last() *Mutation {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.mutations) == 0 {
+		return nil
+	}
+	var last = d.mutations[len(d.mutations)-1]
+	return &last
+}
+
+// record builds the request mutating with m would send, through the
+// transport of the client, and keeps it in dryRun. m is left as monday
+// would have answered nothing.
+func (api *ApiClient) record(ctx context.Context, dryRun *DryRun, operation string, m any, variables map[string]any) error {
+	var body []byte
+	var query, _, _ = withComplexity(m)
+	if err := api.client.Mutate(context.WithValue(ctx, captureKey{}, &body), query, variables); err != nil {
+		return fmt.Errorf("failed to build mutation: %w", err)
+	}
+	var request = struct {
+		Query     string          `json:"query"`
+		Variables json.RawMessage `json:"variables"`
+	}{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return fmt.Errorf("failed to decode mutation: %w", err)
+	}
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	dryRun.mutations = append(dryRun.mutations, Mutation{Operation: operation, Query: request.Query, Variables: request.Variables})
+	return nil
+}
+
+// captureKey carries where the body of a request is kept rather than sent.
+type captureKey struct{}
+
+// captureTransport keeps the body of the requests made with a captureKey
+// and answers them with no data, without sending them.
+type captureTransport struct {
+	base http.RoundTripper
+}
+
+func (t captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, ok := req.Context().Value(captureKey{}).(*[]byte)
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	content, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = content
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"data":null}`)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package monday
+
+import (
+	"reflect"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shurcooL/graphql"
+)
+
+var (
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "monday_api_request_duration_seconds",
+		Help:    "Duration of the GraphQL requests sent to monday, by ApiClient operation.",
+		Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"operation"})
+	requestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "monday_api_errors_total",
+		Help: "GraphQL requests to monday that failed, by ApiClient operation and kind of error.",
+	}, []string{"operation", "error"})
+	complexityRemaining = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "monday_api_complexity_remaining",
+		Help: "Complexity budget left for the current minute, as of the last request.",
+	})
+	complexityReset = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "monday_api_complexity_reset_seconds",
+		Help: "Seconds until the complexity budget is reset, as of the last request.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(requestDuration, requestErrors, complexityRemaining, complexityReset)
+}
+
+// Complexity is asked for along with every query and mutation, it tells how
+// much of the complexity budget is left.
+type Complexity struct {
+	After           graphql.Int `graphql:"after"`
+	ResetInXSeconds graphql.Int `graphql:"reset_in_x_seconds"`
+}
+
+// withComplexity returns a query or mutation asking for everything v asks
+// for and for the complexity budget. copyBack copies the answer into v. v is
+// returned as is when it is not a pointer to a plain struct.
+func withComplexity(v any) (query any, complexity *Complexity, copyBack func()) {
+	var value = reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return v, nil, func() {}
+	}
+	var original = value.Elem()
+	var fields = []reflect.StructField{}
+	for i := 0; i < original.NumField(); i++ {
+		var field = original.Type().Field(i)
+		if field.Anonymous || !field.IsExported() {
+			return v, nil, func() {}
+		}
+		fields = append(fields, field)
+	}
+	fields = append(fields, reflect.StructField{
+		Name: "Complexity",
+		Type: reflect.TypeFor[Complexity](),
+		Tag:  `graphql:"complexity"`,
+	})
+	var wrapped = reflect.New(reflect.StructOf(fields))
+	for i := 0; i < original.NumField(); i++ {
+		wrapped.Elem().Field(i).Set(original.Field(i))
+	}
+	complexity = wrapped.Elem().Field(original.NumField()).Addr().Interface().(*Complexity)
+	return wrapped.Interface(), complexity, func() {
+		for i := 0; i < original.NumField(); i++ {
+			original.Field(i).Set(wrapped.Elem().Field(i))
+		}
+	}
+}
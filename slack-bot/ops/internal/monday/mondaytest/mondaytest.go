@@ -0,0 +1,657 @@
+// Package mondaytest is a fake of the monday GraphQL API for the tests of
+// the code calling it: one contacts workspace whose boards and items are
+// kept in memory and changed by the mutations sent.
+//
+// Queries are answered by the name of their top fields, with only the fields
+// they select, the way the GraphQL client of the monday package expects.
+package mondaytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// WORKSPACE is the name of the workspace of the boards.
+const WORKSPACE = "Contacts Management"
+
+type Column struct {
+	Id    string
+	Title string
+	Type  string
+	// Settings is the settings_str of the column, e.g. its labels.
+	Settings string
+}
+
+type Group struct {
+	Id    string
+	Title string
+}
+
+type Board struct {
+	Id      string
+	Name    string
+	Columns []Column
+	Groups  []Group
+}
+
+// Value is the value of a column of an item: its text and its value as
+// monday encodes it, null when the column is empty.
+type Value struct {
+	Text  string
+	Value string
+}
+
+type Update struct {
+	Id   string
+	Body string
+}
+
+type Item struct {
+	Id    string
+	Name  string
+	Board string
+	Group string
+	// Values are keyed by column id.
+	Values   map[string]Value
+	Updates  []Update
+	Archived bool
+}
+
+// Request is a GraphQL request the server was sent.
+type Request struct {
+	Query     string
+	Variables map[string]any
+}
+
+// Server answers the requests of a monday client made with its URL.
+type Server struct {
+	*httptest.Server
+	// Hook is called with every request before it is answered. The request
+	// fails with the error it returns, as monday reports GraphQL errors.
+	Hook func(Request) error
+
+	mu       sync.Mutex
+	boards   []Board
+	items    map[string]*Item
+	requests []Request
+	lastId   int
+}
+
+// New starts a server holding boards, closed at the end of the test.
+func New(t testing.TB, boards ...Board) *Server {
+	var s = &Server{boards: boards, items: map[string]*Item{}, lastId: 1000}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// URL is the GraphQL endpoint, the url of a monday client.
+func (s *Server) URL() string {
+	return s.Server.URL + "/v2"
+}
+
+// AddItem keeps item on its board, giving it an id when it has none, and
+// returns the id.
+func (s *Server) AddItem(item Item) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if item.Id == "" {
+		item.Id = s.nextId()
+	}
+	item = item.clone()
+	if item.Values == nil {
+		item.Values = map[string]Value{}
+	}
+	s.items[item.Id] = &item
+	return item.Id
+}
+
+// clone copies item, for the fake and the tests to not share its values.
+func (item Item) clone() Item {
+	item.Values = maps.Clone(item.Values)
+	item.Updates = slices.Clone(item.Updates)
+	return item
+}
+
+// Item returns the item with id, false once deleted.
+func (s *Server) Item(id string) (Item, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[id]
+	if !ok {
+		return Item{}, false
+	}
+	return item.clone(), true
+}
+
+// Items returns the items not deleted, archived or not.
+func (s *Server) Items() []Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items = make([]Item, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item.clone())
+	}
+	slices.SortFunc(items, func(a, b Item) int { return strings.Compare(a.Id, b.Id) })
+	return items
+}
+
+// Requests returns the requests whose query calls field, e.g. create_item,
+// or every request when field is empty.
+func (s *Server) Requests(field string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var found = []Request{}
+	for _, r := range s.requests {
+		if field == "" || strings.Contains(r.Query, field+"(") {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+func (s *Server) nextId() string {
+	s.lastId++
+	return strconv.Itoa(s.lastId)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	var request = struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req = Request{Query: request.Query, Variables: request.Variables}
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if s.Hook != nil {
+		if err := s.Hook(req); err != nil {
+			json.NewEncoder(w).Encode(map[string]any{"errors": []map[string]any{{"message": err.Error()}}})
+			return
+		}
+	}
+	fields, err := parse(req.Query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var data = map[string]any{}
+	for _, f := range fields {
+		value, err := s.resolve(f, req.Variables)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]any{"errors": []map[string]any{{"message": err.Error()}}})
+			return
+		}
+		data[f.name] = project(value, f.fields, req.Variables)
+	}
+	json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+// resolve answers the top field f of a request.
+func (s *Server) resolve(f *field, variables map[string]any) (any, error) {
+	var args = f.arguments(variables)
+	switch f.name {
+	case "complexity":
+		return map[string]any{"after": 1000000, "reset_in_x_seconds": 60}, nil
+	case "me":
+		return map[string]any{"account": map[string]any{"id": "1", "name": "Fake", "slug": "fake"}}, nil
+	case "workspaces":
+		return []any{map[string]any{"id": "1", "name": WORKSPACE, "kind": "open"}}, nil
+	case "boards":
+		var ids = idsOf(args["ids"])
+		var boards = []any{}
+		for _, b := range s.boards {
+			if _, all := args["workspace_ids"]; all || slices.Contains(ids, b.Id) {
+				boards = append(boards, s.board(b))
+			}
+		}
+		return boards, nil
+	case "items":
+		var items = []any{}
+		for _, id := range idsOf(args["ids"]) {
+			if item, ok := s.items[id]; ok {
+				items = append(items, s.item(item))
+			}
+		}
+		return items, nil
+	case "next_items_page":
+		return map[string]any{"cursor": "", "items": []any{}}, nil
+	case "create_item":
+		var item = &Item{
+			Id:     s.nextId(),
+			Name:   fmt.Sprint(args["item_name"]),
+			Board:  fmt.Sprint(args["board_id"]),
+			Group:  fmt.Sprint(args["group_id"]),
+			Values: map[string]Value{},
+		}
+		if err := s.setValues(item, args["column_values"]); err != nil {
+			return nil, err
+		}
+		s.items[item.Id] = item
+		return map[string]any{"id": item.Id}, nil
+	case "change_multiple_column_values":
+		item, ok := s.items[fmt.Sprint(args["item_id"])]
+		if !ok {
+			return nil, fmt.Errorf("item %v not found", args["item_id"])
+		}
+		if err := s.setValues(item, args["column_values"]); err != nil {
+			return nil, err
+		}
+		return map[string]any{"id": item.Id}, nil
+	case "archive_item":
+		item, ok := s.items[fmt.Sprint(args["item_id"])]
+		if !ok {
+			return nil, fmt.Errorf("item %v not found", args["item_id"])
+		}
+		item.Archived = true
+		return map[string]any{"id": item.Id}, nil
+	case "delete_item":
+		var id = fmt.Sprint(args["item_id"])
+		if _, ok := s.items[id]; !ok {
+			return nil, fmt.Errorf("item %s not found", id)
+		}
+		delete(s.items, id)
+		return map[string]any{"id": id}, nil
+	case "create_update":
+		item, ok := s.items[fmt.Sprint(args["item_id"])]
+		if !ok {
+			return nil, fmt.Errorf("item %v not found", args["item_id"])
+		}
+		var update = Update{Id: s.nextId(), Body: fmt.Sprint(args["body"])}
+		item.Updates = append([]Update{update}, item.Updates...)
+		return map[string]any{"id": update.Id}, nil
+	case "delete_update":
+		var id = fmt.Sprint(args["id"])
+		for _, item := range s.items {
+			item.Updates = slices.DeleteFunc(item.Updates, func(u Update) bool { return u.Id == id })
+		}
+		return map[string]any{"id": id}, nil
+	}
+	return nil, fmt.Errorf("field %s is not faked", f.name)
+}
+
+// setValues sets the column values of a mutation, encoded as JSON, on item.
+func (s *Server) setValues(item *Item, encoded any) error {
+	var values = map[string]any{}
+	if encoded != nil {
+		if err := json.Unmarshal([]byte(fmt.Sprint(encoded)), &values); err != nil {
+			return fmt.Errorf("invalid column values: %w", err)
+		}
+	}
+	var board = s.boardOf(item)
+	for id, value := range values {
+		if id == "name" {
+			item.Name = fmt.Sprint(value)
+			continue
+		}
+		var idx = slices.IndexFunc(board.Columns, func(c Column) bool { return c.Id == id })
+		if idx < 0 {
+			return fmt.Errorf("column %s not found on board %s", id, board.Name)
+		}
+		if value == "" || value == nil {
+			delete(item.Values, id)
+			continue
+		}
+		raw, _ := json.Marshal(value)
+		item.Values[id] = Value{Text: text(board.Columns[idx], value), Value: string(raw)}
+	}
+	return nil
+}
+
+// text is the text monday shows for value in column.
+func text(column Column, value any) string {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	for _, key := range []string{"email", "phone", "label", "date"} {
+		if v, ok := object[key]; ok {
+			return fmt.Sprint(v)
+		}
+	}
+	if url, ok := object["url"]; ok {
+		if object["text"] == nil || object["text"] == "" {
+			return fmt.Sprint(url)
+		}
+		return fmt.Sprintf("%v - %v", object["text"], url)
+	}
+	if v, ok := object["text"]; ok {
+		return fmt.Sprint(v)
+	}
+	var labels = []string{}
+	if names, ok := object["labels"].([]any); ok {
+		for _, name := range names {
+			labels = append(labels, fmt.Sprint(name))
+		}
+	}
+	if ids, ok := object["ids"].([]any); ok {
+		var options = struct {
+			Labels []struct {
+				Id   float64 `json:"id"`
+				Name string  `json:"name"`
+			} `json:"labels"`
+		}{}
+		json.Unmarshal([]byte(column.Settings), &options)
+		for _, id := range ids {
+			for _, o := range options.Labels {
+				if o.Id == id {
+					labels = append(labels, o.Name)
+				}
+			}
+		}
+	}
+	return strings.Join(labels, ", ")
+}
+
+func (s *Server) boardOf(item *Item) Board {
+	var idx = slices.IndexFunc(s.boards, func(b Board) bool { return b.Id == item.Board })
+	if idx < 0 {
+		return Board{Id: item.Board}
+	}
+	return s.boards[idx]
+}
+
+// board is b as monday answers it, the items of its pages resolved when
+// they are asked for.
+func (s *Server) board(b Board) map[string]any {
+	var columns = []any{}
+	for _, c := range b.Columns {
+		columns = append(columns, map[string]any{"id": c.Id, "title": c.Title, "type": c.Type, "settings_str": c.Settings})
+	}
+	var groups = []any{}
+	for i, g := range b.Groups {
+		groups = append(groups, map[string]any{
+			"id": g.Id, "title": g.Title, "position": strconv.Itoa(i + 1),
+			"items_page": resolver(func(args map[string]any) any {
+				return s.page(args, func(item *Item) bool { return item.Board == b.Id && item.Group == g.Id })
+			}),
+		})
+	}
+	return map[string]any{
+		"id": b.Id, "name": b.Name, "description": "", "board_kind": "public",
+		"columns": columns,
+		"groups":  resolver(func(args map[string]any) any { return filterIds(groups, args["ids"]) }),
+		"items_page": resolver(func(args map[string]any) any {
+			return s.page(args, func(item *Item) bool { return item.Board == b.Id })
+		}),
+	}
+}
+
+// page is the first page of the items kept by keep and matching the
+// query_params of args, in the order of their ids.
+func (s *Server) page(args map[string]any, keep func(*Item) bool) map[string]any {
+	var query = struct {
+		Rules []struct {
+			ColumnId     string `json:"column_id"`
+			CompareValue any    `json:"compare_value"`
+			Operator     string `json:"operator"`
+		} `json:"rules"`
+		Operator string `json:"operator"`
+	}{}
+	if params, ok := args["query_params"]; ok {
+		encoded, _ := json.Marshal(params)
+		json.Unmarshal(encoded, &query)
+	}
+	var limit, _ = strconv.Atoi(fmt.Sprint(args["limit"]))
+	var items = []any{}
+	for _, item := range s.sorted() {
+		if !keep(item) || item.Archived {
+			continue
+		}
+		var matched = len(query.Rules) == 0 || query.Operator != "or"
+		for _, rule := range query.Rules {
+			var value = item.Values[rule.ColumnId].Text
+			if rule.ColumnId == "name" {
+				value = item.Name
+			}
+			var ok = strings.Contains(strings.ToLower(value), strings.ToLower(strings.Trim(fmt.Sprint(rule.CompareValue), "[]")))
+			if rule.Operator == "is_empty" {
+				ok = value == ""
+			}
+			if query.Operator == "or" {
+				matched = matched || ok
+			} else {
+				matched = matched && ok
+			}
+		}
+		if matched {
+			items = append(items, s.item(item))
+		}
+		if limit > 0 && len(items) == limit {
+			break
+		}
+	}
+	return map[string]any{"cursor": "", "items": items}
+}
+
+func (s *Server) sorted() []*Item {
+	var items = make([]*Item, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+	slices.SortFunc(items, func(a, b *Item) int {
+		x, _ := strconv.Atoi(a.Id)
+		y, _ := strconv.Atoi(b.Id)
+		return x - y
+	})
+	return items
+}
+
+// item is item as monday answers it.
+func (s *Server) item(item *Item) map[string]any {
+	var board = s.boardOf(item)
+	var group = map[string]any{"id": item.Group, "title": "", "position": ""}
+	for i, g := range board.Groups {
+		if g.Id == item.Group {
+			group = map[string]any{"id": g.Id, "title": g.Title, "position": strconv.Itoa(i + 1)}
+		}
+	}
+	var values = []any{}
+	for _, c := range board.Columns {
+		var value = item.Values[c.Id]
+		var raw any
+		if value.Value != "" {
+			raw = value.Value
+		}
+		var encoded = map[string]any{"id": c.Id, "text": value.Text, "value": raw}
+		switch c.Type {
+		case "email":
+			encoded["email"] = value.Text
+		case "phone":
+			encoded["phone"] = value.Text
+		}
+		values = append(values, encoded)
+	}
+	var updates = []any{}
+	for _, u := range item.Updates {
+		updates = append(updates, map[string]any{"id": u.Id, "text_body": u.Body, "created_at": "", "creator": map[string]any{"name": "Fake"}})
+	}
+	return map[string]any{
+		"id": item.Id, "name": item.Name, "updated_at": "", "url": "https://fake.monday.com/items/" + item.Id,
+		"board":         map[string]any{"id": board.Id, "name": board.Name},
+		"group":         group,
+		"column_values": values,
+		"updates":       resolver(func(map[string]any) any { return updates }),
+	}
+}
+
+func filterIds(objects []any, ids any) []any {
+	if ids == nil {
+		return objects
+	}
+	var wanted = idsOf(ids)
+	var kept = []any{}
+	for _, o := range objects {
+		if slices.Contains(wanted, fmt.Sprint(o.(map[string]any)["id"])) {
+			kept = append(kept, o)
+		}
+	}
+	return kept
+}
+
+// idsOf returns the ids of an argument holding one id or a list of them.
+func idsOf(arg any) []string {
+	return strings.Fields(strings.Trim(fmt.Sprint(arg), "[]"))
+}
+
+// resolver is a field whose value depends on its arguments.
+type resolver func(args map[string]any) any
+
+// project keeps of value only the fields selected, resolving the fields
+// with the arguments they are given.
+func project(value any, fields []*field, variables map[string]any) any {
+	if len(fields) == 0 {
+		return value
+	}
+	switch v := value.(type) {
+	case []any:
+		var projected = make([]any, 0, len(v))
+		for _, e := range v {
+			projected = append(projected, project(e, fields, variables))
+		}
+		return projected
+	case map[string]any:
+		var projected = map[string]any{}
+		for _, f := range fields {
+			if f.fragment {
+				for k, e := range project(v, f.fields, variables).(map[string]any) {
+					projected[k] = e
+				}
+				continue
+			}
+			e, ok := v[f.name]
+			if !ok {
+				continue
+			}
+			if r, ok := e.(resolver); ok {
+				e = r(f.arguments(variables))
+			}
+			projected[f.name] = project(e, f.fields, variables)
+		}
+		return projected
+	}
+	return value
+}
+
+// field is a field selected by a query, or an inline fragment.
+type field struct {
+	name     string
+	args     string
+	fields   []*field
+	fragment bool
+}
+
+var argument = regexp.MustCompile(`(\w+)\s*:\s*(\[[^\]]*\]|"(?:[^"\\]|\\.)*"|[$\w.-]+)`)
+
+// arguments returns the arguments of f, their variables replaced by their
+// value.
+func (f *field) arguments(variables map[string]any) map[string]any {
+	var args = map[string]any{}
+	for _, match := range argument.FindAllStringSubmatch(f.args, -1) {
+		var raw = strings.Trim(match[2], "[]")
+		switch {
+		case strings.HasPrefix(raw, "$"):
+			args[match[1]] = variables[raw[1:]]
+		case strings.HasPrefix(raw, `"`):
+			args[match[1]], _ = strconv.Unquote(raw)
+		default:
+			args[match[1]] = raw
+		}
+	}
+	return args
+}
+
+// parse returns the top fields of query, ignoring its variable definitions.
+func parse(query string) ([]*field, error) {
+	var start = strings.Index(query, "{")
+	if start < 0 {
+		return nil, fmt.Errorf("no selection in %q", query)
+	}
+	var p = &parser{query: query, at: start}
+	return p.selection()
+}
+
+type parser struct {
+	query string
+	at    int
+}
+
+func (p *parser) skip() {
+	for p.at < len(p.query) && strings.ContainsRune(" \t\n,", rune(p.query[p.at])) {
+		p.at++
+	}
+}
+
+const nameChars = "_0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+func (p *parser) name() string {
+	var start = p.at
+	for p.at < len(p.query) && strings.ContainsRune(nameChars, rune(p.query[p.at])) {
+		p.at++
+	}
+	return p.query[start:p.at]
+}
+
+// selection parses the fields between braces, p being at the opening one.
+func (p *parser) selection() ([]*field, error) {
+	p.at++
+	var fields = []*field{}
+	for {
+		p.skip()
+		if p.at >= len(p.query) {
+			return nil, fmt.Errorf("unterminated selection in %q", p.query)
+		}
+		if p.query[p.at] == '}' {
+			p.at++
+			return fields, nil
+		}
+		var f = &field{}
+		if strings.HasPrefix(p.query[p.at:], "...") {
+			p.at += 3
+			p.skip()
+			p.name() // on
+			p.skip()
+			p.name() // the type
+			f.fragment = true
+		} else {
+			f.name = p.name()
+			if f.name == "" {
+				return nil, fmt.Errorf("unexpected %q in %q", p.query[p.at], p.query)
+			}
+		}
+		p.skip()
+		if p.at < len(p.query) && p.query[p.at] == '(' {
+			var end = strings.IndexByte(p.query[p.at:], ')')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated arguments in %q", p.query)
+			}
+			f.args = p.query[p.at+1 : p.at+end]
+			p.at += end + 1
+			p.skip()
+		}
+		if p.at < len(p.query) && p.query[p.at] == '{' {
+			children, err := p.selection()
+			if err != nil {
+				return nil, err
+			}
+			f.fields = children
+		}
+		fields = append(fields, f)
+	}
+}
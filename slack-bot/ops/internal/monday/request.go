@@ -0,0 +1,125 @@
+package monday
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// ErrRateLimited is returned when monday refuses a call because too many
+	// were made, ErrComplexityBudget when the complexity budget of the minute
+	// is spent.
+	ErrRateLimited      = errors.New("rate limited by monday")
+	ErrComplexityBudget = errors.New("monday complexity budget exhausted")
+	ErrUnauthorized     = errors.New("monday refused the token")
+	ErrServer           = errors.New("monday failed to answer")
+)
+
+var tracer = otel.Tracer("github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday")
+
+var statusCode = regexp.MustCompile(`status code: (\d{3})`)
+
+// query runs the GraphQL query q, recording it as operation.
+func (api *ApiClient) query(ctx context.Context, operation string, q any, variables map[string]any) error {
+	return api.do(ctx, "query", operation, q, func(ctx context.Context, v any) error {
+		return api.client.Query(ctx, v, variables)
+	})
+}
+
+// mutate runs the GraphQL mutation m, recording it as operation. On a dry
+// run it is kept in the DryRun of ctx instead.
+func (api *ApiClient) mutate(ctx context.Context, operation string, m any, variables map[string]any) error {
+	if dryRun := dryRunFrom(ctx); dryRun != nil {
+		return api.record(ctx, dryRun, operation, m, variables)
+	}
+	return api.do(ctx, "mutation", operation, m, func(ctx context.Context, v any) error {
+		return api.client.Mutate(ctx, v, variables)
+	})
+}
+
+// do sends a GraphQL request in its own span, timing it and keeping track of
+// the complexity budget it leaves.
+func (api *ApiClient) do(ctx context.Context, kind, operation string, v any, send func(ctx context.Context, v any) error) error {
+	ctx, span := tracer.Start(ctx, "monday "+operation, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("graphql.operation.type", kind), attribute.String("graphql.operation.name", operation)))
+	defer span.End()
+	var query, complexity, copyBack = withComplexity(v)
+	var start = time.Now()
+	err := send(ctx, query)
+	copyBack()
+	requestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if complexity != nil && complexity.After > 0 {
+		complexityRemaining.Set(float64(complexity.After))
+		complexityReset.Set(float64(complexity.ResetInXSeconds))
+		span.SetAttributes(attribute.Int("monday.complexity.remaining", int(complexity.After)))
+	}
+	if err == nil {
+		return nil
+	}
+	err = classify(ctx, err)
+	if errors.Is(err, ErrUnauthorized) {
+		// the token may have been rotated, the next request reads it again
+		api.tokens.Forget()
+	}
+	requestErrors.WithLabelValues(operation, errorKind(err)).Inc()
+	span.RecordError(err)
+	span.SetStatus(codes.Error, errorKind(err))
+	return err
+}
+
+// classify wraps err with the typed error matching what monday answered.
+func classify(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	var message = strings.ToLower(err.Error())
+	var code = 0
+	if match := statusCode.FindStringSubmatch(message); match != nil {
+		code, _ = strconv.Atoi(match[1])
+	}
+	switch {
+	case strings.Contains(message, "complexity"):
+		return fmt.Errorf("%w: %w", ErrComplexityBudget, err)
+	case code == 429 || strings.Contains(message, "rate limit"):
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	case code == 401 || code == 403:
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	case code >= 500:
+		return fmt.Errorf("%w: %w", ErrServer, err)
+	}
+	return err
+}
+
+// errorKind is the label err is counted under.
+func errorKind(err error) string {
+	var netErr interface{ Timeout() bool }
+	switch {
+	case errors.Is(err, ErrComplexityBudget):
+		return "complexity_budget"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrServer):
+		return "server"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &netErr):
+		return "network"
+	case statusCode.MatchString(err.Error()):
+		return "http"
+	}
+	return "graphql"
+}
@@ -0,0 +1,185 @@
+package monday
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_WORKSPACE = "Contacts Management"
+	// API_VERSION_HEADER pins the version of the monday API requests are
+	// answered with.
+	API_VERSION_HEADER = "API-Version"
+)
+
+// Settings are what the client can be told to do differently while in use.
+type Settings struct {
+	// APIVersion is sent along every request, e.g. 2024-10; monday answers
+	// with the account's default version when it is empty.
+	APIVersion string
+	// Workspaces are the workspaces holding the contact boards, each matched
+	// by a part of its name.
+	Workspaces []string
+	// DefaultBoard and DefaultGroup are where items are created when no board
+	// is given.
+	DefaultBoard string
+	DefaultGroup string
+	// SearchConcurrency bounds how many boards GetItemsInAllBoards queries at
+	// once.
+	SearchConcurrency int
+	// BoardsTTL is how long the workspaces and boards listed are reused, they
+	// are listed again for every call when 0.
+	BoardsTTL time.Duration
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Workspaces:        []string{DEFAULT_WORKSPACE},
+		SearchConcurrency: DEFAULT_SEARCH_CONCURRENCY,
+	}
+}
+
+// Configure makes the calls started from now on follow settings.
+func (api *ApiClient) Configure(settings Settings) {
+	if settings.SearchConcurrency < 1 {
+		settings.SearchConcurrency = 1
+	}
+	if len(settings.Workspaces) == 0 {
+		settings.Workspaces = []string{DEFAULT_WORKSPACE}
+	}
+	settings.Workspaces = slices.Clone(settings.Workspaces)
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if !slices.Equal(api.current.Workspaces, settings.Workspaces) || settings.BoardsTTL < api.current.BoardsTTL {
+		api.listed = nil
+	}
+	api.current = settings
+}
+
+// Settings returns the settings calls follow.
+func (api *ApiClient) Settings() Settings {
+	api.mu.RLock()
+	defer api.mu.RUnlock()
+	return api.current
+}
+
+// ResolveBoard returns board and group, or the default board when board is
+// empty, along with the default group when group is empty too.
+func (api *ApiClient) ResolveBoard(board, group string) (string, string) {
+	if board != "" {
+		return board, group
+	}
+	var settings = api.Settings()
+	if group == "" {
+		group = settings.DefaultGroup
+	}
+	return settings.DefaultBoard, group
+}
+
+// listing is the workspaces and boards listed at some point.
+type listing struct {
+	workspaces []WorkspaceListing
+	boards     []BoardListing
+	at         time.Time
+}
+
+// ListContactBoards returns the boards of every contacts workspace, listed
+// again once BoardsTTL has passed.
+func (api *ApiClient) ListContactBoards(ctx context.Context) ([]BoardListing, error) {
+	listed, err := api.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listed.boards, nil
+}
+
+// ListContactWorkspaces returns the contacts workspaces, in the order of
+// the Workspaces setting.
+func (api *ApiClient) ListContactWorkspaces(ctx context.Context) ([]WorkspaceListing, error) {
+	listed, err := api.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listed.workspaces, nil
+}
+
+func (api *ApiClient) list(ctx context.Context) (*listing, error) {
+	var settings = api.Settings()
+	api.mu.RLock()
+	var listed = api.listed
+	api.mu.RUnlock()
+	if listed != nil && time.Since(listed.at) < settings.BoardsTTL {
+		return listed, nil
+	}
+	workspaces, err := api.contactWorkspaces(ctx, settings.Workspaces)
+	if err != nil {
+		return nil, err
+	}
+	listed = &listing{workspaces: workspaces, at: time.Now()}
+	for _, ws := range workspaces {
+		boards, err := api.ListBoards(ctx, &ws)
+		if err != nil {
+			return nil, fmt.Errorf("could not list the boards of %s: %w", ws.Name, err)
+		}
+		listed.boards = append(listed.boards, boards...)
+	}
+	if settings.BoardsTTL > 0 {
+		api.mu.Lock()
+		api.listed = listed
+		api.mu.Unlock()
+	}
+	return listed, nil
+}
+
+// contactWorkspaces finds the workspace called after each of names, in the
+// order of names.
+func (api *ApiClient) contactWorkspaces(ctx context.Context, names []string) ([]WorkspaceListing, error) {
+	var workspaceQuery = WorkpacesQuery{}
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("context closed: %w", ctx.Err())
+	default:
+	}
+	if err := api.query(ctx, "GetContactsWorkspace", &workspaceQuery, nil); err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	var found = []WorkspaceListing{}
+	for _, name := range names {
+		var idx = slices.IndexFunc(workspaceQuery.Workspaces, func(ws WorkspaceListing) bool {
+			return strings.Contains(string(ws.Name), name)
+		})
+		if idx < 0 {
+			return nil, fmt.Errorf("could not find '%s' workspace", name)
+		}
+		found = append(found, workspaceQuery.Workspaces[idx])
+	}
+	return found, nil
+}
+
+// forgetBoards lists the boards again on the next call, after one was
+// created or changed.
+func (api *ApiClient) forgetBoards() {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.listed = nil
+}
+
+// versionTransport sends the API version of the client along every request.
+type versionTransport struct {
+	api  *ApiClient
+	base http.RoundTripper
+}
+
+func (t versionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var version = t.api.Settings().APIVersion
+	if version == "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(API_VERSION_HEADER, version)
+	return t.base.RoundTrip(req)
+}
@@ -1,12 +1,30 @@
 package monday
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/shurcooL/graphql"
 )
 
+// MeQuery asks for the account the token belongs to.
+type MeQuery struct {
+	Me struct {
+		Account AccountListing
+	}
+}
+
+type AccountListing struct {
+	Id   graphql.ID
+	Name graphql.String
+	Slug graphql.String
+}
+
 type WorkpacesQuery struct {
 	Workspaces []WorkspaceListing
 }
@@ -29,6 +47,17 @@ type BoardListing struct {
 	Columns     []Column
 }
 
+// column finds a column of the board by id or title.
+func (b *BoardListing) column(key string) (Column, error) {
+	var idx = slices.IndexFunc(b.Columns, func(col Column) bool {
+		return col.Id == key || strings.EqualFold(string(col.Title), key)
+	})
+	if idx < 0 {
+		return Column{}, fmt.Errorf("column %s not found in board %s", key, b.Name)
+	}
+	return b.Columns[idx], nil
+}
+
 type Group struct {
 	Id       graphql.ID
 	Title    graphql.String
@@ -55,6 +84,92 @@ type BoardWithGroupsByIdQuery struct {
 	Boards []BoardWithGroups `graphql:"boards(ids: [$ids])"`
 }
 
+// BoardSchema is a board together with everything needed to describe its
+// structure: columns (with their settings) and groups.
+type BoardSchema struct {
+	Id          graphql.ID
+	Name        graphql.String
+	Description graphql.String
+	BoardKind   graphql.String `graphql:"board_kind"`
+	Columns     []Column
+	Groups      []Group
+}
+
+type BoardSchemaQuery struct {
+	Boards []BoardSchema `graphql:"boards(workspace_ids: [$wsId])"`
+}
+
+type BoardDescription struct {
+	Id          string              `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Kind        string              `json:"kind"`
+	Columns     []ColumnDescription `json:"columns"`
+	Groups      []GroupDescription  `json:"groups"`
+}
+
+type ColumnDescription struct {
+	Id     string   `json:"id"`
+	Title  string   `json:"title"`
+	Type   string   `json:"type"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+type GroupDescription struct {
+	Id    string `json:"id"`
+	Title string `json:"title"`
+}
+
+func (b BoardSchema) Describe() (BoardDescription, error) {
+	var desc = BoardDescription{
+		Id:          fmt.Sprint(b.Id),
+		Name:        string(b.Name),
+		Description: string(b.Description),
+		Kind:        string(b.BoardKind),
+		Columns:     make([]ColumnDescription, 0, len(b.Columns)),
+		Groups:      make([]GroupDescription, 0, len(b.Groups)),
+	}
+	for _, col := range b.Columns {
+		labels, err := col.Labels()
+		if err != nil {
+			return desc, err
+		}
+		desc.Columns = append(desc.Columns, ColumnDescription{
+			Id:     fmt.Sprint(col.Id),
+			Title:  string(col.Title),
+			Type:   string(col.Type),
+			Labels: labels,
+		})
+	}
+	for _, g := range b.Groups {
+		desc.Groups = append(desc.Groups, GroupDescription{Id: fmt.Sprint(g.Id), Title: string(g.Title)})
+	}
+	return desc, nil
+}
+
+func (d BoardDescription) String() string {
+	var builder = &strings.Builder{}
+	fmt.Fprintf(builder, "Board %s ID %s [%s]\n", d.Name, d.Id, d.Kind)
+	if d.Description != "" {
+		fmt.Fprintf(builder, "  %s\n", d.Description)
+	}
+	var w = tabwriter.NewWriter(builder, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  Columns:")
+	for _, c := range d.Columns {
+		var labels = ""
+		if len(c.Labels) > 0 {
+			labels = "[" + strings.Join(c.Labels, ", ") + "]"
+		}
+		fmt.Fprintf(w, "    %s\t%s\t%s\t%s\n", c.Title, c.Id, c.Type, labels)
+	}
+	fmt.Fprintln(w, "  Groups:")
+	for _, g := range d.Groups {
+		fmt.Fprintf(w, "    %s\t%s\n", g.Title, g.Id)
+	}
+	w.Flush()
+	return builder.String()
+}
+
 type EmailColumnValue struct {
 	Email graphql.String `json:"email"`
 	Text  graphql.String `json:"text"`
@@ -73,6 +188,26 @@ func NewPhoneColumnValue(val string) PhoneColumnValue {
 	return PhoneColumnValue{Phone: graphql.String(val), Text: graphql.String(val)}
 }
 
+// NewColumnValue encodes value in the shape monday expects for columns of
+// type colType in the column_values of a mutation.
+func NewColumnValue(colType ColumnType, value string) any {
+	switch colType {
+	case COLUMN_TYPE_EMAIL:
+		return NewEmailColumnValue(value)
+	case COLUMN_TYPE_PHONE:
+		return NewPhoneColumnValue(value)
+	case COLUMN_TYPE_STATUS:
+		return map[string]string{"label": value}
+	case COLUMN_TYPE_DROPDOWN:
+		return map[string][]string{"labels": {value}}
+	case COLUMN_TYPE_LONG:
+		return map[string]string{"text": value}
+	case COLUMN_TYPE_DATE:
+		return map[string]string{"date": value}
+	}
+	return value
+}
+
 type TextColumnValue struct {
 	Text  graphql.String
 	Value graphql.String
@@ -87,34 +222,157 @@ type ColumnValue struct {
 }
 
 type Column struct {
-	Id    graphql.ID
-	Title graphql.String
-	Type  graphql.String
+	Id          graphql.ID
+	Title       graphql.String
+	Type        graphql.String
+	SettingsStr graphql.String `graphql:"settings_str"`
+}
+
+// ColumnSettings is the decoded form of a column's settings_str. Status columns
+// keep their labels as an index->label object, dropdowns as a list of options.
+type ColumnSettings struct {
+	Labels json.RawMessage `json:"labels"`
+}
+
+type DropdownOption struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (c Column) Settings() (ColumnSettings, error) {
+	var settings = ColumnSettings{}
+	if c.SettingsStr == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(c.SettingsStr), &settings); err != nil {
+		return settings, fmt.Errorf("failed to decode settings of column %s: %w", c.Id, err)
+	}
+	return settings, nil
+}
+
+// Labels returns the status labels or dropdown options of the column, ordered
+// by their index. Columns of any other type have no labels.
+func (c Column) Labels() ([]string, error) {
+	settings, err := c.Settings()
+	if err != nil {
+		return nil, err
+	}
+	if len(settings.Labels) == 0 {
+		return nil, nil
+	}
+	var options = []DropdownOption{}
+	if err := json.Unmarshal(settings.Labels, &options); err == nil {
+		sort.Slice(options, func(i, j int) bool { return options[i].Id < options[j].Id })
+		var labels = make([]string, 0, len(options))
+		for _, o := range options {
+			labels = append(labels, o.Name)
+		}
+		return labels, nil
+	}
+	var byIndex = map[string]string{}
+	if err := json.Unmarshal(settings.Labels, &byIndex); err != nil {
+		return nil, fmt.Errorf("failed to decode labels of column %s: %w", c.Id, err)
+	}
+	var keys = make([]int, 0, len(byIndex))
+	for k := range byIndex {
+		idx, err := strconv.Atoi(k)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, idx)
+	}
+	sort.Ints(keys)
+	var labels = make([]string, 0, len(keys))
+	for _, k := range keys {
+		// monday keeps an unnamed label around for the "empty" state
+		if l := byIndex[strconv.Itoa(k)]; l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels, nil
+}
+
+type BoardRef struct {
+	Id   graphql.ID
+	Name graphql.String
 }
 
 type Item struct {
 	Id           graphql.ID
 	Name         graphql.String
+	UpdatedAt    graphql.String `graphql:"updated_at"`
+	Url          graphql.String `graphql:"url"`
+	Board        BoardRef
 	Group        Group
 	ColumnValues []ColumnValue `graphql:"column_values"`
 }
 
-func (item Item) String() string {
-	var email, phone = "", ""
+func (item Item) Email() string {
 	for _, c := range item.ColumnValues {
 		if c.EmailValue.Email != "" {
-			email = string(c.EmailValue.Email)
+			return string(c.EmailValue.Email)
 		}
+	}
+	return ""
+}
+
+func (item Item) Phone() string {
+	for _, c := range item.ColumnValues {
 		if c.PhoneValue.Phone != "" {
-			phone = string(c.PhoneValue.Phone)
+			return string(c.PhoneValue.Phone)
 		}
 	}
-	return fmt.Sprintf("Name: %s, Email: %s, Phone: %s\n", item.Name, email, phone)
+	return ""
+}
+
+func (item Item) String() string {
+	return fmt.Sprintf("Name: %s, Email: %s, Phone: %s\n", item.Name, item.Email(), item.Phone())
 }
 
 type ItemsPage struct {
-	// Cursor graphql.String
-	Items []Item `graphql:"items" json:"items"`
+	Cursor graphql.String `graphql:"cursor" json:"cursor"`
+	Items  []Item         `graphql:"items" json:"items"`
+}
+
+type BoardWithAllItemsPage struct {
+	ItemsPage ItemsPage `graphql:"items_page(limit: $limit)" json:"items_page"`
+}
+
+type BoardItemsPageQuery struct {
+	Boards []BoardWithAllItemsPage `graphql:"boards(ids: [$ids])"`
+}
+
+type NextItemsPageQuery struct {
+	NextItemsPage ItemsPage `graphql:"next_items_page(limit: $limit cursor: $cursor)"`
+}
+
+type ItemsByIdQuery struct {
+	Items []Item `graphql:"items(ids: $ids)"`
+}
+
+// GroupItemsQuery asks for the first items of a group of a board.
+type GroupItemsQuery struct {
+	Boards []struct {
+		Groups []struct {
+			ItemsPage ItemsPage `graphql:"items_page(limit: $limit)"`
+		} `graphql:"groups(ids: [$groupId])"`
+	} `graphql:"boards(ids: [$ids])"`
+}
+
+// Update is a note posted on an item.
+type Update struct {
+	Id        graphql.ID
+	TextBody  graphql.String `graphql:"text_body"`
+	CreatedAt graphql.String `graphql:"created_at"`
+	Creator   struct {
+		Name graphql.String
+	}
+}
+
+type ItemUpdatesQuery struct {
+	Items []struct {
+		Updates []Update `graphql:"updates(limit: $limit)"`
+	} `graphql:"items(ids: $ids)"`
 }
 type BoardWithItemsPage struct {
 	ItemsPage   ItemsPage `graphql:"items_page(limit: $limit query_params: $queryParams)" json:"items_page"`
@@ -128,8 +386,14 @@ type BoardByIdWithFilterItemsQuery struct {
 }
 
 type ItemsQuery struct {
-	Rules    []ItemsQueryRule   `graphql:"rules" json:"rules"`
-	Operator ItemsQueryOperator `graphql:"operator" json:"operator"`
+	Rules    []ItemsQueryRule   `graphql:"rules" json:"rules,omitempty"`
+	Operator ItemsQueryOperator `graphql:"operator" json:"operator,omitempty"`
+	OrderBy  []ItemsOrderBy     `graphql:"order_by" json:"order_by,omitempty"`
+}
+
+type ItemsOrderBy struct {
+	ColumnId  graphql.ID `json:"column_id"`
+	Direction string     `json:"direction"`
 }
 
 func (q *ItemsQuery) SetRules(rules []ItemsQueryRule) {
@@ -140,6 +404,10 @@ func (q *ItemsQuery) SetOperator(op ItemsQueryOperator) {
 	q.Operator = op
 }
 
+func (q *ItemsQuery) AddOrderBy(colId graphql.ID, direction string) {
+	q.OrderBy = append(q.OrderBy, ItemsOrderBy{ColumnId: colId, Direction: direction})
+}
+
 func (q *ItemsQuery) AddRule(colId graphql.ID, colVar CompareValue, op ItemsQueryRuleOperator) {
 	q.Rules = append(q.Rules, ItemsQueryRule{ColumnId: colId, CompareValue: colVar, Operator: op})
 }
@@ -155,6 +423,13 @@ type CompareValue graphql.String
 type ItemsQueryRuleOperator string
 type JSON string
 
+const (
+	// LAST_UPDATED_COLUMN is the pseudo column holding when an item last changed.
+	LAST_UPDATED_COLUMN = "__last_updated__"
+	ORDER_ASC           = "asc"
+	ORDER_DESC          = "desc"
+)
+
 const (
 	ANY_OF                 ItemsQueryRuleOperator = "any_of"
 	NOT_ANY_OF             ItemsQueryRuleOperator = "not_any_of"
@@ -174,10 +449,6 @@ const (
 	WITHIN_THE_LAST        ItemsQueryRuleOperator = "within_the_last"
 )
 
-const (
-	COLUMN_TYPE_STATUS string = "status"
-)
-
 type CreateItem struct {
 	Id graphql.ID
 }
@@ -192,4 +463,137 @@ type CreateItemRequest struct {
 	Name      string
 	Email     string
 	Phone     string
+	// Columns holds any other column values, keyed by column id or title.
+	Columns map[string]string
+	// IdempotencyKey names the request for its retries to create the item
+	// once. The ops server keeps the keys it saw; the client only traces it.
+	IdempotencyKey string
+}
+
+type UpdateItem struct {
+	Id graphql.ID
+}
+type UpdateItemMutation struct {
+	UpdateItem UpdateItem `graphql:"change_multiple_column_values(item_id: $itemId board_id: $boardId column_values: $cols)"`
+}
+
+type UpdateItemRequest struct {
+	ItemId string
+	// Name renames the item when not empty.
+	Name string
+	// Columns holds the new column values, keyed by column id or title. An
+	// empty value clears the column.
+	Columns map[string]string
+	// Values holds raw column values, keyed by column id or title, sent to
+	// monday as they are, e.g. to put back a snapshot. A null value clears
+	// the column.
+	Values map[string]json.RawMessage
+}
+
+type ArchiveItem struct {
+	Id graphql.ID
+}
+type ArchiveItemMutation struct {
+	ArchiveItem ArchiveItem `graphql:"archive_item(item_id: $itemId)"`
+}
+
+type CreateUpdate struct {
+	Id graphql.ID
+}
+type CreateUpdateMutation struct {
+	CreateUpdate CreateUpdate `graphql:"create_update(item_id: $itemId body: $body)"`
+}
+
+type DeleteItem struct {
+	Id graphql.ID
+}
+type DeleteItemMutation struct {
+	DeleteItem DeleteItem `graphql:"delete_item(item_id: $itemId)"`
+}
+
+type DeleteUpdate struct {
+	Id graphql.ID
+}
+type DeleteUpdateMutation struct {
+	DeleteUpdate DeleteUpdate `graphql:"delete_update(id: $updateId)"`
+}
+
+// ItemSnapshot is what an update is about to change on an item, to put it
+// back with UpdateItem.
+type ItemSnapshot struct {
+	ItemId string `json:"item_id,omitempty"`
+	Board  string `json:"board,omitempty"`
+	// Name is the name of the item when the update renames it.
+	Name string `json:"name,omitempty"`
+	// Values holds the raw values of the columns the update sets, keyed by
+	// column id: the texts monday shows cannot always be set back, e.g. the
+	// labels of a dropdown or the text of a link. A null value is a column
+	// that was empty.
+	Values map[string]json.RawMessage `json:"values,omitempty"`
+}
+
+// Restore is the update putting back what the snapshot holds.
+func (s ItemSnapshot) Restore() UpdateItemRequest {
+	return UpdateItemRequest{ItemId: s.ItemId, Name: s.Name, Values: s.Values}
+}
+
+type BoardKind string
+type ColumnType string
+
+const (
+	BOARD_KIND_PUBLIC    BoardKind = "public"
+	BOARD_KIND_PRIVATE   BoardKind = "private"
+	BOARD_KIND_SHAREABLE BoardKind = "share"
+)
+
+const (
+	COLUMN_TYPE_STATUS   ColumnType = "status"
+	COLUMN_TYPE_TEXT     ColumnType = "text"
+	COLUMN_TYPE_EMAIL    ColumnType = "email"
+	COLUMN_TYPE_PHONE    ColumnType = "phone"
+	COLUMN_TYPE_DROPDOWN ColumnType = "dropdown"
+	COLUMN_TYPE_DATE     ColumnType = "date"
+	COLUMN_TYPE_NUMBERS  ColumnType = "numbers"
+	COLUMN_TYPE_LONG     ColumnType = "long_text"
+)
+
+type CreateBoard struct {
+	Id graphql.ID
+}
+type CreateBoardMutation struct {
+	CreateBoard CreateBoard `graphql:"create_board(board_name: $boardName board_kind: $boardKind workspace_id: $wsId description: $description)"`
+}
+
+type CreateBoardRequest struct {
+	Name        string
+	Kind        BoardKind
+	Description string
+	WorkspaceId graphql.ID
+}
+
+type CreateGroup struct {
+	Id graphql.ID
+}
+type CreateGroupMutation struct {
+	CreateGroup CreateGroup `graphql:"create_group(board_id: $boardId group_name: $groupName)"`
+}
+
+type CreateGroupRequest struct {
+	BoardId graphql.ID
+	Name    string
+}
+
+type CreateColumn struct {
+	Id graphql.ID
+}
+type CreateColumnMutation struct {
+	CreateColumn CreateColumn `graphql:"create_column(board_id: $boardId title: $title column_type: $columnType defaults: $defaults)"`
+}
+
+type CreateColumnRequest struct {
+	BoardId graphql.ID
+	Title   string
+	Type    ColumnType
+	// Labels are only used for status and dropdown columns.
+	Labels []string
 }
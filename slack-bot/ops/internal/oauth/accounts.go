@@ -0,0 +1,124 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/secret"
+	"golang.org/x/oauth2"
+)
+
+// Accounts hands out a monday client per installed account, with the token
+// the account granted.
+type Accounts struct {
+	store    *Store
+	url      string
+	mu       sync.Mutex
+	settings monday.Settings
+	// clients are kept by account id, along with the token they were made
+	// with to notice a reinstall
+	clients map[string]accountClient
+}
+
+type accountClient struct {
+	token  string
+	client *monday.ApiClient
+}
+
+// NewAccounts calls monday at url for the accounts of store, with settings.
+func NewAccounts(store *Store, url string, settings monday.Settings) *Accounts {
+	return &Accounts{store: store, url: url, settings: settings, clients: map[string]accountClient{}}
+}
+
+// Client returns the client of the account team is routed to,
+// ErrNotInstalled when there is none.
+func (a *Accounts) Client(ctx context.Context, team string) (*monday.ApiClient, error) {
+	if team == "" {
+		return nil, fmt.Errorf("%w for callers without a team", ErrNotInstalled)
+	}
+	account, err := a.store.ForTeam(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if cached, ok := a.clients[account.Id]; ok && cached.token == account.Token {
+		return cached.client, nil
+	}
+	var client = monday.New(a.url, secret.Static(account.Token, account.String()))
+	client.Configure(a.settings)
+	a.clients[account.Id] = accountClient{token: account.Token, client: client}
+	return client, nil
+}
+
+// Configure makes every client follow settings, as monday.ApiClient does.
+func (a *Accounts) Configure(settings monday.Settings) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.settings = settings
+	for _, cached := range a.clients {
+		cached.client.Configure(settings)
+	}
+}
+
+// install keeps the account token belongs to and routes team to it, replacing
+// the account team was routed to with replace.
+func (a *Accounts) install(ctx context.Context, token, scope, team string, replace bool) (Account, error) {
+	var client = monday.New(a.url, secret.Static(token, "token being installed"))
+	listing, err := client.GetAccount(ctx)
+	if err != nil {
+		return Account{}, fmt.Errorf("failed to identify the monday account: %w", err)
+	}
+	var account = Account{
+		Id:          fmt.Sprint(listing.Id),
+		Name:        string(listing.Name),
+		Token:       token,
+		Scope:       scope,
+		InstalledAt: time.Now(),
+	}
+	if err := a.store.Install(ctx, account, team, replace); err != nil {
+		return Account{}, err
+	}
+	return account, nil
+}
+
+// accountToken is the token of an installed account, read from the store
+// until it is refused, e.g. after a reinstall replaced it.
+type accountToken struct {
+	store *Store
+	id    string
+	mu    sync.Mutex
+	token string
+}
+
+// Token returns the token of the account with id as a secret.Source, for
+// clients made before the account is installed or outliving a reinstall.
+func (s *Store) Token(id string) secret.Source {
+	return &accountToken{store: s, id: id}
+}
+
+func (t *accountToken) Token() (*oauth2.Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token == "" {
+		account, err := t.store.Account(context.Background(), t.id)
+		if err != nil {
+			return nil, err
+		}
+		t.token = account.Token
+	}
+	return &oauth2.Token{AccessToken: t.token}, nil
+}
+
+func (t *accountToken) Forget() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = ""
+}
+
+func (t *accountToken) String() string {
+	return "installed monday account " + t.id
+}
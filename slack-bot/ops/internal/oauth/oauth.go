@@ -0,0 +1,115 @@
+// Package oauth installs ops in monday accounts with monday's OAuth
+// authorization code flow, keeps the token each account grants, and routes
+// the callers of every Slack team to the client of their account.
+package oauth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/installlink"
+	"golang.org/x/oauth2"
+)
+
+const (
+	INSTALL_PATH      = "/oauth/install"
+	CALLBACK_PATH     = "/oauth/callback"
+	DEFAULT_AUTH_URL  = "https://auth.monday.com/oauth2/authorize"
+	DEFAULT_TOKEN_URL = "https://auth.monday.com/oauth2/token"
+	// how long an installation may take between the redirect to monday and
+	// its callback
+	stateTTL = 10 * time.Minute
+)
+
+// Installer serves INSTALL_PATH, which sends the installing user to monday
+// to grant access, and CALLBACK_PATH, where monday sends them back with a
+// code exchanged for the account token. Installations start from an install
+// link the bot signed for an admin of the Slack team.
+type Installer struct {
+	config     *oauth2.Config
+	accounts   *Accounts
+	linkSecret []byte
+	mux        *http.ServeMux
+	mu         sync.Mutex
+	// pending holds the install link of every installation under way, by
+	// state
+	pending map[string]pendingInstall
+}
+
+type pendingInstall struct {
+	link   installlink.Link
+	expiry time.Time
+}
+
+// NewInstaller installs ops in the accounts of config for the links signed
+// with linkSecret.
+func NewInstaller(config *oauth2.Config, accounts *Accounts, linkSecret []byte) *Installer {
+	var installer = &Installer{config: config, accounts: accounts, linkSecret: linkSecret, mux: http.NewServeMux(), pending: map[string]pendingInstall{}}
+	installer.mux.HandleFunc("GET "+INSTALL_PATH, installer.install)
+	installer.mux.HandleFunc("GET "+CALLBACK_PATH, installer.callback)
+	return installer
+}
+
+func (i *Installer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	i.mux.ServeHTTP(w, r)
+}
+
+// install starts installing ops for the Slack team of the install link
+// followed.
+func (i *Installer) install(w http.ResponseWriter, r *http.Request) {
+	link, err := installlink.Verify(i.linkSecret, r.URL.Query(), time.Now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("%s, ask the bot for a new one with /contact install", err), http.StatusForbidden)
+		return
+	}
+	var nonce = make([]byte, 16)
+	rand.Read(nonce)
+	var state = hex.EncodeToString(nonce)
+	i.mu.Lock()
+	for s, p := range i.pending {
+		if time.Now().After(p.expiry) {
+			delete(i.pending, s)
+		}
+	}
+	i.pending[state] = pendingInstall{link: link, expiry: time.Now().Add(stateTTL)}
+	i.mu.Unlock()
+	http.Redirect(w, r, i.config.AuthCodeURL(state), http.StatusFound)
+}
+
+// callback finishes an installation, once per state.
+func (i *Installer) callback(w http.ResponseWriter, r *http.Request) {
+	var query = r.URL.Query()
+	i.mu.Lock()
+	pending, ok := i.pending[query.Get("state")]
+	delete(i.pending, query.Get("state"))
+	i.mu.Unlock()
+	if !ok || time.Now().After(pending.expiry) {
+		http.Error(w, "unknown or expired installation, start again from "+INSTALL_PATH, http.StatusBadRequest)
+		return
+	}
+	if reason := query.Get("error"); reason != "" {
+		http.Error(w, fmt.Sprintf("monday did not grant access: %s", reason), http.StatusForbidden)
+		return
+	}
+	token, err := i.config.Exchange(r.Context(), query.Get("code"))
+	if err != nil {
+		log.Println(fmt.Errorf("failed to exchange the code of team %s: %w", pending.link.Team, err))
+		http.Error(w, "monday refused the installation", http.StatusBadGateway)
+		return
+	}
+	var scope, _ = token.Extra("scope").(string)
+	// the link re-authenticated the installation for its team
+	account, err := i.accounts.install(r.Context(), token.AccessToken, scope, pending.link.Team, true)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to install team %s: %w", pending.link.Team, err))
+		http.Error(w, "failed to install, try again later", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Installed %s for Slack team %s by %s", account, pending.link.Team, pending.link.User)
+	fmt.Fprintf(w, "ops is installed in %s for Slack team %s, you can close this page\n", account, pending.link.Team)
+}
@@ -0,0 +1,262 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/installlink"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"golang.org/x/oauth2"
+)
+
+// fakeMonday grants access to account, answering the authorizations right
+// away like cmd/oauthstub, and tells each token's account in the `me`
+// query.
+type fakeMonday struct {
+	*httptest.Server
+	mu      sync.Mutex
+	account string
+	issued  int
+	codes   map[string]string
+	tokens  map[string]string
+}
+
+func newFakeMonday(t *testing.T) *fakeMonday {
+	var f = &fakeMonday{codes: map[string]string{}, tokens: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /oauth2/authorize", f.authorize)
+	mux.HandleFunc("POST /oauth2/token", f.token)
+	mux.HandleFunc("POST /v2", f.graphql)
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeMonday) authorize(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	redirect, _ := url.Parse(r.URL.Query().Get("redirect_uri"))
+	var back = url.Values{"state": {r.URL.Query().Get("state")}}
+	if f.account == "" {
+		back.Set("error", "access_denied")
+	} else {
+		f.issued++
+		var code = fmt.Sprint("code-", f.issued)
+		f.codes[code] = f.account
+		back.Set("code", code)
+	}
+	redirect.RawQuery = back.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (f *fakeMonday) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	var id, secret, ok = r.BasicAuth()
+	if !ok {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	account, known := f.codes[r.PostForm.Get("code")]
+	if id != "client" || secret != "secret" || !known {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error":"invalid_grant"}`)
+		return
+	}
+	delete(f.codes, r.PostForm.Get("code"))
+	var token = fmt.Sprint("token-", f.issued)
+	f.tokens[token] = account
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"access_token": token, "token_type": "Bearer", "scope": "boards:read"})
+}
+
+func (f *fakeMonday) graphql(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	account, known := f.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	f.mu.Unlock()
+	if !known {
+		http.Error(w, "unknown token", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+		"me":         map[string]any{"account": map[string]string{"id": account, "name": "Account " + account, "slug": account}},
+		"complexity": map[string]int{"after": 5000000, "reset_in_x_seconds": 60},
+	}})
+}
+
+// grant has the fake grant access to account, or deny it when empty.
+func (f *fakeMonday) grant(account string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.account = account
+}
+
+var linkSecret = []byte("link secret")
+
+// linkFor is an install link for an admin of team, signed with secret.
+func linkFor(t *testing.T, installer *httptest.Server, secret []byte, team string) string {
+	t.Helper()
+	link, err := installlink.Sign(secret, installer.URL+INSTALL_PATH, installlink.Link{Team: team, User: "U1", Expiry: time.Now().Add(time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return link
+}
+
+// installFor installs ops for team through installer, following the
+// redirects to the fake and back, and returns the page it ends on.
+func installFor(t *testing.T, installer *httptest.Server, team string) (int, string) {
+	t.Helper()
+	return follow(t, linkFor(t, installer, linkSecret, team))
+}
+
+// follow gets link, following the redirects, and returns the page it ends
+// on.
+func follow(t *testing.T, link string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func accountOf(t *testing.T, accounts *Accounts, team string) (string, error) {
+	t.Helper()
+	client, err := accounts.Client(context.Background(), team)
+	if err != nil {
+		return "", err
+	}
+	account, err := client.GetAccount(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprint(account.Id), nil
+}
+
+// newInstaller serves an Installer of the accounts of fake, verifying the
+// links signed with linkSecret, until the end of the test.
+func newInstaller(t *testing.T, fake *fakeMonday) (*httptest.Server, *Store, *Accounts) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "accounts.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	var accounts = NewAccounts(store, fake.URL+"/v2", monday.Settings{})
+	var installer = httptest.NewUnstartedServer(nil)
+	installer.Config.Handler = NewInstaller(&oauth2.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Endpoint:     oauth2.Endpoint{AuthURL: fake.URL + "/oauth2/authorize", TokenURL: fake.URL + "/oauth2/token"},
+		RedirectURL:  "http://" + installer.Listener.Addr().String() + CALLBACK_PATH,
+	}, accounts, linkSecret)
+	installer.Start()
+	t.Cleanup(installer.Close)
+	return installer, store, accounts
+}
+
+func TestInstallRoutesTeamsToTheirAccount(t *testing.T) {
+	var fake = newFakeMonday(t)
+	var installer, store, accounts = newInstaller(t, fake)
+
+	if _, err := accounts.Client(context.Background(), "T1"); !errors.Is(err, ErrNotInstalled) {
+		t.Fatalf("Client() before installing: err = %v, want ErrNotInstalled", err)
+	}
+	fake.grant("101")
+	if code, page := installFor(t, installer, "T1"); code != http.StatusOK || !strings.Contains(page, "Account 101") {
+		t.Fatalf("installing T1 answered %d %s", code, page)
+	}
+	fake.grant("202")
+	if code, page := installFor(t, installer, "T2"); code != http.StatusOK {
+		t.Fatalf("installing T2 answered %d %s", code, page)
+	}
+	for team, want := range map[string]string{"T1": "101", "T2": "202"} {
+		if account, err := accountOf(t, accounts, team); err != nil || account != want {
+			t.Errorf("team %s calls account %q, %v, want %s", team, account, err, want)
+		}
+	}
+	installed, err := store.ForTeam(context.Background(), "T1")
+	if err != nil || installed.Scope != "boards:read" || installed.Token == "" {
+		t.Errorf("stored %v, %v, want the token and scope granted", installed, err)
+	}
+
+	// a reinstall replaces the token the cached client was made with
+	var before, _ = accounts.Client(context.Background(), "T1")
+	fake.grant("101")
+	installFor(t, installer, "T1")
+	if after, _ := accounts.Client(context.Background(), "T1"); after == before {
+		t.Error("the client of T1 kept the token replaced by the reinstall")
+	}
+
+	fake.grant("")
+	if code, _ := installFor(t, installer, "T3"); code != http.StatusForbidden {
+		t.Errorf("denied installation answered %d, want %d", code, http.StatusForbidden)
+	}
+	if _, err := accounts.Client(context.Background(), "T3"); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("Client() of a denied team: err = %v, want ErrNotInstalled", err)
+	}
+}
+
+func TestCallbackRefusesUnknownState(t *testing.T) {
+	var installer = httptest.NewServer(NewInstaller(&oauth2.Config{}, nil, linkSecret))
+	defer installer.Close()
+	resp, err := http.Get(installer.URL + CALLBACK_PATH + "?state=forged&code=stolen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("callback with an unknown state answered %d", resp.StatusCode)
+	}
+}
+
+func TestInstallNeedsALinkForTheTeam(t *testing.T) {
+	var fake = newFakeMonday(t)
+	var installer, store, accounts = newInstaller(t, fake)
+	fake.grant("101")
+	if code, page := installFor(t, installer, "T1"); code != http.StatusOK {
+		t.Fatalf("installing T1 answered %d %s", code, page)
+	}
+
+	// someone else installs their account for T1 without a valid link
+	fake.grant("666")
+	var expired, _ = installlink.Sign(linkSecret, installer.URL+INSTALL_PATH, installlink.Link{Team: "T1", User: "U1", Expiry: time.Now().Add(-time.Second)})
+	var other = strings.Replace(linkFor(t, installer, linkSecret, "T2"), "team=T2", "team=T1", 1)
+	for name, link := range map[string]string{
+		"no link":         installer.URL + INSTALL_PATH + "?team=T1",
+		"other secret":    linkFor(t, installer, []byte("forged"), "T1"),
+		"expired":         expired,
+		"other team link": other,
+	} {
+		if code, _ := follow(t, link); code != http.StatusForbidden {
+			t.Errorf("%s: installing T1 again answered %d, want %d", name, code, http.StatusForbidden)
+		}
+	}
+	if account, err := accountOf(t, accounts, "T1"); err != nil || account != "101" {
+		t.Errorf("team T1 calls account %q, %v, want 101", account, err)
+	}
+
+	// the store only routes T1 elsewhere when told to
+	var account = Account{Id: "666", Name: "Account 666", Token: "token-666", InstalledAt: time.Now()}
+	if err := store.Install(context.Background(), account, "T1", false); !errors.Is(err, ErrTeamInstalled) {
+		t.Errorf("Install() over T1: err = %v, want ErrTeamInstalled", err)
+	}
+	if err := store.Install(context.Background(), account, "T1", true); err != nil {
+		t.Errorf("Install() replacing T1: %v", err)
+	}
+}
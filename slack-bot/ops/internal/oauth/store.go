@@ -0,0 +1,143 @@
+package oauth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+var ErrNotInstalled = errors.New("no monday account installed")
+
+// ErrTeamInstalled is returned when installing for a team already routed to
+// another account without being allowed to replace it.
+var ErrTeamInstalled = errors.New("team already installed")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS accounts (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	token TEXT NOT NULL,
+	scope TEXT NOT NULL,
+	installed_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS teams (
+	team TEXT PRIMARY KEY,
+	account TEXT NOT NULL REFERENCES accounts(id)
+);`
+
+// Account is a monday account ops was installed in, with the access token
+// it was granted.
+type Account struct {
+	Id          string
+	Name        string
+	Token       string
+	Scope       string
+	InstalledAt time.Time
+}
+
+// String leaves the token out.
+func (a Account) String() string {
+	return fmt.Sprintf("monday account %s (%s)", a.Name, a.Id)
+}
+
+// Store keeps the accounts ops was installed in and the Slack teams routed
+// to each, in a SQLite file only its owner may read.
+type Store struct {
+	db *sql.DB
+}
+
+func OpenStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open accounts %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create accounts schema: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to restrict accounts %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Install keeps account, replacing the token it had, and routes team to it.
+// A team routed to another account is only routed again with replace, for
+// installations re-authenticated for that team; ErrTeamInstalled otherwise.
+func (s *Store) Install(ctx context.Context, account Account, team string, replace bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to install account: %w", err)
+	}
+	defer tx.Rollback()
+	var current string
+	err = tx.QueryRowContext(ctx, `SELECT account FROM teams WHERE team = ?`, team).Scan(&current)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("failed to look up the account of team %s: %w", team, err)
+	case current != account.Id && !replace:
+		return fmt.Errorf("%w: %s is routed to account %s", ErrTeamInstalled, team, current)
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO accounts(id, name, token, scope, installed_at) VALUES(?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name, token = excluded.token, scope = excluded.scope, installed_at = excluded.installed_at`,
+		account.Id, account.Name, account.Token, account.Scope, account.InstalledAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to install account: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO teams(team, account) VALUES(?, ?)
+		ON CONFLICT(team) DO UPDATE SET account = excluded.account`, team, account.Id)
+	if err != nil {
+		return fmt.Errorf("failed to route team %s: %w", team, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to install account: %w", err)
+	}
+	return nil
+}
+
+// ForTeam returns the account team is routed to, ErrNotInstalled when there
+// is none.
+func (s *Store) ForTeam(ctx context.Context, team string) (Account, error) {
+	var account = Account{}
+	var installedAt string
+	err := s.db.QueryRowContext(ctx, `SELECT a.id, a.name, a.token, a.scope, a.installed_at
+		FROM teams t JOIN accounts a ON a.id = t.account WHERE t.team = ?`, team).
+		Scan(&account.Id, &account.Name, &account.Token, &account.Scope, &installedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Account{}, fmt.Errorf("%w for team %s", ErrNotInstalled, team)
+	}
+	if err != nil {
+		return Account{}, fmt.Errorf("failed to look up the account of team %s: %w", team, err)
+	}
+	account.InstalledAt, _ = time.Parse(time.RFC3339, installedAt)
+	return account, nil
+}
+
+// Account returns the account with id, ErrNotInstalled when ops was not
+// installed in it.
+func (s *Store) Account(ctx context.Context, id string) (Account, error) {
+	var account = Account{}
+	var installedAt string
+	err := s.db.QueryRowContext(ctx, `SELECT id, name, token, scope, installed_at FROM accounts WHERE id = ?`, id).
+		Scan(&account.Id, &account.Name, &account.Token, &account.Scope, &installedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Account{}, fmt.Errorf("%w with id %s", ErrNotInstalled, id)
+	}
+	if err != nil {
+		return Account{}, fmt.Errorf("failed to look up account %s: %w", id, err)
+	}
+	account.InstalledAt, _ = time.Parse(time.RFC3339, installedAt)
+	return account, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,206 @@
+package provision
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+)
+
+type Op string
+
+const (
+	// OpCreate marks something the spec declares and the board lacks.
+	OpCreate Op = "+"
+	// OpKeep marks something that already matches the spec.
+	OpKeep Op = "="
+	// OpDrift marks something that exists but differs from the spec in a way
+	// the API cannot reconcile, e.g. a column with a different type.
+	OpDrift Op = "!"
+)
+
+type Target string
+
+const (
+	TargetBoard  Target = "board"
+	TargetGroup  Target = "group"
+	TargetColumn Target = "column"
+)
+
+type Change struct {
+	Op     Op
+	Target Target
+	Name   string
+	Detail string
+	column *ColumnSpec
+}
+
+func (c Change) String() string {
+	if c.Detail == "" {
+		return fmt.Sprintf("%s %s %q", c.Op, c.Target, c.Name)
+	}
+	return fmt.Sprintf("%s %s %q: %s", c.Op, c.Target, c.Name, c.Detail)
+}
+
+// Plan is the difference between a Spec and what currently exists in the
+// workspace. Applying it only ever creates, so running it twice is a no-op.
+type Plan struct {
+	Spec      *Spec
+	Workspace *monday.WorkspaceListing
+	BoardId   string
+	Changes   []Change
+}
+
+func NewPlan(ctx context.Context, client *monday.ApiClient, ws *monday.WorkspaceListing, spec *Spec) (*Plan, error) {
+	var plan = &Plan{Spec: spec, Workspace: ws}
+	boards, err := client.ListBoards(ctx, ws)
+	if err != nil {
+		return nil, fmt.Errorf("could not list all boards: %w", err)
+	}
+	var existing *monday.BoardListing
+	for _, b := range boards {
+		if strings.EqualFold(string(b.Name), spec.Name) {
+			existing = &b
+			break
+		}
+	}
+	if existing == nil {
+		plan.add(Change{Op: OpCreate, Target: TargetBoard, Name: spec.Name})
+		for _, g := range spec.Groups {
+			plan.add(Change{Op: OpCreate, Target: TargetGroup, Name: g})
+		}
+		for _, c := range spec.Columns {
+			plan.add(Change{Op: OpCreate, Target: TargetColumn, Name: c.Title, Detail: describeColumn(c), column: &c})
+		}
+		return plan, nil
+	}
+
+	boardId, ok := existing.Id.(string)
+	if !ok {
+		return nil, fmt.Errorf("board id cannot be cast to string")
+	}
+	plan.BoardId = boardId
+	plan.add(Change{Op: OpKeep, Target: TargetBoard, Name: string(existing.Name)})
+
+	withGroups, err := client.GetBoardWithGroups(ctx, boardId)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range spec.Groups {
+		var found = slices.ContainsFunc(withGroups.Groups, func(group monday.Group) bool {
+			return strings.EqualFold(string(group.Title), g)
+		})
+		if found {
+			plan.add(Change{Op: OpKeep, Target: TargetGroup, Name: g})
+		} else {
+			plan.add(Change{Op: OpCreate, Target: TargetGroup, Name: g})
+		}
+	}
+
+	for _, c := range spec.Columns {
+		var idx = slices.IndexFunc(existing.Columns, func(col monday.Column) bool {
+			return strings.EqualFold(string(col.Title), c.Title)
+		})
+		if idx < 0 {
+			plan.add(Change{Op: OpCreate, Target: TargetColumn, Name: c.Title, Detail: describeColumn(c), column: &c})
+			continue
+		}
+		change, err := diffColumn(existing.Columns[idx], c)
+		if err != nil {
+			return nil, err
+		}
+		plan.add(change)
+	}
+	return plan, nil
+}
+
+func (p *Plan) add(c Change) {
+	p.Changes = append(p.Changes, c)
+}
+
+// Pending reports whether applying the plan would change anything.
+func (p *Plan) Pending() bool {
+	return slices.ContainsFunc(p.Changes, func(c Change) bool { return c.Op == OpCreate })
+}
+
+func (p *Plan) String() string {
+	var sb = strings.Builder{}
+	fmt.Fprintf(&sb, "Workspace %s\n", p.Workspace.Name)
+	for _, c := range p.Changes {
+		fmt.Fprintln(&sb, c)
+	}
+	return sb.String()
+}
+
+func (p *Plan) Apply(ctx context.Context, client *monday.ApiClient) error {
+	for _, c := range p.Changes {
+		if c.Op != OpCreate {
+			continue
+		}
+		switch c.Target {
+		case TargetBoard:
+			id, err := client.CreateBoard(ctx, monday.CreateBoardRequest{
+				Name:        p.Spec.Name,
+				Kind:        monday.BoardKind(p.Spec.Kind),
+				Description: p.Spec.Description,
+				WorkspaceId: p.Workspace.Id,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create board %s: %w", c.Name, err)
+			}
+			p.BoardId = id
+		case TargetGroup:
+			_, err := client.CreateGroup(ctx, monday.CreateGroupRequest{BoardId: p.BoardId, Name: c.Name})
+			if err != nil {
+				return fmt.Errorf("failed to create group %s: %w", c.Name, err)
+			}
+		case TargetColumn:
+			_, err := client.CreateColumn(ctx, monday.CreateColumnRequest{
+				BoardId: p.BoardId,
+				Title:   c.column.Title,
+				Type:    monday.ColumnType(c.column.Type),
+				Labels:  c.column.Labels,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create column %s: %w", c.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func diffColumn(col monday.Column, spec ColumnSpec) (Change, error) {
+	var change = Change{Op: OpKeep, Target: TargetColumn, Name: spec.Title, Detail: describeColumn(spec)}
+	if !strings.EqualFold(string(col.Type), spec.Type) {
+		change.Op = OpDrift
+		change.Detail = fmt.Sprintf("is of type %s, spec wants %s", col.Type, spec.Type)
+		return change, nil
+	}
+	if len(spec.Labels) == 0 {
+		return change, nil
+	}
+	labels, err := col.Labels()
+	if err != nil {
+		return change, err
+	}
+	var missing = []string{}
+	for _, l := range spec.Labels {
+		if !slices.ContainsFunc(labels, func(existing string) bool { return strings.EqualFold(existing, l) }) {
+			missing = append(missing, l)
+		}
+	}
+	if len(missing) > 0 {
+		change.Op = OpDrift
+		change.Detail = fmt.Sprintf("is missing labels [%s], add them from monday", strings.Join(missing, ", "))
+	}
+	return change, nil
+}
+
+func describeColumn(c ColumnSpec) string {
+	if len(c.Labels) == 0 {
+		return c.Type
+	}
+	return fmt.Sprintf("%s [%s]", c.Type, strings.Join(c.Labels, ", "))
+}
@@ -0,0 +1,93 @@
+package provision
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec declares the shape of a single contact board: which groups it holds
+// and which columns (with their status labels or dropdown options) it has.
+type Spec struct {
+	Name        string       `yaml:"name" json:"name"`
+	Kind        string       `yaml:"kind" json:"kind"`
+	Description string       `yaml:"description" json:"description"`
+	Groups      []string     `yaml:"groups" json:"groups"`
+	Columns     []ColumnSpec `yaml:"columns" json:"columns"`
+}
+
+type ColumnSpec struct {
+	Title  string   `yaml:"title" json:"title"`
+	Type   string   `yaml:"type" json:"type"`
+	Labels []string `yaml:"labels" json:"labels"`
+}
+
+// Load reads a board spec from a .json file or from YAML for any other extension.
+func Load(path string) (*Spec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+	var spec = &Spec{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(spec)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(spec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode spec %s: %w", path, err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func (s *Spec) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("spec is missing the board name")
+	}
+	switch monday.BoardKind(s.Kind) {
+	case "", monday.BOARD_KIND_PUBLIC, monday.BOARD_KIND_PRIVATE, monday.BOARD_KIND_SHAREABLE:
+	default:
+		return fmt.Errorf("unknown board kind %q", s.Kind)
+	}
+	var groups = map[string]bool{}
+	for _, g := range s.Groups {
+		if strings.TrimSpace(g) == "" {
+			return fmt.Errorf("group names cannot be empty")
+		}
+		if groups[strings.ToLower(g)] {
+			return fmt.Errorf("group %q is declared twice", g)
+		}
+		groups[strings.ToLower(g)] = true
+	}
+	var columns = map[string]bool{}
+	for _, c := range s.Columns {
+		if strings.TrimSpace(c.Title) == "" {
+			return fmt.Errorf("column titles cannot be empty")
+		}
+		if c.Type == "" {
+			return fmt.Errorf("column %q is missing its type", c.Title)
+		}
+		if columns[strings.ToLower(c.Title)] {
+			return fmt.Errorf("column %q is declared twice", c.Title)
+		}
+		columns[strings.ToLower(c.Title)] = true
+		var colType = monday.ColumnType(c.Type)
+		if len(c.Labels) > 0 && colType != monday.COLUMN_TYPE_STATUS && colType != monday.COLUMN_TYPE_DROPDOWN {
+			return fmt.Errorf("column %q of type %s cannot have labels", c.Title, c.Type)
+		}
+	}
+	return nil
+}
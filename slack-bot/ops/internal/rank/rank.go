@@ -0,0 +1,246 @@
+package rank
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+)
+
+type Sort string
+
+const (
+	// SORT_NONE keeps items in the order the boards answered.
+	SORT_NONE Sort = "none"
+	// SORT_RELEVANCE orders by match quality against the searched value.
+	SORT_RELEVANCE Sort = "relevance"
+	// SORT_NAME orders alphabetically by item name.
+	SORT_NAME Sort = "name"
+)
+
+func ParseSort(s string) (Sort, error) {
+	switch Sort(strings.ToLower(s)) {
+	case "", SORT_NONE:
+		return SORT_NONE, nil
+	case SORT_RELEVANCE:
+		return SORT_RELEVANCE, nil
+	case SORT_NAME:
+		return SORT_NAME, nil
+	}
+	return "", fmt.Errorf("unknown sort %q, expected none, relevance or name", s)
+}
+
+type Match int
+
+const (
+	MATCH_NONE Match = iota
+	MATCH_FUZZY
+	MATCH_PREFIX
+	MATCH_EXACT
+)
+
+func (m Match) String() string {
+	switch m {
+	case MATCH_EXACT:
+		return "exact"
+	case MATCH_PREFIX:
+		return "prefix"
+	case MATCH_FUZZY:
+		return "fuzzy"
+	}
+	return "none"
+}
+
+// fuzzyThreshold is the minimum similarity for a typo to still count as a match.
+const fuzzyThreshold = 0.6
+
+type Result struct {
+	Item  monday.Item
+	Match Match
+	// Score is in [0, 3], the integer part being the Match kind and the
+	// fractional part how close a fuzzy match is.
+	Score float64
+}
+
+// Rank scores items against the searched value, drops contacts present on
+// several boards and returns them ordered by sort. The ordering is stable for
+// a given set of items, whatever the order they arrived in.
+func Rank(value string, items []monday.Item, sort Sort) []Result {
+	var results = make([]Result, 0, len(items))
+	for _, item := range items {
+		match, score := score(value, item)
+		results = append(results, Result{Item: item, Match: match, Score: score})
+	}
+	slices.SortStableFunc(results, func(a, b Result) int {
+		if sort == SORT_RELEVANCE && a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return compareItems(a.Item, b.Item)
+	})
+	return dedupe(results)
+}
+
+func compareItems(a, b monday.Item) int {
+	if c := strings.Compare(normalize(string(a.Name)), normalize(string(b.Name))); c != 0 {
+		return c
+	}
+	if c := strings.Compare(string(a.Board.Name), string(b.Board.Name)); c != 0 {
+		return c
+	}
+	return strings.Compare(fmt.Sprint(a.Id), fmt.Sprint(b.Id))
+}
+
+// dedupe keeps the first of the results describing the same contact, i.e.
+// having the same name, email and phone. Items with neither an email nor a
+// phone are all kept: two people can share a name.
+func dedupe(results []Result) []Result {
+	var seen = map[string]bool{}
+	var unique = results[:0]
+	for _, r := range results {
+		var email, phone = strings.ToLower(strings.TrimSpace(r.Item.Email())), digits(r.Item.Phone())
+		if email == "" && phone == "" {
+			unique = append(unique, r)
+			continue
+		}
+		var key = strings.Join([]string{normalize(string(r.Item.Name)), email, phone}, "\x00")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, r)
+	}
+	return unique
+}
+
+func score(value string, item monday.Item) (Match, float64) {
+	var query = normalize(value)
+	if query == "" {
+		return MATCH_NONE, 0
+	}
+	var best, bestScore = MATCH_NONE, 0.0
+	var consider = func(m Match, s float64) {
+		if s > bestScore {
+			best, bestScore = m, s
+		}
+	}
+	consider(matchText(query, normalize(string(item.Name))))
+	consider(matchText(query, strings.ToLower(strings.TrimSpace(item.Email()))))
+	if phoneQuery := digits(value); phoneQuery != "" {
+		consider(matchText(phoneQuery, digits(item.Phone())))
+	}
+	return best, bestScore
+}
+
+func matchText(query, field string) (Match, float64) {
+	if field == "" {
+		return MATCH_NONE, 0
+	}
+	if field == query {
+		return MATCH_EXACT, float64(MATCH_EXACT)
+	}
+	if strings.HasPrefix(field, query) || wordsPrefix(query, field) {
+		return MATCH_PREFIX, float64(MATCH_PREFIX)
+	}
+	// words in another order, e.g. "smith john", are as good as a close typo
+	var similarity = max(similarity(query, field), similarity(sortWords(query), sortWords(field)))
+	if strings.Contains(field, query) {
+		similarity = max(similarity, fuzzyThreshold)
+	}
+	if similarity < fuzzyThreshold {
+		return MATCH_NONE, 0
+	}
+	return MATCH_FUZZY, float64(MATCH_FUZZY) + similarity*0.99
+}
+
+// wordsPrefix reports whether every word of query starts a distinct word of
+// field, in order, e.g. "jo sm" against "john smith".
+func wordsPrefix(query, field string) bool {
+	var words = strings.Fields(field)
+	var i = 0
+	for _, q := range strings.Fields(query) {
+		for i < len(words) && !strings.HasPrefix(words[i], q) {
+			i++
+		}
+		if i == len(words) {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// similarity is 1 minus the edit distance normalized by the longest input.
+func similarity(a, b string) float64 {
+	var ra, rb = []rune(a), []rune(b)
+	var longest = max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	var prev = make([]int, len(b)+1)
+	var curr = make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			var cost = 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func sortWords(s string) string {
+	var words = strings.Fields(s)
+	slices.Sort(words)
+	return strings.Join(words, " ")
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// Collect drains items and ranks them. Ranking needs every result, so the
+// limit only applies to the ranked output.
+func Collect(value string, items <-chan monday.Item, sort Sort, limit int) []Result {
+	var all = []monday.Item{}
+	for item := range items {
+		all = append(all, item)
+	}
+	var results = Rank(value, all, sort)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func Items(results []Result) []monday.Item {
+	var items = make([]monday.Item, 0, len(results))
+	for _, r := range results {
+		items = append(items, r.Item)
+	}
+	return items
+}
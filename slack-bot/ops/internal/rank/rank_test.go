@@ -0,0 +1,143 @@
+package rank
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/shurcooL/graphql"
+)
+
+// contact is an item of board with the email and phone given, when not empty.
+func contact(id, name, board, email, phone string) monday.Item {
+	var item = monday.Item{Id: graphql.ID(id), Name: graphql.String(name), Board: monday.BoardRef{Id: graphql.ID(board), Name: graphql.String(board)}}
+	if email != "" {
+		var c = monday.ColumnValue{Id: "email"}
+		c.EmailValue.Email = graphql.String(email)
+		item.ColumnValues = append(item.ColumnValues, c)
+	}
+	if phone != "" {
+		var c = monday.ColumnValue{Id: "phone"}
+		c.PhoneValue.Phone = graphql.String(phone)
+		item.ColumnValues = append(item.ColumnValues, c)
+	}
+	return item
+}
+
+func ids(results []Result) []string {
+	var found = []string{}
+	for _, r := range results {
+		found = append(found, fmt.Sprint(r.Item.Id))
+	}
+	return found
+}
+
+func TestScore(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		item  monday.Item
+		want  Match
+	}{
+		{"John Smith", contact("1", "john  smith", "Clients", "", ""), MATCH_EXACT},
+		{"jane@example.com", contact("1", "Jane Doe", "Clients", "Jane@Example.com", ""), MATCH_EXACT},
+		{"+40 (721) 000-111", contact("1", "Jane Doe", "Clients", "", "0040721000111"), MATCH_FUZZY},
+		{"0721 000 111", contact("1", "Jane Doe", "Clients", "", "0721-000-111"), MATCH_EXACT},
+		{"jo", contact("1", "John Smith", "Clients", "", ""), MATCH_PREFIX},
+		{"jo sm", contact("1", "John Smith", "Clients", "", ""), MATCH_PREFIX},
+		{"smith john", contact("1", "John Smith", "Clients", "", ""), MATCH_FUZZY},
+		{"jhon smith", contact("1", "John Smith", "Clients", "", ""), MATCH_FUZZY},
+		{"smi", contact("1", "John Smith", "Clients", "", ""), MATCH_PREFIX},
+		{"mith", contact("1", "John Smith", "Clients", "", ""), MATCH_FUZZY},
+		{"alice", contact("1", "John Smith", "Clients", "", ""), MATCH_NONE},
+		{"  ", contact("1", "John Smith", "Clients", "", ""), MATCH_NONE},
+	} {
+		if match, _ := score(tc.value, tc.item); match != tc.want {
+			t.Errorf("score(%q, %s) = %s, want %s", tc.value, tc.item.Name, match, tc.want)
+		}
+	}
+}
+
+func TestRankOrder(t *testing.T) {
+	var items = []monday.Item{
+		contact("1", "Johnny Walker", "Clients", "", ""),
+		contact("2", "Jhon", "Clients", "", ""),
+		contact("3", "John", "Suppliers", "", ""),
+		contact("4", "Ann", "Clients", "", ""),
+		contact("5", "John", "Clients", "", ""),
+	}
+	for _, tc := range []struct {
+		sort Sort
+		want []string
+	}{
+		// ties, the two exact matches and the two items not matching, are
+		// ordered by name, then board
+		{SORT_RELEVANCE, []string{"5", "3", "1", "4", "2"}},
+		{SORT_NAME, []string{"4", "2", "5", "3", "1"}},
+	} {
+		var want = fmt.Sprint(tc.want)
+		if got := fmt.Sprint(ids(Rank("john", slices.Clone(items), tc.sort))); got != want {
+			t.Errorf("Rank(%s) = %s, want %s", tc.sort, got, want)
+		}
+		// the order the boards answered in does not matter
+		var reversed = slices.Clone(items)
+		slices.Reverse(reversed)
+		if got := fmt.Sprint(ids(Rank("john", reversed, tc.sort))); got != want {
+			t.Errorf("Rank(%s) of the reversed items = %s, want %s", tc.sort, got, want)
+		}
+	}
+}
+
+func TestRankDedupe(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		items []monday.Item
+		want  []string
+	}{
+		{
+			name: "same contact on two boards",
+			items: []monday.Item{
+				contact("1", "Ann Lee", "Suppliers", "ann@example.com", "0721 000 111"),
+				contact("2", "ann  lee", "Clients", "Ann@Example.com", "0721-000-111"),
+			},
+			want: []string{"2"},
+		},
+		{
+			name: "same name and email",
+			items: []monday.Item{
+				contact("1", "Ann Lee", "Clients", "ann@example.com", ""),
+				contact("2", "Ann Lee", "Suppliers", "ann@example.com", ""),
+			},
+			want: []string{"1"},
+		},
+		{
+			name: "same name and phone",
+			items: []monday.Item{
+				contact("1", "Ann Lee", "Clients", "", "0721 000 111"),
+				contact("2", "Ann Lee", "Suppliers", "", "0721000111"),
+			},
+			want: []string{"1"},
+		},
+		{
+			name: "same name, other email",
+			items: []monday.Item{
+				contact("1", "Ann Lee", "Clients", "ann@example.com", ""),
+				contact("2", "Ann Lee", "Clients", "ann.lee@example.com", ""),
+			},
+			want: []string{"1", "2"},
+		},
+		{
+			name: "same name, no contact details",
+			items: []monday.Item{
+				contact("1", "Ann Lee", "Clients", "", ""),
+				contact("2", "Ann Lee", "Clients", "", ""),
+				contact("3", "Ann Lee", "Suppliers", "", ""),
+			},
+			want: []string{"1", "2", "3"},
+		},
+	} {
+		if got := ids(Rank("ann lee", tc.items, SORT_RELEVANCE)); fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("%s: Rank() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
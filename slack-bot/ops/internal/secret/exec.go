@@ -0,0 +1,88 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// execTimeout bounds how long a credential helper may take.
+const execTimeout = 30 * time.Second
+
+type execSource struct {
+	command []string
+	refresh time.Duration
+	mu      sync.Mutex
+	token   string
+	expiry  time.Time
+}
+
+// Exec runs command, a credential helper such as `op read ...` or
+// `vault kv get -field=token ...`, for the token and reuses it for refresh.
+// The helper prints either the token or a JSON object with a token and,
+// optionally, an RFC 3339 expires_at, which shortens refresh.
+func Exec(command []string, refresh time.Duration) Source {
+	return &execSource{command: command, refresh: refresh}
+}
+
+// helperOutput is the JSON a credential helper may print.
+type helperOutput struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (s *execSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Before(s.expiry) {
+		return &oauth2.Token{AccessToken: s.token}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+	defer cancel()
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.command[0], s.command[1:]...)
+	cmd.Stdout = &stdout
+	// the output is not put in the error, it may hold the token
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("credential helper %s failed: %s", s.command[0], exitErr.ProcessState)
+		}
+		return nil, fmt.Errorf("failed to run credential helper %s: %w", s.command[0], err)
+	}
+	var output = helperOutput{Token: strings.TrimSpace(stdout.String())}
+	if strings.HasPrefix(output.Token, "{") {
+		output = helperOutput{}
+		if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+			return nil, fmt.Errorf("credential helper %s printed invalid JSON", s.command[0])
+		}
+	}
+	if output.Token == "" {
+		return nil, fmt.Errorf("credential helper %s printed no token", s.command[0])
+	}
+	s.token = output.Token
+	s.expiry = time.Now().Add(s.refresh)
+	if !output.ExpiresAt.IsZero() && output.ExpiresAt.Before(s.expiry) {
+		s.expiry = output.ExpiresAt
+	}
+	return &oauth2.Token{AccessToken: s.token}, nil
+}
+
+func (s *execSource) Forget() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+// String leaves the arguments out, in case one of them is sensitive.
+func (s *execSource) String() string {
+	return "credential helper " + s.command[0]
+}
@@ -0,0 +1,83 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+type fileSource struct {
+	path    string
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+// File reads the token from the file at path, again whenever the file
+// changes. Surrounding whitespace is ignored.
+func File(path string) Source {
+	return &fileSource{path: path}
+}
+
+func (s *fileSource) Token() (*oauth2.Token, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return &oauth2.Token{AccessToken: s.token}, nil
+	}
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+	var token = strings.TrimSpace(string(content))
+	if token == "" {
+		return nil, fmt.Errorf("no token in %s", s.path)
+	}
+	s.token, s.modTime, s.size = token, info.ModTime(), info.Size()
+	return &oauth2.Token{AccessToken: token}, nil
+}
+
+func (s *fileSource) Forget() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+func (s *fileSource) String() string {
+	return "file " + s.path
+}
+
+// writeFile keeps token in the file at path, readable by the owner only.
+// The file is replaced at once, so sources reading it never see half a
+// token.
+func writeFile(path, token string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create the directory of %s: %w", path, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".token-*")
+	if err != nil {
+		return fmt.Errorf("failed to write token to %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(token + "\n"); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write token to %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write token to %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write token to %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+)
+
+type keyringSource struct {
+	service  string
+	user     string
+	refresh  time.Duration
+	fallback Source
+	warned   sync.Once
+	mu       sync.Mutex
+	token    string
+	expiry   time.Time
+}
+
+// Keyring reads the token kept for user of service in the OS keyring
+// (Keychain, Windows Credential Manager or the Secret Service), again after
+// refresh. Where there is no keyring, e.g. on a headless server, the token
+// is read from the fallback file instead.
+func Keyring(service, user string, refresh time.Duration, fallback string) Source {
+	return &keyringSource{service: service, user: user, refresh: refresh, fallback: File(fallback)}
+}
+
+func (s *keyringSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Before(s.expiry) {
+		return &oauth2.Token{AccessToken: s.token}, nil
+	}
+	token, err := keyring.Get(s.service, s.user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("no token for %s in the %s keyring, store one with `ops token store`", s.user, s.service)
+	}
+	if err != nil {
+		s.warned.Do(func() {
+			log.Printf("No %s keyring (%s), reading the token from %s", s.service, err, s.fallback)
+		})
+		return s.fallback.Token()
+	}
+	s.token, s.expiry = token, time.Now().Add(s.refresh)
+	return &oauth2.Token{AccessToken: token}, nil
+}
+
+func (s *keyringSource) Forget() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+	s.fallback.Forget()
+}
+
+func (s *keyringSource) String() string {
+	return fmt.Sprintf("keyring %s/%s (or %s)", s.service, s.user, s.fallback)
+}
+
+// Store keeps token for user of service in the OS keyring, or in the
+// fallback file where there is no keyring. It returns where token was kept.
+func Store(service, user, fallback, token string) (string, error) {
+	err := keyring.Set(service, user, token)
+	if err == nil {
+		return fmt.Sprintf("keyring %s/%s", service, user), nil
+	}
+	if err := writeFile(fallback, token); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("file %s, there is no keyring (%s)", fallback, err), nil
+}
@@ -0,0 +1,74 @@
+// Package secret provides the monday API token from wherever it is kept:
+// an environment variable, a file, a credential helper or the OS keyring.
+// Sources are asked for the token on every request, so a rotated token is
+// used without a restart. The token itself is never logged nor put in an
+// error; sources describe themselves by where they read from.
+package secret
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	SOURCE_ENV     = "env"
+	SOURCE_FILE    = "file"
+	SOURCE_EXEC    = "exec"
+	SOURCE_KEYRING = "keyring"
+)
+
+// Source is an oauth2.TokenSource that can be told the token it gave was
+// refused, e.g. because it was rotated, and that describes where it reads
+// the token from.
+type Source interface {
+	oauth2.TokenSource
+	// Forget drops the token kept, if any, so the next call reads it again.
+	Forget()
+	String() string
+}
+
+type envSource struct {
+	name string
+}
+
+// Env reads the token from the environment variable name on every call.
+func Env(name string) Source {
+	return envSource{name: name}
+}
+
+func (s envSource) Token() (*oauth2.Token, error) {
+	var token = os.Getenv(s.name)
+	if token == "" {
+		return nil, fmt.Errorf("no token in %s", s.name)
+	}
+	return &oauth2.Token{AccessToken: token}, nil
+}
+
+func (s envSource) Forget() {}
+
+func (s envSource) String() string {
+	return "environment variable " + s.name
+}
+
+type staticSource struct {
+	token string
+	name  string
+}
+
+// Static always gives token, e.g. one obtained by installing ops in a monday
+// account. name tells where it comes from.
+func Static(token, name string) Source {
+	return staticSource{token: token, name: name}
+}
+
+func (s staticSource) Token() (*oauth2.Token, error) {
+	return &oauth2.Token{AccessToken: s.token}, nil
+}
+
+func (s staticSource) Forget() {}
+
+func (s staticSource) String() string {
+	return s.name
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/oauth"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/tenant"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/rpcauth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UseAccounts serves the callers of every Slack team that installed ops in
+// a monday account from that account. The other callers are served by the
+// client of the server.
+func (s *Server) UseAccounts(accounts *oauth.Accounts) {
+	s.accounts = accounts
+}
+
+// UseTenants serves every call from the tenant of registry it names, or the
+// one of its Slack team, each with its own client and policy. Calls without
+// a tenant are refused, and the client of the server, the index and the
+// mirror are no longer used.
+func (s *Server) UseTenants(registry *tenant.Registry) {
+	s.tenants = registry
+}
+
+// backend is what a call is served from.
+type backend struct {
+	client *monday.ApiClient
+	policy *authz.Policy
+	// local is set for the client of the server, the only one the index and
+	// the mirror hold the boards of
+	local bool
+}
+
+// backendFor returns the backend of the tenant of the call, if the server
+// has tenants, or the client of the account the team of the caller
+// installed ops in, the client of the server when there is none.
+func (s *Server) backendFor(ctx context.Context) (backend, error) {
+	if s.tenants != nil {
+		t, err := s.tenants.Admit(ctx)
+		switch {
+		case errors.Is(err, tenant.ErrNoTenant):
+			return backend{}, status.Errorf(codes.InvalidArgument, "%s, set %s", err, authz.TENANT_KEY)
+		case errors.Is(err, tenant.ErrUnknownTenant), errors.Is(err, tenant.ErrClientNotAllowed):
+			return backend{}, status.Error(codes.PermissionDenied, err.Error())
+		case errors.Is(err, tenant.ErrRateLimited):
+			return backend{}, status.Error(codes.ResourceExhausted, err.Error())
+		case err != nil:
+			return backend{}, status.Error(codes.Internal, err.Error())
+		}
+		var policy = t.Policy
+		if policy == nil {
+			policy = s.policy
+		}
+		return backend{client: t.Client, policy: policy}, nil
+	}
+	if s.accounts == nil {
+		return backend{client: s.client, policy: s.policy, local: true}, nil
+	}
+	client, err := s.accounts.Client(ctx, authz.FromIncomingContext(ctx).Team)
+	if errors.Is(err, oauth.ErrNotInstalled) {
+		return backend{client: s.client, policy: s.policy, local: true}, nil
+	}
+	if err != nil {
+		return backend{}, status.Errorf(codes.Unavailable, "failed to find the monday account: %s", err)
+	}
+	return backend{client: client, policy: s.policy}, nil
+}
+
+// callerScope is who the idempotency keys and operations of the call
+// belong to: the client, its tenant and its Slack team, for callers to never
+// replay or revert each other's.
+func (s *Server) callerScope(ctx context.Context) string {
+	var tenantId string
+	if s.tenants != nil {
+		if t, err := s.tenants.Lookup(ctx); err == nil {
+			tenantId = t.Id
+		}
+	}
+	return strings.Join([]string{rpcauth.ClientFromContext(ctx), tenantId, authz.FromIncomingContext(ctx).Team}, "/")
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/audit"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/rpcauth"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// record appends the call of method to the audit log, if any. It is
+// deferred at the start of the call, outcome giving the board, the item and
+// the error the call ended with.
+func (s *Server) record(ctx context.Context, method string, req proto.Message, start time.Time, outcome func() (board, itemId string, err error)) {
+	// dry runs change nothing
+	if dryRun, ok := req.(interface{ GetDryRun() bool }); s.audit == nil || ok && dryRun.GetDryRun() {
+		return
+	}
+	board, itemId, err := outcome()
+	var caller = authz.FromIncomingContext(ctx)
+	request, _ := protojson.Marshal(req)
+	var record = audit.Record{
+		Time:     start,
+		Method:   method,
+		Client:   rpcauth.ClientFromContext(ctx),
+		User:     caller.User,
+		Groups:   caller.Groups,
+		Channel:  caller.Channel,
+		Board:    board,
+		ItemId:   itemId,
+		Request:  request,
+		Outcome:  audit.OUTCOME_OK,
+		Duration: time.Since(start),
+	}
+	if s.tenants != nil {
+		if t, err := s.tenants.Lookup(ctx); err == nil {
+			record.Tenant = t.Id
+		}
+	}
+	if err != nil {
+		var st = status.Convert(err)
+		record.Outcome = st.Code().String()
+		record.Error = st.Message()
+	}
+	// the change is done whether or not it can be recorded, the caller is
+	// not the one to tell
+	if err := s.audit.Append(context.WithoutCancel(ctx), record); err != nil {
+		log.Println(fmt.Errorf("failed to audit %s: %w", method, err))
+	}
+}
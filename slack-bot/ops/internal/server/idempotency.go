@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/idempotency"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// UseIdempotency creates the item of a CreateItem once per idempotency key,
+// keeping the keys in store. Requests without a key create an item each.
+func (s *Server) UseIdempotency(store *idempotency.Store) {
+	s.idempotency = store
+}
+
+// createOnce calls create once for the idempotency key of req, when it has
+// one and the server keeps the keys. Dry runs create nothing to remember.
+// create must make its calls with the ctx it gets, which outlives the
+// caller's when a key is kept.
+func (s *Server) createOnce(ctx context.Context, req *pb.CreateItemRequest, create func(ctx context.Context) (string, error)) (id string, replayed bool, err error) {
+	if s.idempotency == nil || req.IdempotencyKey == "" || req.DryRun {
+		id, err = create(ctx)
+		return id, false, err
+	}
+	id, replayed, err = s.idempotency.Do(ctx, s.callerScope(ctx), req.IdempotencyKey, fingerprint(req), create)
+	switch {
+	case errors.Is(err, idempotency.ErrKeyReused):
+		return "", false, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, idempotency.ErrInProgress):
+		return "", false, status.Error(codes.Aborted, err.Error())
+	}
+	return id, replayed, err
+}
+
+// fingerprint identifies what req asks for, its key aside, for a key to not
+// be reused with another request.
+func fingerprint(req *pb.CreateItemRequest) string {
+	var asked = proto.Clone(req).(*pb.CreateItemRequest)
+	asked.IdempotencyKey = ""
+	content, _ := proto.MarshalOptions{Deterministic: true}.Marshal(asked)
+	var sum = sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
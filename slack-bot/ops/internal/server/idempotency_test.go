@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/idempotency"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday/mondaytest"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateItemOutlivesCanceledCaller(t *testing.T) {
+	var fake = mondaytest.New(t, clients)
+	var creating, proceed = make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	fake.Hook = func(r mondaytest.Request) error {
+		if strings.Contains(r.Query, "create_item(") {
+			once.Do(func() { close(creating) })
+			<-proceed
+		}
+		return nil
+	}
+	store, err := idempotency.OpenStore(filepath.Join(t.TempDir(), "keys.db"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	var s = New(newClient(fake))
+	s.UseIdempotency(store)
+	var client = serve(t, s)
+	var req = &pb.CreateItemRequest{Board: "Clients", Group: "Leads", Name: "Ann", IdempotencyKey: "key-1"}
+
+	// the caller gives up while monday is creating the item
+	ctx, cancel := context.WithCancel(context.Background())
+	var first = make(chan error)
+	go func() {
+		_, err := client.CreateItem(ctx, req)
+		first <- err
+	}()
+	<-creating
+	cancel()
+	if err := <-first; status.Code(err) != codes.Canceled {
+		t.Fatalf("canceled CreateItem: err = %v", err)
+	}
+	close(proceed)
+
+	var resp *pb.CreateItemResponse
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		resp, err = client.CreateItem(context.Background(), req)
+		if status.Code(err) != codes.Aborted || time.Now().After(deadline) {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created = fake.Items()
+	if n := len(fake.Requests("create_item")); n != 1 || len(created) != 1 {
+		t.Fatalf("%d create_item requests made %d items, want the retry to get the first one", n, len(created))
+	}
+	if !resp.Replayed || resp.Id != created[0].Id {
+		t.Errorf("retry answered %v, want item %s replayed", resp, created[0].Id)
+	}
+}
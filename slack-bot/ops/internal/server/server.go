@@ -1 +1,520 @@
 package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/audit"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/idempotency"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/index"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/mirror"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/oauth"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/rank"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/tenant"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/undo"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Server struct {
+	pb.UnimplementedMondayServiceServer
+	client *monday.ApiClient
+	index  *index.Index
+	mirror *mirror.Mirror
+	policy *authz.Policy
+	audit  audit.Log
+	// accounts, if any, serve the teams that installed ops in their account
+	accounts *oauth.Accounts
+	// tenants, if any, serve every call instead of the client and accounts
+	tenants *tenant.Registry
+	// idempotency, if any, keeps the items created for idempotency keys
+	idempotency *idempotency.Store
+	// undo, if any, keeps the changes of the last while for Revert
+	undo *undo.Store
+}
+
+func New(client *monday.ApiClient) *Server {
+	return &Server{client: client}
+}
+
+// UseIndex serves name, email and phone lookups from idx instead of monday,
+// unless a request asks for a live search.
+func (s *Server) UseIndex(idx *index.Index) {
+	s.index = idx
+}
+
+// UseMirror serves searches from m whenever monday cannot be queried.
+func (s *Server) UseMirror(m *mirror.Mirror) {
+	s.mirror = m
+}
+
+// UsePolicy only lets callers work with the boards policy allows them to,
+// the caller being the one forwarded in the request metadata. Tenants with
+// a policy of their own follow theirs instead.
+func (s *Server) UsePolicy(policy *authz.Policy) {
+	s.policy = policy
+}
+
+// UseAudit records every change asked of the server in log.
+func (s *Server) UseAudit(log audit.Log) {
+	s.audit = log
+}
+
+func (s *Server) FindItem(req *pb.FindItemRequest, stream grpc.ServerStreamingServer[pb.FindItemResponse]) error {
+	if req.Column == "" || req.Value == "" {
+		return status.Error(codes.InvalidArgument, "column and value are required")
+	}
+	var params = monday.ItemsQuery{
+		Rules: []monday.ItemsQueryRule{
+			{
+				ColumnId:     req.Column,
+				CompareValue: monday.CompareValue(req.Value),
+				Operator:     monday.CONTAINS_TEXT,
+			},
+		},
+		Operator: "and",
+	}
+	var sort = toSort(req.Sort)
+	var limit = int(req.Limit)
+	var caller = authz.FromIncomingContext(stream.Context())
+	b, err := s.backendFor(stream.Context())
+	if err != nil {
+		return err
+	}
+	if sort != rank.SORT_NONE || b.policy != nil {
+		// ranking needs every match and the caller may not see them all, the
+		// limit applies to the items sent
+		limit = 0
+	}
+	// the index and the mirror search every board, the limit applies once
+	// the other boards are dropped
+	var boards []string
+	var localLimit = limit
+	if req.Board != "" {
+		boards, localLimit = []string{req.Board}, 0
+	}
+	if b.local && s.index != nil && !req.Live {
+		items, err := s.index.Search(stream.Context(), req.Column, req.Value, localLimit)
+		if err == nil {
+			syncedAt, _ := s.index.LastSync(stream.Context())
+			return sendLocal(stream, req, b.visible(caller, onBoard(items, req.Board)), pb.Source_SOURCE_INDEX, syncedAt)
+		}
+		slog.Debug("Falling back to a live search", "reason", err)
+	}
+	// the stream context is done as soon as the client goes away, which in
+	// turn stops the search of the remaining boards
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	search, err := b.client.GetItemsInBoards(ctx, boards, params, limit)
+	if errors.Is(err, monday.ErrBoardNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		if b.local && s.mirror != nil {
+			found, syncedAt, mirrorErr := s.mirror.Search(stream.Context(), req.Column, req.Value, localLimit)
+			if mirrorErr == nil {
+				slog.Debug("Serving search from the mirror", "reason", err, "syncedAt", syncedAt)
+				return sendLocal(stream, req, b.visible(caller, onBoard(found, req.Board)), pb.Source_SOURCE_MIRROR, syncedAt)
+			}
+			slog.Debug(fmt.Errorf("failed to search mirror: %w", mirrorErr).Error())
+		}
+		return status.Errorf(codes.Unavailable, "failed to search items: %s", err)
+	}
+	var found = b.visibleStream(ctx, caller, search.Items)
+	if sort == rank.SORT_NONE {
+		var sent = 0
+		for item := range found {
+			if err := stream.Send(toFindItemResponse(item)); err != nil {
+				return err
+			}
+			if sent++; req.Limit > 0 && sent == int(req.Limit) {
+				return nil
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		stale, syncedAt, err := s.searchFailed(ctx, b, caller, req, search.Err())
+		if err != nil {
+			return err
+		}
+		if req.Limit > 0 && len(stale) > int(req.Limit)-sent {
+			stale = stale[:int(req.Limit)-sent]
+		}
+		return sendLocal(stream, req, stale, pb.Source_SOURCE_MIRROR, syncedAt)
+	}
+	var results = rank.Collect(req.Value, found, sort, int(req.Limit))
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	stale, syncedAt, err := s.searchFailed(ctx, b, caller, req, search.Err())
+	if err != nil {
+		return err
+	}
+	var fromMirror = map[string]bool{}
+	if len(stale) > 0 {
+		for _, item := range stale {
+			fromMirror[fmt.Sprint(item.Id)] = true
+		}
+		results = rank.Rank(req.Value, append(rank.Items(results), stale...), sort)
+		if req.Limit > 0 && len(results) > int(req.Limit) {
+			results = results[:req.Limit]
+		}
+	}
+	for _, result := range results {
+		var resp = toFindItemResponse(result.Item)
+		if fromMirror[fmt.Sprint(result.Item.Id)] {
+			resp.Source = pb.Source_SOURCE_MIRROR
+			if !syncedAt.IsZero() {
+				resp.SyncedAt = timestamppb.New(syncedAt)
+			}
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchFailed finds in the mirror the items of the boards a live search
+// failed to search, as told by err, for them to be sent marked as stale.
+// It fails with Unavailable when there is no mirror to fall back to.
+func (s *Server) searchFailed(ctx context.Context, b backend, caller authz.Caller, req *pb.FindItemRequest, err error) ([]monday.Item, time.Time, error) {
+	if err == nil {
+		return nil, time.Time{}, nil
+	}
+	if !b.local || s.mirror == nil {
+		return nil, time.Time{}, status.Errorf(codes.Unavailable, "failed to search items: %s", err)
+	}
+	found, syncedAt, mirrorErr := s.mirror.Search(ctx, req.Column, req.Value, 0)
+	if mirrorErr != nil {
+		return nil, time.Time{}, status.Errorf(codes.Unavailable, "failed to search items: %s, nor the mirror: %s", err, mirrorErr)
+	}
+	var failed = map[string]bool{}
+	for _, board := range monday.FailedBoards(err) {
+		failed[fmt.Sprint(board.Id)] = true
+	}
+	slog.Debug("Serving the boards that failed from the mirror", "reason", err, "syncedAt", syncedAt)
+	found = slices.DeleteFunc(found, func(item monday.Item) bool { return !failed[fmt.Sprint(item.Board.Id)] })
+	return b.visible(caller, found), syncedAt, nil
+}
+
+// onBoard drops the items of the boards other than board, when not empty.
+func onBoard(items []monday.Item, board string) []monday.Item {
+	if board == "" {
+		return items
+	}
+	return slices.DeleteFunc(items, func(item monday.Item) bool {
+		return !strings.EqualFold(string(item.Board.Name), board)
+	})
+}
+
+// visible drops the items of the boards the caller may not search.
+func (b backend) visible(caller authz.Caller, items []monday.Item) []monday.Item {
+	if b.policy == nil {
+		return items
+	}
+	return slices.DeleteFunc(items, func(item monday.Item) bool {
+		return !b.policy.Allowed(caller, string(item.Board.Name), authz.FIND)
+	})
+}
+
+// visibleStream is visible for the items of a live search. It stops when
+// ctx is done.
+func (b backend) visibleStream(ctx context.Context, caller authz.Caller, items chan monday.Item) <-chan monday.Item {
+	if b.policy == nil {
+		return items
+	}
+	var found = make(chan monday.Item)
+	go func() {
+		defer close(found)
+		for item := range items {
+			if !b.policy.Allowed(caller, string(item.Board.Name), authz.FIND) {
+				continue
+			}
+			select {
+			case found <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return found
+}
+
+// authorize fails with PermissionDenied unless the caller of the request
+// may do op on board.
+func (b backend) authorize(ctx context.Context, board string, op authz.Operation) error {
+	if err := b.policy.Check(authz.FromIncomingContext(ctx), board, op); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
+
+// authorizeItem is authorize for the board of the item with id, which it
+// returns. The board is only looked up when there is a policy to check or an
+// audit log to record it in.
+func (s *Server) authorizeItem(ctx context.Context, b backend, id string, op authz.Operation) (string, error) {
+	if b.policy == nil && s.audit == nil {
+		return "", nil
+	}
+	items, err := b.client.GetItemsByIds(ctx, id)
+	if err != nil {
+		return "", status.Errorf(codes.Unavailable, "failed to look up item %s: %s", id, err)
+	}
+	if len(items) == 0 {
+		return "", status.Errorf(codes.NotFound, "%s: %s", monday.ErrItemNotFound, id)
+	}
+	var board = string(items[0].Board.Name)
+	return board, b.authorize(ctx, board, op)
+}
+
+// sendLocal streams items found in the index or the mirror, marking them
+// with where they come from and how fresh that copy is.
+func sendLocal(stream grpc.ServerStreamingServer[pb.FindItemResponse], req *pb.FindItemRequest, items []monday.Item, source pb.Source, syncedAt time.Time) error {
+	if sort := toSort(req.Sort); sort != rank.SORT_NONE {
+		items = rank.Items(rank.Rank(req.Value, items, sort))
+	}
+	if req.Limit > 0 && len(items) > int(req.Limit) {
+		items = items[:req.Limit]
+	}
+	for _, item := range items {
+		var resp = toFindItemResponse(item)
+		resp.Source = source
+		if !syncedAt.IsZero() {
+			resp.SyncedAt = timestamppb.New(syncedAt)
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) CreateItem(ctx context.Context, req *pb.CreateItemRequest) (resp *pb.CreateItemResponse, err error) {
+	var board, group string
+	defer s.record(ctx, "CreateItem", req, time.Now(), func() (string, string, error) { return board, resp.GetId(), err })
+	b, err := s.backendFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// items go to the default board, if any, when no board is given
+	board, group = b.client.ResolveBoard(req.Board, req.Group)
+	if board == "" || req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "board and name are required")
+	}
+	if err := b.authorize(ctx, board, authz.ADD); err != nil {
+		return nil, err
+	}
+	var request = monday.CreateItemRequest{
+		BoardName:      strings.ToLower(board),
+		GroupName:      strings.ToLower(group),
+		Name:           req.Name,
+		Email:          req.Email,
+		Phone:          req.Phone,
+		Columns:        req.Columns,
+		IdempotencyKey: req.IdempotencyKey,
+	}
+	ctx, dryRun := dryRunContext(ctx, req.DryRun)
+	id, replayed, err := s.createOnce(ctx, req, func(ctx context.Context) (string, error) {
+		return b.client.CreateItem(ctx, request)
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create item: %s", err)
+	}
+	resp = &pb.CreateItemResponse{Id: id, DryRun: toMutation(dryRun), Replayed: replayed}
+	if !replayed {
+		resp.OperationId = s.remember(ctx, undo.Operation{Method: undo.METHOD_CREATE_ITEM, Board: board, ItemId: id})
+	}
+	return resp, nil
+}
+
+func (s *Server) UpdateItem(ctx context.Context, req *pb.UpdateItemRequest) (resp *pb.UpdateItemResponse, err error) {
+	var board string
+	defer s.record(ctx, "UpdateItem", req, time.Now(), func() (string, string, error) { return board, req.Id, err })
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	b, err := s.backendFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if board, err = s.authorizeItem(ctx, b, req.Id, authz.UPDATE); err != nil {
+		return nil, err
+	}
+	var request = monday.UpdateItemRequest{
+		ItemId:  req.Id,
+		Name:    req.Name,
+		Columns: req.Columns,
+	}
+	snapshot, err := s.snapshot(ctx, b, request, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if board == "" {
+		board = snapshot.Board
+	}
+	ctx, dryRun := dryRunContext(ctx, req.DryRun)
+	if err := b.client.UpdateItem(ctx, request); err != nil {
+		if errors.Is(err, monday.ErrItemNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update item: %s", err)
+	}
+	var operationId = s.remember(ctx, undo.Operation{Method: undo.METHOD_UPDATE_ITEM, Board: board, ItemId: req.Id, Snapshot: snapshot})
+	return &pb.UpdateItemResponse{Id: req.Id, DryRun: toMutation(dryRun), OperationId: operationId}, nil
+}
+
+func (s *Server) ArchiveItem(ctx context.Context, req *pb.ArchiveItemRequest) (resp *pb.ArchiveItemResponse, err error) {
+	var board string
+	defer s.record(ctx, "ArchiveItem", req, time.Now(), func() (string, string, error) { return board, req.Id, err })
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	b, err := s.backendFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if board, err = s.authorizeItem(ctx, b, req.Id, authz.DELETE); err != nil {
+		return nil, err
+	}
+	ctx, dryRun := dryRunContext(ctx, req.DryRun)
+	if err := b.client.ArchiveItem(ctx, req.Id); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to archive item: %s", err)
+	}
+	s.remember(ctx, undo.Operation{Method: undo.METHOD_ARCHIVE_ITEM, Board: board, ItemId: req.Id})
+	return &pb.ArchiveItemResponse{Id: req.Id, DryRun: toMutation(dryRun)}, nil
+}
+
+func (s *Server) AddNote(ctx context.Context, req *pb.AddNoteRequest) (resp *pb.AddNoteResponse, err error) {
+	var board string
+	defer s.record(ctx, "AddNote", req, time.Now(), func() (string, string, error) { return board, req.ItemId, err })
+	if req.ItemId == "" || strings.TrimSpace(req.Body) == "" {
+		return nil, status.Error(codes.InvalidArgument, "item_id and body are required")
+	}
+	b, err := s.backendFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if board, err = s.authorizeItem(ctx, b, req.ItemId, authz.UPDATE); err != nil {
+		return nil, err
+	}
+	ctx, dryRun := dryRunContext(ctx, req.DryRun)
+	id, err := b.client.AddNote(ctx, req.ItemId, req.Body)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to add note: %s", err)
+	}
+	var operationId = s.remember(ctx, undo.Operation{Method: undo.METHOD_ADD_NOTE, Board: board, ItemId: req.ItemId, UpdateId: id})
+	return &pb.AddNoteResponse{Id: id, DryRun: toMutation(dryRun), OperationId: operationId}, nil
+}
+
+// dryRunContext records the mutations made with ctx rather than sending
+// them when a dry run is asked for.
+func dryRunContext(ctx context.Context, asked bool) (context.Context, *monday.DryRun) {
+	if !asked {
+		return ctx, nil
+	}
+	return monday.WithDryRun(ctx)
+}
+
+// toMutation is the last mutation dryRun recorded, nil when it recorded
+// none or it is nil.
+func toMutation(dryRun *monday.DryRun) *pb.Mutation {
+	if dryRun == nil {
+		return nil
+	}
+	var m = dryRun.Last()
+	if m == nil {
+		return nil
+	}
+	return &pb.Mutation{Operation: m.Operation, Query: m.Query, Variables: string(m.Variables)}
+}
+
+// DescribeBoard only describes the boards the caller may search.
+func (s *Server) DescribeBoard(ctx context.Context, req *pb.DescribeBoardRequest) (*pb.DescribeBoardResponse, error) {
+	b, err := s.backendFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Board != "" {
+		if err := b.authorize(ctx, req.Board, authz.FIND); err != nil {
+			return nil, err
+		}
+	}
+	boards, err := b.client.DescribeBoards(ctx, req.Board)
+	if err != nil {
+		slog.Debug(fmt.Errorf("failed to describe boards: %w", err).Error())
+		if errors.Is(err, monday.ErrBoardNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Errorf(codes.Unavailable, "failed to describe boards: %s", err)
+	}
+	var caller = authz.FromIncomingContext(ctx)
+	var resp = &pb.DescribeBoardResponse{}
+	for _, board := range boards {
+		if !b.policy.Allowed(caller, board.Name, authz.FIND) {
+			continue
+		}
+		resp.Boards = append(resp.Boards, toBoardDescription(board))
+	}
+	return resp, nil
+}
+
+func toFindItemResponse(item monday.Item) *pb.FindItemResponse {
+	var resp = &pb.FindItemResponse{
+		Id:    fmt.Sprint(item.Id),
+		Name:  string(item.Name),
+		Group: string(item.Group.Title),
+		Board: string(item.Board.Name),
+		Email: item.Email(),
+		Phone: item.Phone(),
+		Url:   string(item.Url),
+	}
+	for _, c := range item.ColumnValues {
+		resp.Columns = append(resp.Columns, &pb.Column{
+			Id:    fmt.Sprint(c.Id),
+			Value: string(c.Text),
+			Meta:  &pb.ColumnMeta{Id: fmt.Sprint(c.Id)},
+		})
+	}
+	return resp
+}
+
+func toSort(sort pb.Sort) rank.Sort {
+	switch sort {
+	case pb.Sort_SORT_RELEVANCE:
+		return rank.SORT_RELEVANCE
+	case pb.Sort_SORT_NAME:
+		return rank.SORT_NAME
+	}
+	return rank.SORT_NONE
+}
+
+func toBoardDescription(b monday.BoardDescription) *pb.BoardDescription {
+	var desc = &pb.BoardDescription{
+		Id:          b.Id,
+		Name:        b.Name,
+		Description: b.Description,
+		Kind:        b.Kind,
+	}
+	for _, c := range b.Columns {
+		desc.Columns = append(desc.Columns, &pb.ColumnMeta{Id: c.Id, Title: c.Title, Type: c.Type, Labels: c.Labels})
+	}
+	for _, g := range b.Groups {
+		desc.Groups = append(desc.Groups, &pb.GroupMeta{Id: g.Id, Title: g.Title})
+	}
+	return desc
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/mirror"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday/mondaytest"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/secret"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var contactColumns = []mondaytest.Column{
+	{Id: "name", Title: "Name", Type: "name"},
+	{Id: "email", Title: "Email", Type: "email"},
+	{Id: "phone", Title: "Phone", Type: "phone"},
+}
+
+var clients = mondaytest.Board{Id: "10", Name: "Clients", Columns: contactColumns, Groups: []mondaytest.Group{{Id: "g1", Title: "Leads"}}}
+
+func newClient(fake *mondaytest.Server) *monday.ApiClient {
+	return monday.New(fake.URL(), secret.Static("token", "test"))
+}
+
+// serve serves s in memory until the end of the test and returns a client
+// calling it.
+func serve(t *testing.T, s *Server) pb.MondayServiceClient {
+	var listener = bufconn.Listen(1 << 20)
+	var server = grpc.NewServer()
+	pb.RegisterMondayServiceServer(server, s)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewMondayServiceClient(conn)
+}
+
+// find returns what FindItem answers req with, until the error ending it.
+func find(t *testing.T, client pb.MondayServiceClient, req *pb.FindItemRequest) ([]*pb.FindItemResponse, error) {
+	stream, err := client.FindItem(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found = []*pb.FindItemResponse{}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return found, nil
+		}
+		if err != nil {
+			return found, err
+		}
+		found = append(found, resp)
+	}
+}
+
+// failingBoard is a fake whose searches of the Suppliers board are rate
+// limited, an Ann being on each board.
+func failingBoard(t *testing.T) *mondaytest.Server {
+	var fake = mondaytest.New(t, clients, mondaytest.Board{Id: "20", Name: "Suppliers", Columns: contactColumns})
+	fake.AddItem(mondaytest.Item{Name: "Ann Client", Board: "10"})
+	fake.AddItem(mondaytest.Item{Name: "Ann Supplier", Board: "20"})
+	return fake
+}
+
+func failSuppliers(r mondaytest.Request) error {
+	if strings.Contains(r.Query, "query_params") && r.Variables["ids"] == "20" {
+		return errors.New("Rate limit exceeded")
+	}
+	return nil
+}
+
+func TestFindItemServesFailedBoardsFromMirror(t *testing.T) {
+	var fake = failingBoard(t)
+	var client = newClient(fake)
+	m, err := mirror.Open(filepath.Join(t.TempDir(), "mirror.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if err := mirror.NewSyncer(client, m, nil).Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	fake.Hook = failSuppliers
+	var s = New(client)
+	s.UseMirror(m)
+
+	for _, sort := range []pb.Sort{pb.Sort_SORT_NONE, pb.Sort_SORT_RELEVANCE} {
+		found, err := find(t, serve(t, s), &pb.FindItemRequest{Column: "Name", Value: "ann", Live: true, Sort: sort})
+		if err != nil {
+			t.Fatalf("sort %s: %v", sort, err)
+		}
+		var sources = map[string]pb.Source{}
+		for _, resp := range found {
+			sources[resp.Name] = resp.Source
+		}
+		if len(found) != 2 || sources["Ann Client"] != pb.Source_SOURCE_LIVE || sources["Ann Supplier"] != pb.Source_SOURCE_MIRROR {
+			t.Errorf("sort %s: found %v, want Ann Client live and Ann Supplier from the mirror", sort, sources)
+		}
+	}
+}
+
+func TestFindItemFailsWhenBoardsFailWithoutMirror(t *testing.T) {
+	var fake = failingBoard(t)
+	fake.Hook = failSuppliers
+	found, err := find(t, serve(t, New(newClient(fake))), &pb.FindItemRequest{Column: "Name", Value: "ann"})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("err = %v, want Unavailable", err)
+	}
+	if len(found) != 1 || found[0].Name != "Ann Client" {
+		t.Errorf("found %v before failing, want Ann Client", found)
+	}
+}
+
+func TestFindItemLimitsTheBoardAskedFor(t *testing.T) {
+	var fake = mondaytest.New(t, clients, mondaytest.Board{Id: "20", Name: "Suppliers", Columns: contactColumns})
+	for range 3 {
+		fake.AddItem(mondaytest.Item{Name: "Ann Client", Board: "10"})
+	}
+	fake.AddItem(mondaytest.Item{Name: "Ann Supplier", Board: "20"})
+	var client = serve(t, New(newClient(fake)))
+
+	for _, sort := range []pb.Sort{pb.Sort_SORT_NONE, pb.Sort_SORT_RELEVANCE} {
+		found, err := find(t, client, &pb.FindItemRequest{Column: "Name", Value: "ann", Limit: 1, Sort: sort, Board: "suppliers"})
+		if err != nil {
+			t.Fatalf("sort %s: %v", sort, err)
+		}
+		if len(found) != 1 || found[0].Name != "Ann Supplier" {
+			t.Errorf("sort %s: found %v, want Ann Supplier", sort, found)
+		}
+	}
+	if _, err := find(t, client, &pb.FindItemRequest{Column: "Name", Value: "ann", Board: "Partners"}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown board: err = %v, want NotFound", err)
+	}
+}
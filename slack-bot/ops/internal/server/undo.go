@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/undo"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UseUndo keeps the items created, updated, noted and archived in store,
+// with what the updates replaced, for their callers to revert them with
+// Revert.
+func (s *Server) UseUndo(store *undo.Store) {
+	s.undo = store
+}
+
+// remember keeps op for Revert and returns its id. Nothing is kept on dry
+// runs or when the server keeps no operations. The change is done whether
+// or not it can be kept, failing to keep it only logs.
+func (s *Server) remember(ctx context.Context, op undo.Operation) string {
+	if s.undo == nil || monday.IsDryRun(ctx) {
+		return ""
+	}
+	op.Scope, op.User = s.callerScope(ctx), authz.FromIncomingContext(ctx).User
+	id, err := s.undo.Record(context.WithoutCancel(ctx), op)
+	if err != nil {
+		log.Println(fmt.Errorf("failed to keep %s for undo: %w", op.Method, err))
+		return ""
+	}
+	return id
+}
+
+// snapshot returns what req is about to change, when the server keeps
+// operations and req is not a dry run.
+func (s *Server) snapshot(ctx context.Context, b backend, req monday.UpdateItemRequest, dryRun bool) (monday.ItemSnapshot, error) {
+	if s.undo == nil || dryRun {
+		return monday.ItemSnapshot{}, nil
+	}
+	snapshot, err := b.client.Snapshot(ctx, req)
+	if errors.Is(err, monday.ErrItemNotFound) {
+		return snapshot, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return snapshot, status.Errorf(codes.Unavailable, "failed to snapshot item %s: %s", req.ItemId, err)
+	}
+	return snapshot, nil
+}
+
+// Revert undoes an operation of the caller kept by the server: it deletes
+// the item a CreateItem created or the update an AddNote posted, and puts
+// back what an UpdateItem changed. An ArchiveItem is refused, and so stops
+// a revert of the last change from reaching past it.
+func (s *Server) Revert(ctx context.Context, req *pb.RevertRequest) (resp *pb.RevertResponse, err error) {
+	var board, itemId string
+	defer s.record(ctx, "Revert", req, time.Now(), func() (string, string, error) { return board, itemId, err })
+	if s.undo == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the server keeps no operations to revert")
+	}
+	b, err := s.backendFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	op, err := s.undo.Find(ctx, s.callerScope(ctx), authz.FromIncomingContext(ctx).User, req.OperationId)
+	switch {
+	case errors.Is(err, undo.ErrNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, undo.ErrExpired), errors.Is(err, undo.ErrReverted):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	board, itemId = op.Board, op.ItemId
+	if op.Method == undo.METHOD_ARCHIVE_ITEM {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s: item %s was archived, restore it from the archive of %s in monday",
+			undo.ErrCannotRevert, op.ItemId, op.Board)
+	}
+	// reverting a creation deletes, the other reverts change the item
+	var operation = authz.UPDATE
+	if op.Method == undo.METHOD_CREATE_ITEM {
+		operation = authz.DELETE
+	}
+	if err := b.authorize(ctx, op.Board, operation); err != nil {
+		return nil, err
+	}
+	ctx, dryRun := dryRunContext(ctx, req.DryRun)
+	if !req.DryRun {
+		if err := s.undo.Claim(ctx, op.Id); err != nil {
+			if errors.Is(err, undo.ErrReverted) {
+				return nil, status.Error(codes.FailedPrecondition, err.Error())
+			}
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	if err := revert(ctx, b.client, op); err != nil {
+		if !req.DryRun {
+			if releaseErr := s.undo.Release(context.WithoutCancel(ctx), op.Id); releaseErr != nil {
+				log.Println(releaseErr)
+			}
+		}
+		if errors.Is(err, monday.ErrItemNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to revert %s: %s", op.Method, err)
+	}
+	return &pb.RevertResponse{
+		OperationId: op.Id,
+		Method:      op.Method,
+		ItemId:      op.ItemId,
+		Board:       op.Board,
+		DryRun:      toMutation(dryRun),
+	}, nil
+}
+
+func revert(ctx context.Context, client *monday.ApiClient, op undo.Operation) error {
+	switch op.Method {
+	case undo.METHOD_CREATE_ITEM:
+		return client.DeleteItem(ctx, op.ItemId)
+	case undo.METHOD_UPDATE_ITEM:
+		return client.UpdateItem(ctx, op.Snapshot.Restore())
+	case undo.METHOD_ADD_NOTE:
+		return client.DeleteUpdate(ctx, op.UpdateId)
+	}
+	return fmt.Errorf("%s cannot be reverted", op.Method)
+}
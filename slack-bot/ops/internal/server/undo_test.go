@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday/mondaytest"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/undo"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// withUndo returns a client of a server over fake keeping its operations.
+func withUndo(t *testing.T, fake *mondaytest.Server) pb.MondayServiceClient {
+	store, err := undo.OpenStore(filepath.Join(t.TempDir(), "operations.db"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	var s = New(newClient(fake))
+	s.UseUndo(store)
+	return serve(t, s)
+}
+
+func TestRevertUpdatePutsBackRawValues(t *testing.T) {
+	var board = clients
+	board.Columns = append([]mondaytest.Column{
+		{Id: "tags", Title: "Tags", Type: "dropdown", Settings: `{"labels":[{"id":1,"name":"VIP"},{"id":2,"name":"Partner"}]}`},
+		{Id: "site", Title: "Site", Type: "link"},
+		{Id: "notes", Title: "Notes", Type: "text"},
+	}, contactColumns...)
+	var fake = mondaytest.New(t, board)
+	var original = map[string]mondaytest.Value{
+		"tags": {Text: "VIP, Partner", Value: `{"ids":[1,2]}`},
+		"site": {Text: "Site - https://a.example", Value: `{"text":"Site","url":"https://a.example"}`},
+	}
+	var id = fake.AddItem(mondaytest.Item{Name: "Ann", Board: "10", Group: "g1", Values: original})
+	var client = withUndo(t, fake)
+
+	_, err := client.UpdateItem(context.Background(), &pb.UpdateItemRequest{Id: id, Name: "Ann Smith", Columns: map[string]string{
+		"Tags": "Partner", "Site": "https://b.example", "Notes": "met at the fair",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Revert(context.Background(), &pb.RevertRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	item, _ := fake.Item(id)
+	if item.Name != "Ann" {
+		t.Errorf("name = %q after revert, want Ann", item.Name)
+	}
+	for column, want := range original {
+		if got := item.Values[column]; got != want {
+			t.Errorf("%s = %+v after revert, want %+v", column, got, want)
+		}
+	}
+	if notes, ok := item.Values["notes"]; ok {
+		t.Errorf("notes = %+v after revert, want the column empty again", notes)
+	}
+}
+
+func TestRevertStopsAtAnArchive(t *testing.T) {
+	var fake = mondaytest.New(t, clients)
+	var renamed = fake.AddItem(mondaytest.Item{Name: "Ann", Board: "10", Group: "g1"})
+	var archived = fake.AddItem(mondaytest.Item{Name: "Bob", Board: "10", Group: "g1"})
+	var client = withUndo(t, fake)
+
+	update, err := client.UpdateItem(context.Background(), &pb.UpdateItemRequest{Id: renamed, Name: "Ann Smith"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ArchiveItem(context.Background(), &pb.ArchiveItemRequest{Id: archived}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the last change is the archive, not the update before it
+	_, err = client.Revert(context.Background(), &pb.RevertRequest{})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Revert() after an archive: err = %v, want FailedPrecondition", err)
+	}
+	if item, _ := fake.Item(renamed); item.Name != "Ann Smith" {
+		t.Errorf("Revert() after an archive renamed %s back to %q", renamed, item.Name)
+	}
+
+	// the update can still be reverted by its id
+	if _, err := client.Revert(context.Background(), &pb.RevertRequest{OperationId: update.OperationId}); err != nil {
+		t.Fatal(err)
+	}
+	if item, _ := fake.Item(renamed); item.Name != "Ann" {
+		t.Errorf("name = %q after reverting the update, want Ann", item.Name)
+	}
+}
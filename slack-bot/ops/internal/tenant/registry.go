@@ -0,0 +1,167 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/oauth"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/secret"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/rpcauth"
+)
+
+var (
+	ErrNoTenant         = errors.New("no tenant given")
+	ErrUnknownTenant    = errors.New("unknown tenant")
+	ErrClientNotAllowed = errors.New("client may not call for tenant")
+	ErrRateLimited      = errors.New("tenant is over its rate")
+)
+
+// Tenant is served from its own client, which keeps its own board cache.
+type Tenant struct {
+	Id     string
+	Client *monday.ApiClient
+	// Policy is nil when the tenant has none of its own.
+	Policy  *authz.Policy
+	spec    Spec
+	tokens  secret.Source
+	limiter *limiter
+}
+
+// settings are the settings of the server, base, overridden by the ones of
+// the tenant.
+func (t *Tenant) settings(base monday.Settings) monday.Settings {
+	var settings = base
+	if t.spec.APIVersion != "" {
+		settings.APIVersion = t.spec.APIVersion
+	}
+	if len(t.spec.Workspaces) > 0 {
+		settings.Workspaces = t.spec.Workspaces
+	}
+	if t.spec.DefaultBoard != "" {
+		settings.DefaultBoard = t.spec.DefaultBoard
+	}
+	if t.spec.DefaultGroup != "" {
+		settings.DefaultGroup = t.spec.DefaultGroup
+	}
+	if t.spec.SearchConcurrency > 0 {
+		settings.SearchConcurrency = t.spec.SearchConcurrency
+	}
+	if t.spec.BoardsTTL > 0 {
+		settings.BoardsTTL = t.spec.BoardsTTL
+	}
+	return settings
+}
+
+// Registry finds the tenant of a call.
+type Registry struct {
+	byId   map[string]*Tenant
+	byTeam map[string]*Tenant
+	now    func() time.Time
+}
+
+// NewRegistry makes a client calling monday at url for every tenant, with
+// the settings of the server, base, where the tenant has none. accounts
+// holds the tokens of the tenants served from an OAuth account, it may be
+// nil when there are none.
+func NewRegistry(tenants *Tenants, url string, base monday.Settings, accounts *oauth.Store) (*Registry, error) {
+	var r = &Registry{byId: map[string]*Tenant{}, byTeam: map[string]*Tenant{}, now: time.Now}
+	for _, spec := range tenants.Tenants {
+		var tokens secret.Source
+		switch {
+		case spec.Token.Env != "":
+			tokens = secret.Env(spec.Token.Env)
+		case spec.Token.File != "":
+			tokens = secret.File(tenants.path(spec.Token.File))
+		case len(spec.Token.Command) > 0:
+			tokens = secret.Exec(spec.Token.Command, spec.Token.Refresh)
+		default:
+			if accounts == nil {
+				return nil, fmt.Errorf("tenant %s is served from account %s, but no OAuth accounts are kept", spec.Id, spec.Token.Account)
+			}
+			tokens = accounts.Token(spec.Token.Account)
+		}
+		var t = &Tenant{Id: spec.Id, Client: monday.New(url, tokens), spec: spec, tokens: tokens, limiter: newLimiter(spec.Rate, spec.Burst)}
+		if spec.Policy != "" {
+			policy, err := authz.Load(tenants.path(spec.Policy))
+			if err != nil {
+				return nil, fmt.Errorf("tenant %s: %w", spec.Id, err)
+			}
+			t.Policy = policy
+		}
+		t.Client.Configure(t.settings(base))
+		r.byId[t.Id] = t
+		for _, team := range spec.Teams {
+			r.byTeam[team] = t
+		}
+	}
+	return r, nil
+}
+
+// Tenants returns every tenant, ordered by id.
+func (r *Registry) Tenants() []*Tenant {
+	var tenants = []*Tenant{}
+	for _, t := range r.byId {
+		tenants = append(tenants, t)
+	}
+	slices.SortFunc(tenants, func(a, b *Tenant) int { return strings.Compare(a.Id, b.Id) })
+	return tenants
+}
+
+// Check gets the token of t, unless it is served from an OAuth account,
+// which may be installed later on.
+func (t *Tenant) Check() error {
+	if t.spec.Token.Account != "" {
+		return nil
+	}
+	_, err := t.tokens.Token()
+	return err
+}
+
+// Configure makes every tenant follow base where it has no settings of its
+// own, as monday.ApiClient does.
+func (r *Registry) Configure(base monday.Settings) {
+	for _, t := range r.byId {
+		t.Client.Configure(t.settings(base))
+	}
+}
+
+// Lookup returns the tenant the caller of a gRPC call names, or the one
+// serving the caller's team when none is named.
+func (r *Registry) Lookup(ctx context.Context) (*Tenant, error) {
+	var caller = authz.FromIncomingContext(ctx)
+	if caller.Tenant != "" {
+		if t, ok := r.byId[caller.Tenant]; ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("%w %s", ErrUnknownTenant, caller.Tenant)
+	}
+	if caller.Team != "" {
+		if t, ok := r.byTeam[caller.Team]; ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("%w for team %s", ErrUnknownTenant, caller.Team)
+	}
+	return nil, ErrNoTenant
+}
+
+// Admit is Lookup for a call about to be served: it also fails when the
+// client may not call for the tenant or the tenant is over its rate.
+func (r *Registry) Admit(ctx context.Context) (*Tenant, error) {
+	t, err := r.Lookup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if client := rpcauth.ClientFromContext(ctx); len(t.spec.Clients) > 0 && !slices.Contains(t.spec.Clients, client) {
+		return nil, fmt.Errorf("%w %s: %s", ErrClientNotAllowed, t.Id, client)
+	}
+	if !t.limiter.allow(r.now()) {
+		return nil, fmt.Errorf("%w of %g calls a second: %s", ErrRateLimited, t.spec.Rate, t.Id)
+	}
+	return t, nil
+}
@@ -0,0 +1,131 @@
+// Package tenant lets one ops server serve several business units, each a
+// tenant with its own monday account, workspaces, board cache, rate limit
+// and permissions. Every call names its tenant, or comes from a Slack team
+// one tenant lists, and is served from that tenant only.
+package tenant
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec describes a tenant. The monday settings left empty are the ones of
+// the server.
+type Spec struct {
+	Id string `yaml:"id"`
+	// Teams are the Slack teams served from the tenant when a call does not
+	// name one.
+	Teams []string `yaml:"teams"`
+	// Clients are the authenticated clients that may call for the tenant,
+	// every one when empty.
+	Clients []string `yaml:"clients"`
+	Token   Token    `yaml:"token"`
+	// APIVersion, Workspaces, DefaultBoard, DefaultGroup, SearchConcurrency
+	// and BoardsTTL are as in monday.Settings.
+	APIVersion        string        `yaml:"api_version"`
+	Workspaces        []string      `yaml:"workspaces"`
+	DefaultBoard      string        `yaml:"default_board"`
+	DefaultGroup      string        `yaml:"default_group"`
+	SearchConcurrency int           `yaml:"search_concurrency"`
+	BoardsTTL         time.Duration `yaml:"boards_ttl"`
+	// Rate is how many calls a second the tenant may make, with bursts of up
+	// to Burst. Calls are not limited when it is 0.
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+	// Policy is the authz policy of the tenant, the one of the server when
+	// empty.
+	Policy string `yaml:"policy"`
+}
+
+// Token is where the monday token of a tenant comes from, exactly one of:
+// the Env variable, File, the output of Command, read again after Refresh,
+// or the Account ops was installed in through OAuth.
+type Token struct {
+	Env     string        `yaml:"env"`
+	File    string        `yaml:"file"`
+	Command []string      `yaml:"command"`
+	Refresh time.Duration `yaml:"refresh"`
+	Account string        `yaml:"account"`
+}
+
+type Tenants struct {
+	Tenants []Spec `yaml:"tenants"`
+	dir     string
+}
+
+// Load reads the tenants from a YAML file. The token files and policies are
+// relative to it.
+func Load(path string) (*Tenants, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants: %w", err)
+	}
+	var tenants = &Tenants{dir: filepath.Dir(path)}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(tenants); err != nil {
+		return nil, fmt.Errorf("failed to decode tenants %s: %w", path, err)
+	}
+	if err := tenants.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid tenants %s: %w", path, err)
+	}
+	return tenants, nil
+}
+
+// Validate reports every problem of the tenants at once. The policies are
+// read to be checked too.
+func (t *Tenants) Validate() error {
+	var errs = []error{}
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	check(len(t.Tenants) > 0, "no tenants listed")
+	var ids, teams = map[string]bool{}, map[string]string{}
+	for i, spec := range t.Tenants {
+		if spec.Id == "" {
+			errs = append(errs, fmt.Errorf("tenant %d has no id", i+1))
+			continue
+		}
+		check(!ids[spec.Id], "tenant %s is listed twice", spec.Id)
+		ids[spec.Id] = true
+		for _, team := range spec.Teams {
+			if other, ok := teams[team]; ok {
+				errs = append(errs, fmt.Errorf("team %s is served by both %s and %s", team, other, spec.Id))
+			}
+			teams[team] = spec.Id
+		}
+		var sources = 0
+		for _, set := range []bool{spec.Token.Env != "", spec.Token.File != "", len(spec.Token.Command) > 0, spec.Token.Account != ""} {
+			if set {
+				sources++
+			}
+		}
+		check(sources == 1, "tenant %s needs exactly one of token.env, token.file, token.command and token.account", spec.Id)
+		check(spec.SearchConcurrency >= 0, "tenant %s has a negative search_concurrency", spec.Id)
+		check(spec.Rate >= 0, "tenant %s has a negative rate", spec.Id)
+		check(spec.Rate == 0 || spec.Burst >= 1, "tenant %s needs a burst of at least 1 with a rate", spec.Id)
+		if spec.Policy != "" {
+			if _, err := authz.Load(t.path(spec.Policy)); err != nil {
+				errs = append(errs, fmt.Errorf("tenant %s: %w", spec.Id, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// path resolves a path of the tenants file.
+func (t *Tenants) path(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(t.dir, path)
+}
@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// GROUP_ITEMS is how many items of a group are listed.
+const GROUP_ITEMS = 100
+
+// entry is a row of a list screen, opening another screen when chosen.
+type entry struct {
+	title string
+	desc  string
+	open  func() screen
+}
+
+func (e entry) Title() string       { return e.title }
+func (e entry) Description() string { return e.desc }
+func (e entry) FilterValue() string { return e.title }
+
+type loadedMsg struct {
+	entries []entry
+	err     error
+}
+
+// listScreen lists what load returns, filtered as the user types after /.
+type listScreen struct {
+	app  *app
+	list list.Model
+	load func(ctx context.Context) ([]entry, error)
+	err  error
+}
+
+func (a *app) newList(title string, load func(ctx context.Context) ([]entry, error)) *listScreen {
+	var l = list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	l.Title = title
+	l.SetShowHelp(false)
+	// / opens the search, f filters the list
+	l.KeyMap.Filter.SetKeys("f")
+	l.KeyMap.Quit.SetEnabled(false)
+	return &listScreen{app: a, list: l, load: load}
+}
+
+func (s *listScreen) init() tea.Cmd {
+	return tea.Batch(s.list.StartSpinner(), s.app.run(s, func(ctx context.Context) tea.Msg {
+		entries, err := s.load(ctx)
+		return loadedMsg{entries: entries, err: err}
+	}))
+}
+
+func (s *listScreen) update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case loadedMsg:
+		s.list.StopSpinner()
+		s.err = msg.err
+		var items = make([]list.Item, 0, len(msg.entries))
+		for _, e := range msg.entries {
+			items = append(items, e)
+		}
+		return s.list.SetItems(items)
+	case tea.KeyMsg:
+		if msg.String() == "enter" && s.list.FilterState() != list.Filtering {
+			if e, ok := s.list.SelectedItem().(entry); ok && e.open != nil {
+				return push(e.open())
+			}
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	s.list, cmd = s.list.Update(msg)
+	return cmd
+}
+
+func (s *listScreen) view() string {
+	if s.err != nil {
+		return s.list.View() + "\n" + errStyle.Render(s.err.Error())
+	}
+	return s.list.View()
+}
+
+func (s *listScreen) resize(width, height int) {
+	s.list.SetSize(width, height-1)
+}
+
+func (s *listScreen) help() string {
+	return "↑/↓ move • enter open • f filter"
+}
+
+func (s *listScreen) capturing() bool {
+	return s.list.FilterState() == list.Filtering
+}
+
+func (s *listScreen) close() {}
+
+func (a *app) workspaces() screen {
+	return a.newList("Workspaces", func(ctx context.Context) ([]entry, error) {
+		workspaces, err := a.client.ListContactWorkspaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var entries = []entry{}
+		for _, ws := range workspaces {
+			entries = append(entries, entry{
+				title: string(ws.Name),
+				desc:  fmt.Sprintf("%s workspace %s", ws.Kind, ws.Id),
+				open:  func() screen { return a.boards(ws) },
+			})
+		}
+		return entries, nil
+	})
+}
+
+func (a *app) boards(ws monday.WorkspaceListing) screen {
+	return a.newList(string(ws.Name)+" › boards", func(ctx context.Context) ([]entry, error) {
+		boards, err := a.client.ListBoards(ctx, &ws)
+		if err != nil {
+			return nil, err
+		}
+		var entries = []entry{}
+		for _, board := range boards {
+			var desc = string(board.Description)
+			if desc == "" {
+				desc = fmt.Sprintf("%s board, %d columns", board.BoardKind, len(board.Columns))
+			}
+			entries = append(entries, entry{
+				title: string(board.Name),
+				desc:  desc,
+				open:  func() screen { return a.groups(board) },
+			})
+		}
+		return entries, nil
+	})
+}
+
+func (a *app) groups(board monday.BoardListing) screen {
+	return a.newList(string(board.Name)+" › groups", func(ctx context.Context) ([]entry, error) {
+		withGroups, err := a.client.GetBoardWithGroups(ctx, fmt.Sprint(board.Id))
+		if err != nil {
+			return nil, err
+		}
+		var entries = []entry{}
+		for _, group := range withGroups.Groups {
+			entries = append(entries, entry{
+				title: string(group.Title),
+				desc:  fmt.Sprint(group.Id),
+				open:  func() screen { return a.items(board, group) },
+			})
+		}
+		return entries, nil
+	})
+}
+
+func (a *app) items(board monday.BoardListing, group monday.Group) screen {
+	return a.newList(fmt.Sprintf("%s › %s", board.Name, group.Title), func(ctx context.Context) ([]entry, error) {
+		items, err := a.client.GetGroupItems(ctx, fmt.Sprint(board.Id), fmt.Sprint(group.Id), GROUP_ITEMS)
+		if err != nil {
+			return nil, err
+		}
+		var entries = []entry{}
+		for _, item := range items {
+			entries = append(entries, a.itemEntry(item))
+		}
+		return entries, nil
+	})
+}
+
+// itemEntry is the row of item, opening it.
+func (a *app) itemEntry(item monday.Item) entry {
+	var details = []string{}
+	for _, detail := range []string{item.Email(), item.Phone()} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if len(details) == 0 {
+		details = append(details, fmt.Sprintf("%s › %s", item.Board.Name, item.Group.Title))
+	}
+	var id = fmt.Sprint(item.Id)
+	return entry{
+		title: string(item.Name),
+		desc:  strings.Join(details, " • "),
+		open:  func() screen { return a.item(id) },
+	}
+}
@@ -0,0 +1,280 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ITEM_UPDATES is how many of the newest updates of an item are shown.
+const ITEM_UPDATES = 10
+
+// EDITABLE_TYPES are the column types monday.NewColumnValue can set from
+// the text typed in.
+var EDITABLE_TYPES = []monday.ColumnType{
+	monday.COLUMN_TYPE_TEXT,
+	monday.COLUMN_TYPE_EMAIL,
+	monday.COLUMN_TYPE_PHONE,
+	monday.COLUMN_TYPE_STATUS,
+	monday.COLUMN_TYPE_DROPDOWN,
+	monday.COLUMN_TYPE_DATE,
+	monday.COLUMN_TYPE_NUMBERS,
+	monday.COLUMN_TYPE_LONG,
+}
+
+// field is a row of the item screen: its name or one of its columns.
+type field struct {
+	// column is empty for the name of the item
+	column   string
+	title    string
+	value    string
+	editable bool
+}
+
+// itemScreen shows an item with its updates. The user edits the column
+// values inline and adds notes.
+type itemScreen struct {
+	app     *app
+	id      string
+	item    *monday.Item
+	fields  []field
+	updates []monday.Update
+	cursor  int
+	input   textinput.Model
+	// editing is the field being edited, noting is set while a note is typed
+	editing *field
+	noting  bool
+	status  string
+	err     error
+	width   int
+}
+
+type itemLoadedMsg struct {
+	item    *monday.Item
+	fields  []field
+	updates []monday.Update
+	err     error
+}
+
+type savedMsg struct {
+	what string
+	err  error
+}
+
+func (a *app) item(id string) screen {
+	var input = textinput.New()
+	return &itemScreen{app: a, id: id, input: input}
+}
+
+func (s *itemScreen) init() tea.Cmd {
+	return s.app.run(s, func(ctx context.Context) tea.Msg {
+		items, err := s.app.client.GetItemsByIds(ctx, s.id)
+		if err != nil {
+			return itemLoadedMsg{err: err}
+		}
+		if len(items) == 0 {
+			return itemLoadedMsg{err: fmt.Errorf("%w: %s", monday.ErrItemNotFound, s.id)}
+		}
+		var item = items[0]
+		boards, err := s.app.client.ListContactBoards(ctx)
+		if err != nil {
+			return itemLoadedMsg{err: err}
+		}
+		updates, err := s.app.client.GetItemUpdates(ctx, s.id, ITEM_UPDATES)
+		if err != nil {
+			return itemLoadedMsg{err: err}
+		}
+		return itemLoadedMsg{item: &item, fields: fieldsOf(item, boards), updates: updates}
+	})
+}
+
+// fieldsOf lists the name of item then its columns, in the order of its
+// board among boards. Columns of a board not listed cannot be edited.
+func fieldsOf(item monday.Item, boards []monday.BoardListing) []field {
+	var fields = []field{{title: "Name", value: string(item.Name), editable: true}}
+	var values = map[string]string{}
+	for _, c := range item.ColumnValues {
+		values[fmt.Sprint(c.Id)] = string(c.Text)
+	}
+	var idx = slices.IndexFunc(boards, func(b monday.BoardListing) bool { return b.Id == item.Board.Id })
+	if idx < 0 {
+		for _, c := range item.ColumnValues {
+			fields = append(fields, field{column: fmt.Sprint(c.Id), title: fmt.Sprint(c.Id), value: string(c.Text)})
+		}
+		return fields
+	}
+	for _, col := range boards[idx].Columns {
+		var id = fmt.Sprint(col.Id)
+		if id == "name" {
+			continue
+		}
+		fields = append(fields, field{
+			column:   id,
+			title:    string(col.Title),
+			value:    values[id],
+			editable: slices.Contains(EDITABLE_TYPES, monday.ColumnType(col.Type)),
+		})
+	}
+	return fields
+}
+
+// save sets the field edited to value, or adds value as a note.
+func (s *itemScreen) save(value string) tea.Cmd {
+	if s.noting {
+		s.noting = false
+		return s.app.run(s, func(ctx context.Context) tea.Msg {
+			_, err := s.app.client.AddNote(ctx, s.id, value)
+			return savedMsg{what: "Note added", err: err}
+		})
+	}
+	var req = monday.UpdateItemRequest{ItemId: s.id}
+	if s.editing.column == "" {
+		req.Name = value
+	} else {
+		req.Columns = map[string]string{s.editing.column: value}
+	}
+	var what = s.editing.title + " saved"
+	s.editing = nil
+	return s.app.run(s, func(ctx context.Context) tea.Msg {
+		return savedMsg{what: what, err: s.app.client.UpdateItem(ctx, req)}
+	})
+}
+
+func (s *itemScreen) update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case itemLoadedMsg:
+		s.err = msg.err
+		if msg.err == nil {
+			s.item, s.fields, s.updates = msg.item, msg.fields, msg.updates
+			s.cursor = min(s.cursor, len(s.fields)-1)
+		}
+		return nil
+	case savedMsg:
+		if msg.err != nil {
+			s.status, s.err = "", msg.err
+			return nil
+		}
+		s.status, s.err = msg.what, nil
+		return s.init()
+	case tea.KeyMsg:
+		if s.input.Focused() {
+			switch msg.String() {
+			case "enter":
+				s.input.Blur()
+				return s.save(strings.TrimSpace(s.input.Value()))
+			case "esc":
+				s.input.Blur()
+				s.editing, s.noting = nil, false
+				return nil
+			}
+			var cmd tea.Cmd
+			s.input, cmd = s.input.Update(msg)
+			return cmd
+		}
+		switch msg.String() {
+		case "up", "k":
+			s.cursor = max(s.cursor-1, 0)
+		case "down", "j":
+			s.cursor = min(s.cursor+1, len(s.fields)-1)
+		case "enter", "e":
+			if s.cursor < len(s.fields) && s.fields[s.cursor].editable {
+				s.editing = &s.fields[s.cursor]
+				s.status, s.err = "", nil
+				s.input.Prompt = s.editing.title + " › "
+				s.input.SetValue(s.editing.value)
+				s.input.CursorEnd()
+				return s.input.Focus()
+			}
+			s.status = "This column cannot be edited here"
+		case "n":
+			if s.item != nil {
+				s.noting = true
+				s.status, s.err = "", nil
+				s.input.Prompt = "note › "
+				s.input.SetValue("")
+				return s.input.Focus()
+			}
+		case "r":
+			s.status = "Reloading"
+			return s.init()
+		}
+	}
+	return nil
+}
+
+func (s *itemScreen) view() string {
+	var b = strings.Builder{}
+	if s.item == nil {
+		b.WriteString(titleStyle.Render("Item " + s.id))
+		b.WriteString("\n")
+		if s.err != nil {
+			b.WriteString(errStyle.Render(s.err.Error()))
+		} else {
+			b.WriteString(labelStyle.Render("Loading…"))
+		}
+		return b.String()
+	}
+	b.WriteString(titleStyle.Render(string(s.item.Name)))
+	b.WriteString("\n")
+	b.WriteString(labelStyle.Render(fmt.Sprintf("%s › %s • %s", s.item.Board.Name, s.item.Group.Title, s.item.Url)))
+	b.WriteString("\n\n")
+	var width = 0
+	for _, f := range s.fields {
+		width = max(width, lipgloss.Width(f.title))
+	}
+	for i, f := range s.fields {
+		var row = fmt.Sprintf("%-*s  %s", width, f.title, f.value)
+		if !f.editable {
+			row = hintStyle.Render(row)
+		}
+		if i == s.cursor {
+			row = cursorRow.Render("› ") + row
+		} else {
+			row = "  " + row
+		}
+		b.WriteString(row)
+		b.WriteString("\n")
+	}
+	if s.input.Focused() {
+		b.WriteString("\n")
+		b.WriteString(s.input.View())
+		b.WriteString("\n")
+	}
+	switch {
+	case s.err != nil:
+		b.WriteString("\n" + errStyle.Render(s.err.Error()) + "\n")
+	case s.status != "":
+		b.WriteString("\n" + labelStyle.Render(s.status) + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Updates (%d)", len(s.updates))))
+	b.WriteString("\n")
+	for _, u := range s.updates {
+		b.WriteString(labelStyle.Render(fmt.Sprintf("%s, %s", u.Creator.Name, u.CreatedAt)))
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Width(max(s.width-2, 20)).PaddingLeft(2).Render(string(u.TextBody)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (s *itemScreen) resize(width, height int) {
+	s.width = width
+	s.input.Width = width / 2
+}
+
+func (s *itemScreen) help() string {
+	return "↑/↓ move • enter edit • n add a note • r reload"
+}
+
+func (s *itemScreen) capturing() bool {
+	return s.input.Focused()
+}
+
+func (s *itemScreen) close() {}
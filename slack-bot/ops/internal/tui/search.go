@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DEFAULT_SEARCH_COLUMN is searched when the query names no column.
+const DEFAULT_SEARCH_COLUMN = "Name"
+
+// searchScreen searches every contact board live, listing the items as
+// the boards answer.
+type searchScreen struct {
+	app   *app
+	input textinput.Model
+	list  list.Model
+	spin  spinner.Model
+	// cancel stops the search running, if any
+	cancel context.CancelFunc
+	// search counts the searches started, for the items of a replaced one
+	// to be dropped
+	search  int
+	running bool
+	err     error
+}
+
+type startedMsg struct {
+	search int
+	items  *monday.Search
+	err    error
+}
+
+type foundMsg struct {
+	search int
+	items  *monday.Search
+	item   monday.Item
+	// done is set once the search sent every item
+	done bool
+}
+
+func (a *app) search() screen {
+	var input = textinput.New()
+	input.Prompt = "search › "
+	input.Placeholder = "ann, or email: ann@example.com"
+	input.Focus()
+	var l = list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	l.SetShowTitle(false)
+	l.SetShowHelp(false)
+	l.SetFilteringEnabled(false)
+	l.KeyMap.Quit.SetEnabled(false)
+	return &searchScreen{app: a, input: input, list: l, spin: spinner.New(spinner.WithSpinner(spinner.Dot))}
+}
+
+func (s *searchScreen) init() tea.Cmd {
+	return textinput.Blink
+}
+
+// focus moves back to the query, to search again.
+func (s *searchScreen) focus() tea.Cmd {
+	return s.input.Focus()
+}
+
+// parseQuery splits "column: value" in its column and value, the column
+// being DEFAULT_SEARCH_COLUMN when there is none.
+func parseQuery(query string) (column, value string) {
+	column, value, ok := strings.Cut(query, ":")
+	if !ok {
+		return DEFAULT_SEARCH_COLUMN, strings.TrimSpace(query)
+	}
+	return strings.TrimSpace(column), strings.TrimSpace(value)
+}
+
+// start searches for the query, replacing the search running if any.
+func (s *searchScreen) start() tea.Cmd {
+	column, value := parseQuery(s.input.Value())
+	if value == "" {
+		return nil
+	}
+	s.close()
+	s.search++
+	s.running, s.err = true, nil
+	s.input.Blur()
+	var search = s.search
+	ctx, cancel := context.WithCancel(s.app.ctx)
+	s.cancel = cancel
+	var params = monday.ItemsQuery{Operator: "and"}
+	params.AddRule(column, monday.CompareValue(value), monday.CONTAINS_TEXT)
+	return tea.Batch(s.list.SetItems(nil), s.spin.Tick, func() tea.Msg {
+		items, err := s.app.client.GetItemsInAllBoards(ctx, params, 0)
+		return forMsg{to: s, msg: startedMsg{search: search, items: items, err: err}}
+	})
+}
+
+// next waits for the next item of the search.
+func (s *searchScreen) next(search int, items *monday.Search) tea.Cmd {
+	return func() tea.Msg {
+		item, ok := <-items.Items
+		return forMsg{to: s, msg: foundMsg{search: search, items: items, item: item, done: !ok}}
+	}
+}
+
+func (s *searchScreen) update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case startedMsg:
+		if msg.search != s.search {
+			return nil
+		}
+		if msg.err != nil {
+			s.running, s.err = false, msg.err
+			return nil
+		}
+		return s.next(msg.search, msg.items)
+	case foundMsg:
+		if msg.search != s.search {
+			return nil
+		}
+		if msg.done {
+			// the items of the boards that failed are missing
+			s.running, s.err = false, msg.items.Err()
+			return nil
+		}
+		return tea.Batch(s.list.InsertItem(len(s.list.Items()), s.app.itemEntry(msg.item)), s.next(msg.search, msg.items))
+	case spinner.TickMsg:
+		if !s.running {
+			return nil
+		}
+		var cmd tea.Cmd
+		s.spin, cmd = s.spin.Update(msg)
+		return cmd
+	case tea.KeyMsg:
+		if s.input.Focused() {
+			switch msg.String() {
+			case "enter":
+				return s.start()
+			case "esc":
+				s.input.Blur()
+				return nil
+			}
+			var cmd tea.Cmd
+			s.input, cmd = s.input.Update(msg)
+			return cmd
+		}
+		if msg.String() == "enter" {
+			if e, ok := s.list.SelectedItem().(entry); ok {
+				return push(e.open())
+			}
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	s.list, cmd = s.list.Update(msg)
+	return cmd
+}
+
+func (s *searchScreen) view() string {
+	var status string
+	switch {
+	case s.err != nil:
+		status = errStyle.Render(s.err.Error())
+	case s.running:
+		status = labelStyle.Render(fmt.Sprintf("%s %d found so far", s.spin.View(), len(s.list.Items())))
+	case s.search > 0:
+		status = labelStyle.Render(fmt.Sprintf("%d found", len(s.list.Items())))
+	default:
+		status = hintStyle.Render("search every contact board, by name or by column: value")
+	}
+	return titleStyle.Render("Search") + "\n" + s.input.View() + "\n" + status + "\n" + s.list.View()
+}
+
+func (s *searchScreen) resize(width, height int) {
+	s.input.Width = width - lipgloss.Width(s.input.Prompt) - 1
+	s.list.SetSize(width, height-4)
+}
+
+func (s *searchScreen) help() string {
+	return "↑/↓ move • enter open • / search again"
+}
+
+func (s *searchScreen) capturing() bool {
+	return s.input.Focused()
+}
+
+func (s *searchScreen) close() {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
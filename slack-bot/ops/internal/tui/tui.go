@@ -0,0 +1,165 @@
+// Package tui is an interactive terminal UI over the monday client. It
+// browses the contacts workspaces, their boards, groups and items, searches
+// every board with results shown as they stream in, and shows an item with
+// its updates, where column values are edited inline and notes added.
+package tui
+
+import (
+	"context"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	labelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	hintStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	errStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	cursorRow  = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+)
+
+// Run shows the UI until the user quits. The calls to monday are made with
+// ctx, and are cancelled when the UI ends.
+func Run(ctx context.Context, client *monday.ApiClient) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var a = &app{ctx: ctx, client: client}
+	_, err := tea.NewProgram(newModel(a), tea.WithAltScreen()).Run()
+	return err
+}
+
+// app is what every screen calls monday with.
+type app struct {
+	ctx    context.Context
+	client *monday.ApiClient
+}
+
+// screen is one view of the stack the user navigates, e.g. the boards of a
+// workspace. The screen on top gets the keys.
+type screen interface {
+	init() tea.Cmd
+	update(msg tea.Msg) tea.Cmd
+	view() string
+	resize(width, height int)
+	// help names the keys of the screen.
+	help() string
+	// capturing tells whether the screen takes every key, e.g. while the
+	// user types in it.
+	capturing() bool
+	// close stops what the screen still has running once it is left.
+	close()
+}
+
+// pushMsg opens a screen on top of the stack.
+type pushMsg struct {
+	screen screen
+}
+
+// forMsg is the result of the work of a screen, delivered to it wherever it
+// is in the stack and dropped once it was left.
+type forMsg struct {
+	to  screen
+	msg tea.Msg
+}
+
+// run does fn in the background and delivers its result to s.
+func (a *app) run(s screen, fn func(ctx context.Context) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return forMsg{to: s, msg: fn(a.ctx)}
+	}
+}
+
+func push(s screen) tea.Cmd {
+	return func() tea.Msg { return pushMsg{screen: s} }
+}
+
+type model struct {
+	app    *app
+	stack  []screen
+	width  int
+	height int
+}
+
+func newModel(a *app) *model {
+	return &model{app: a, stack: []screen{a.workspaces()}}
+}
+
+func (m *model) Init() tea.Cmd {
+	return m.top().init()
+}
+
+func (m *model) top() screen {
+	return m.stack[len(m.stack)-1]
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		for _, s := range m.stack {
+			s.resize(m.width, m.height-1)
+		}
+		return m, nil
+	case pushMsg:
+		msg.screen.resize(m.width, m.height-1)
+		m.stack = append(m.stack, msg.screen)
+		return m, msg.screen.init()
+	case spinner.TickMsg:
+		// each spinner only takes its own ticks, including the ones of
+		// screens below the top
+		var cmds = []tea.Cmd{}
+		for _, s := range m.stack {
+			cmds = append(cmds, s.update(msg))
+		}
+		return m, tea.Batch(cmds...)
+	case forMsg:
+		for _, s := range m.stack {
+			if s == msg.to {
+				return m, s.update(msg.msg)
+			}
+		}
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m.quit()
+		}
+		if m.top().capturing() {
+			return m, m.top().update(msg)
+		}
+		switch msg.String() {
+		case "q":
+			return m.quit()
+		case "esc", "backspace":
+			if len(m.stack) == 1 {
+				return m, nil
+			}
+			m.top().close()
+			m.stack = m.stack[:len(m.stack)-1]
+			return m, nil
+		case "/":
+			if s, ok := m.top().(*searchScreen); ok {
+				return m, s.focus()
+			}
+			return m, push(m.app.search())
+		}
+	}
+	return m, m.top().update(msg)
+}
+
+func (m *model) quit() (tea.Model, tea.Cmd) {
+	for _, s := range m.stack {
+		s.close()
+	}
+	return m, tea.Quit
+}
+
+func (m *model) View() string {
+	var help = m.top().help() + " • / search • esc back • q quit"
+	if m.top().capturing() {
+		help = "enter confirm • esc cancel • ctrl+c quit"
+	}
+	return m.top().view() + "\n" + hintStyle.Render(help)
+}
@@ -0,0 +1,196 @@
+// Package undo keeps the changes made through the ops server for a while,
+// with what they replaced, for their caller to revert them.
+package undo
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
+	_ "modernc.org/sqlite"
+)
+
+// DEFAULT_WINDOW is how long a change can be reverted when the config does
+// not say.
+const DEFAULT_WINDOW = 15 * time.Minute
+
+// The methods whose operations are kept. Archives cannot be reverted, they
+// are kept for the last change of their caller to be the archive rather
+// than the change before it.
+const (
+	METHOD_CREATE_ITEM  = "CreateItem"
+	METHOD_UPDATE_ITEM  = "UpdateItem"
+	METHOD_ADD_NOTE     = "AddNote"
+	METHOD_ARCHIVE_ITEM = "ArchiveItem"
+)
+
+var (
+	ErrNotFound     = errors.New("no operation to revert")
+	ErrExpired      = errors.New("operation is too old to revert")
+	ErrReverted     = errors.New("operation already reverted")
+	ErrCannotRevert = errors.New("operation cannot be reverted")
+)
+
+// time is stored as RFC 3339 with nanoseconds in UTC, which sorts as text;
+// reverted_at is empty until the operation is reverted
+const schema = `
+CREATE TABLE IF NOT EXISTS operations (
+	id TEXT PRIMARY KEY,
+	scope TEXT NOT NULL,
+	user TEXT NOT NULL,
+	method TEXT NOT NULL,
+	board TEXT NOT NULL,
+	item_id TEXT NOT NULL,
+	update_id TEXT NOT NULL,
+	snapshot TEXT NOT NULL,
+	at TEXT NOT NULL,
+	reverted_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS operations_caller ON operations(scope, user, at);
+CREATE INDEX IF NOT EXISTS operations_at ON operations(at);`
+
+const timeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// Operation is a change made through the server, with what reverting it
+// needs.
+type Operation struct {
+	Id string
+	// Scope is the client, tenant and Slack team the caller called from,
+	// User the caller.
+	Scope  string
+	User   string
+	Method string
+	Board  string
+	ItemId string
+	// UpdateId is the update an AddNote posted.
+	UpdateId string
+	// Snapshot is the item before an UpdateItem.
+	Snapshot monday.ItemSnapshot
+	At       time.Time
+}
+
+// Store keeps the operations of the last window in a SQLite file only its
+// owner may read.
+type Store struct {
+	db     *sql.DB
+	window time.Duration
+}
+
+func OpenStore(path string, window time.Duration) (*Store, error) {
+	if window <= 0 {
+		window = DEFAULT_WINDOW
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open operations %s: %w", path, err)
+	}
+	// a single connection serializes the reverts of an operation
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create operations schema: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to restrict operations %s: %w", path, err)
+	}
+	return &Store{db: db, window: window}, nil
+}
+
+// Record keeps op, forgetting the operations too old to revert, and returns
+// the id it was given.
+func (s *Store) Record(ctx context.Context, op Operation) (string, error) {
+	var id = make([]byte, 12)
+	rand.Read(id)
+	op.Id = hex.EncodeToString(id)
+	if op.At.IsZero() {
+		op.At = time.Now()
+	}
+	snapshot, err := json.Marshal(op.Snapshot)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM operations WHERE at < ?`, time.Now().Add(-s.window).UTC().Format(timeFormat)); err != nil {
+		return "", fmt.Errorf("failed to expire operations: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO operations(id, scope, user, method, board, item_id, update_id, snapshot, at, reverted_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, '')`,
+		op.Id, op.Scope, op.User, op.Method, op.Board, op.ItemId, op.UpdateId, string(snapshot), op.At.UTC().Format(timeFormat))
+	if err != nil {
+		return "", fmt.Errorf("failed to record operation: %w", err)
+	}
+	return op.Id, nil
+}
+
+// Find returns the operation with id, or the last one not reverted yet when
+// id is empty. Only the operations of user in scope are looked at, and the
+// ones reverted or too old are refused.
+func (s *Store) Find(ctx context.Context, scope, user, id string) (Operation, error) {
+	var query = `SELECT id, scope, user, method, board, item_id, update_id, snapshot, at, reverted_at FROM operations
+		WHERE scope = ? AND user = ?`
+	var args = []any{scope, user}
+	if id != "" {
+		query += ` AND id = ?`
+		args = append(args, id)
+	} else {
+		query += ` AND reverted_at = '' ORDER BY at DESC LIMIT 1`
+	}
+	var op = Operation{}
+	var snapshot, at, revertedAt string
+	err := s.db.QueryRowContext(ctx, query, args...).
+		Scan(&op.Id, &op.Scope, &op.User, &op.Method, &op.Board, &op.ItemId, &op.UpdateId, &snapshot, &at, &revertedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Operation{}, ErrNotFound
+	}
+	if err != nil {
+		return Operation{}, fmt.Errorf("failed to look up operation: %w", err)
+	}
+	if err := json.Unmarshal([]byte(snapshot), &op.Snapshot); err != nil {
+		return Operation{}, fmt.Errorf("failed to decode snapshot of operation %s: %w", op.Id, err)
+	}
+	op.At, _ = time.Parse(timeFormat, at)
+	switch {
+	case revertedAt != "":
+		return op, ErrReverted
+	case time.Since(op.At) > s.window:
+		return op, fmt.Errorf("%w, the %s was %s ago", ErrExpired, op.Method, time.Since(op.At).Round(time.Second))
+	}
+	return op, nil
+}
+
+// Claim marks the operation with id as reverted, ErrReverted when it
+// already was. Release gives it back when reverting it failed.
+func (s *Store) Claim(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE operations SET reverted_at = ? WHERE id = ? AND reverted_at = ''`,
+		time.Now().UTC().Format(timeFormat), id)
+	if err != nil {
+		return fmt.Errorf("failed to claim operation %s: %w", id, err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to claim operation %s: %w", id, err)
+	}
+	if claimed == 0 {
+		return ErrReverted
+	}
+	return nil
+}
+
+// Release marks the operation with id as not reverted, for another try.
+func (s *Store) Release(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE operations SET reverted_at = '' WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to release operation %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
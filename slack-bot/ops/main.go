@@ -1,69 +1,303 @@
 package main
 
 import (
+	"bufio"
 	"context"
-	"flag"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/audit"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/config"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/devcert"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/gateway"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/idempotency"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/index"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/mirror"
 	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/monday"
-	"github.com/joho/godotenv"
-)
-
-const (
-	MONDAY_TOKEN = "MONDAY_TOKEN"
-	MONDAY_URL   = "https://api.monday.com/v2"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/oauth"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/provision"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/rank"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/secret"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/server"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/tenant"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/tui"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/internal/undo"
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/rpcauth"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/rpcmetrics"
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/tracing"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 var (
-	verbose       = flag.Bool("v", false, "verbose")
-	searchFlagSet = flag.NewFlagSet("search", flag.ExitOnError)
-	column        = searchFlagSet.String("col", "", "Column after which to search")
-	value         = searchFlagSet.String("val", "", "Value to search in corresponding column")
-	addFlagSet    = flag.NewFlagSet("add", flag.ExitOnError)
-	board         = addFlagSet.String("board", "", "Board Name to add")
-	group         = addFlagSet.String("group", "", "Board Name to add")
-	name          = addFlagSet.String("name", "", "Name to add")
-	email         = addFlagSet.String("email", "", "Email to add")
-	phone         = addFlagSet.String("phone", "", "Phone to add")
+	rootCmd = &cobra.Command{
+		Use:   "ops",
+		Short: "Search and edit the monday contact boards, and serve them to the bot",
+	}
+	verbose   = rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose")
+	searchCmd = &cobra.Command{
+		Use:   "search",
+		Short: "Search every contact board for the items whose column contains a value",
+		Args:  cobra.NoArgs,
+	}
+	column       = searchCmd.Flags().String("col", "", "Column after which to search")
+	value        = searchCmd.Flags().String("val", "", "Value to search in corresponding column")
+	limit        = searchCmd.Flags().Int("limit", 0, "Stop after this many results, 0 for all")
+	sortBy       = searchCmd.Flags().String("sort", "none", "Order results by none, relevance or name")
+	searchIndex  = searchCmd.Flags().String("index", "", "Search this local index first, falling back to monday")
+	searchMirror = searchCmd.Flags().String("mirror", "", "Search this local mirror when monday cannot be reached")
+	addCmd       = &cobra.Command{
+		Use:   "add",
+		Short: "Add a contact to a board",
+		Args:  cobra.NoArgs,
+	}
+	board        = addCmd.Flags().String("board", "", "Board Name to add")
+	group        = addCmd.Flags().String("group", "", "Board Name to add")
+	name         = addCmd.Flags().String("name", "", "Name to add")
+	email        = addCmd.Flags().String("email", "", "Email to add")
+	phone        = addCmd.Flags().String("phone", "", "Phone to add")
+	addDryRun    = addCmd.Flags().Bool("dry-run", false, "Print the mutation, with the board, group and column ids resolved, instead of sending it")
+	provisionCmd = &cobra.Command{
+		Use:   "provision",
+		Short: "Create or update a board after a spec",
+		Args:  cobra.NoArgs,
+	}
+	specPath        = provisionCmd.Flags().String("spec", "", "YAML or JSON board spec to apply")
+	provisionDryRun = provisionCmd.Flags().Bool("dry-run", false, "Print the mutations applying the spec would send instead of sending them")
+	describeCmd     = &cobra.Command{
+		Use:   "describe",
+		Short: "Describe the columns and groups of the contact boards",
+		Args:  cobra.NoArgs,
+	}
+	describeBoard = describeCmd.Flags().String("board", "", "Board to describe, all boards in the workspace if empty")
+	asJson        = describeCmd.Flags().Bool("json", false, "Print the description as JSON")
+	serveCmd      = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve MondayService over gRPC",
+		Args:  cobra.NoArgs,
+	}
+	syncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Fully sync a local index of the contacts",
+		Args:  cobra.NoArgs,
+	}
+	syncIndex = syncCmd.Flags().String("index", "contacts.db", "Local index file to fully sync")
+	mirrorCmd = &cobra.Command{
+		Use:   "mirror",
+		Short: "Pull the items updated since the last run into a local mirror",
+		Args:  cobra.NoArgs,
+	}
+	mirrorPath = mirrorCmd.Flags().String("db", "mirror.db", "Local mirror to update")
+	mirrorOnly = mirrorCmd.Flags().String("boards", "", "Comma separated boards to mirror, all boards if empty")
+	certsCmd   = &cobra.Command{
+		Use:   "certs",
+		Short: "Generate a CA with server and client certificates for development",
+		Args:  cobra.NoArgs,
+	}
+	certsDir      = certsCmd.Flags().String("dir", "certs", "Directory to write the certificates and keys to")
+	certsHosts    = certsCmd.Flags().String("hosts", "localhost,127.0.0.1,::1", "Comma separated names and addresses the server certificate is valid for")
+	certsClient   = certsCmd.Flags().String("client", "bot", "Common name of the client certificate")
+	certsValidFor = certsCmd.Flags().Duration("valid-for", 90*24*time.Hour, "How long the certificates are valid")
+	auditCmd      = &cobra.Command{
+		Use:   "audit",
+		Short: "Query the audit log of the changes asked of the server",
+		Args:  cobra.NoArgs,
+	}
+	auditPath  = auditCmd.Flags().String("log", "audit.db", "Audit log to query, as given to serve --audit")
+	auditUser  = auditCmd.Flags().String("user", "", "Only show the changes asked by this Slack user id")
+	auditBoard = auditCmd.Flags().String("board", "", "Only show the changes to this board")
+	auditSince = auditCmd.Flags().String("since", "", "Only show the changes since this time, e.g. 24h ago or 2024-05-01")
+	auditUntil = auditCmd.Flags().String("until", "", "Only show the changes before this time, e.g. 1h ago or 2024-05-02T12:00:00Z")
+	auditLimit = auditCmd.Flags().Int("limit", 100, "Show at most this many of the newest changes")
+	auditJson  = auditCmd.Flags().Bool("json", false, "Print the records as JSON lines")
+	configCmd  = &cobra.Command{
+		Use:   "config",
+		Short: "Check the config",
+		Args:  cobra.NoArgs,
+	}
+	validateCmd = &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config as serve would read it, token included",
+		Args:  cobra.NoArgs,
+	}
+	tokenCmd = &cobra.Command{
+		Use:   "token",
+		Short: "Manage the monday token",
+		Args:  cobra.NoArgs,
+	}
+	storeCmd = &cobra.Command{
+		Use:   "store",
+		Short: "Keep the monday token read from stdin in the keyring",
+		Args:  cobra.NoArgs,
+	}
+	tuiCmd = &cobra.Command{
+		Use:   "tui",
+		Short: "Browse, search and edit the contact boards in the terminal",
+		Args:  cobra.NoArgs,
+	}
+	tuiLog = tuiCmd.Flags().String("log", "", "File to write the logs to while the TUI runs, none if empty")
+	// configPath is the --config flag of every subcommand that reaches monday
+	configPath string
 )
 
-func main() {
-	godotenv.Load("../.env")
-	parseFlags()
-	if *verbose {
-		slog.SetLogLoggerLevel(slog.LevelDebug)
+// init wires the commands, and defines the serve flags, which override the
+// config, with the config defaults.
+func init() {
+	rootCmd.PersistentPreRun = func(*cobra.Command, []string) {
+		if *verbose {
+			slog.SetLogLoggerLevel(slog.LevelDebug)
+		}
+	}
+	searchCmd.Run = withClient(doSearch)
+	addCmd.Run = withClient(doAdd)
+	provisionCmd.Run = withClient(doProvision)
+	describeCmd.Run = withClient(doDescribe)
+	serveCmd.Run = func(*cobra.Command, []string) {
+		cfg, client := connect()
+		doServe(client, cfg)
 	}
-	client := monday.New(MONDAY_URL, os.Getenv(MONDAY_TOKEN))
-	if searchFlagSet.Parsed() {
-		doSearch(client)
-	} else {
-		doAdd(client)
+	syncCmd.Run = withClient(doSync)
+	mirrorCmd.Run = withClient(doMirror)
+	tuiCmd.Run = withClient(doTUI)
+	certsCmd.Run = func(*cobra.Command, []string) { doCerts() }
+	auditCmd.Run = func(*cobra.Command, []string) { doAudit() }
+	validateCmd.Run = func(*cobra.Command, []string) { doValidate() }
+	storeCmd.Run = func(*cobra.Command, []string) { doStoreToken() }
+	searchCmd.MarkFlagRequired("col")
+	searchCmd.MarkFlagRequired("val")
+	provisionCmd.MarkFlagRequired("spec")
+	for _, cmd := range []*cobra.Command{searchCmd, addCmd, provisionCmd, describeCmd, serveCmd, syncCmd, mirrorCmd, validateCmd, storeCmd, tuiCmd} {
+		cmd.Flags().StringVar(&configPath, "config", "", fmt.Sprintf("YAML or TOML config file, $%sCONFIG or %s if it exists when empty", config.ENV_PREFIX, config.DEFAULT_PATH))
+		cmd.MarkFlagFilename("config", "yaml", "yml", "toml")
 	}
+	var defaults = config.Default().Serve
+	var serveFlags = serveCmd.Flags()
+	serveFlags.String("addr", defaults.Addr, "Address the gRPC server listens on")
+	serveFlags.String("index", "", "Local index file to serve name, email and phone lookups from")
+	serveFlags.Duration("sync-interval", defaults.SyncInterval, "How often the local index is fully re-synced")
+	serveFlags.String("webhook-addr", "", "Address to receive monday webhooks on, at /webhooks/monday")
+	serveFlags.String("mirror", "", "Local mirror to serve searches from when monday cannot be reached")
+	serveFlags.String("mirror-boards", "", "Comma separated boards to mirror, all boards if empty")
+	serveFlags.Duration("mirror-interval", defaults.MirrorInterval, "How often the mirror pulls updated items")
+	serveFlags.String("authz", "", "YAML policy of who may do what on which boards, everyone may do everything if empty")
+	serveFlags.String("tenants", "", "YAML list of the tenants to serve, each from its own monday account; only the monday token if empty")
+	serveFlags.String("tls-cert", "", "Certificate to serve TLS with, plaintext if empty")
+	serveFlags.String("tls-key", "", "Key of --tls-cert")
+	serveFlags.String("tls-client-ca", "", "CA client certificates must be signed by (mutual TLS)")
+	serveFlags.String("clients", "", "YAML list of the clients allowed to call, with their token or HMAC secret; no authentication if empty")
+	serveFlags.String("http-addr", "", "Address to serve the REST/JSON gateway and its OpenAPI document on, none if empty")
+	serveFlags.String("trace", "", "Export traces to stdout or otlp (OTEL_EXPORTER_OTLP_ENDPOINT, localhost:4317 by default); none if empty")
+	serveFlags.String("metrics-addr", "", "Address to serve Prometheus metrics on, at /metrics; none if empty")
+	serveFlags.String("audit", "", "Audit log to record every change in, JSON lines if it ends with .jsonl, SQLite otherwise")
+	serveFlags.String("idempotency", "", "SQLite file keeping the idempotency keys of CreateItem, none kept if empty")
+	serveFlags.Duration("idempotency-ttl", defaults.IdempotencyTTL, "How long an idempotency key is remembered")
+	serveFlags.String("undo", "", "SQLite file keeping the changes callers can revert, none kept if empty")
+	serveFlags.Duration("undo-window", defaults.UndoWindow, "How long a change can be reverted")
+	registerCompletions()
+	configCmd.AddCommand(validateCmd)
+	tokenCmd.AddCommand(storeCmd)
+	rootCmd.AddCommand(searchCmd, addCmd, provisionCmd, describeCmd, serveCmd, syncCmd, mirrorCmd, tuiCmd, certsCmd, auditCmd, configCmd, tokenCmd)
 }
 
-func parseFlags() {
-	if len(os.Args) < 2 {
-		fmt.Println("expected 'search' or 'add' subcommands")
+func main() {
+	rootCmd.SetArgs(longFlags(rootCmd, os.Args[1:]))
+	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
-	switch os.Args[1] {
-	case "search":
-		searchFlagSet.Parse(os.Args[2:])
-		if *value == "" || *column == "" {
-			log.Fatal("Use -c && -v to search")
+}
+
+// withClient runs fn with a client of the config.
+func withClient(fn func(client *monday.ApiClient)) func(*cobra.Command, []string) {
+	return func(*cobra.Command, []string) {
+		_, client := connect()
+		fn(client)
+	}
+}
+
+// connect reads the config and makes a monday client with its token.
+func connect() (*config.Config, *monday.ApiClient) {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	tokens := cfg.TokenSource()
+	if _, err := tokens.Token(); err != nil {
+		log.Fatal(fmt.Errorf("failed to get the monday token from %s: %w", tokens, err))
+	}
+	slog.Debug("Using the monday token", "source", tokens.String())
+	client := monday.New(cfg.Monday.URL, tokens)
+	client.Configure(cfg.Settings())
+	return cfg, client
+}
+
+// loadConfig layers the config file, the environment and, for serve, the
+// flags given.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if serveCmd.Flags().Parsed() {
+		if err := cfg.ApplyFlags(serveCmd.Flags()); err != nil {
+			return nil, err
 		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s:\n%w", cfg.Path(), err)
+	}
+	return cfg, nil
+}
 
-	case "add":
-		addFlagSet.Parse(os.Args[2:])
-	default:
-		fmt.Println("expected 'search' or 'add' as subcommands")
-		os.Exit(1)
+// longFlags reads the flags given with a single dash, as ops took them
+// before it had completions, e.g. -config, as the long flags they are.
+func longFlags(root *cobra.Command, args []string) []string {
+	var names = map[string]bool{}
+	var collect func(cmd *cobra.Command)
+	collect = func(cmd *cobra.Command) {
+		for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.PersistentFlags()} {
+			flags.VisitAll(func(f *pflag.Flag) { names[f.Name] = true })
+		}
+		for _, sub := range cmd.Commands() {
+			collect(sub)
+		}
 	}
+	collect(root)
+	var long = slices.Clone(args)
+	for i, arg := range long {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") {
+			continue
+		}
+		name, _, _ := strings.Cut(arg[1:], "=")
+		if len(name) > 1 && names[name] {
+			long[i] = "-" + arg
+		}
+	}
+	return long
 }
+
 func doSearch(client *monday.ApiClient) {
 	var params = monday.ItemsQuery{
 		Rules: []monday.ItemsQueryRule{
@@ -75,24 +309,621 @@ func doSearch(client *monday.ApiClient) {
 		},
 		Operator: "and",
 	}
-	items, err := client.GetItemsInAllBoards(context.Background(), params)
+	sort, err := rank.ParseSort(*sortBy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if *searchIndex != "" {
+		idx, err := index.Open(*searchIndex)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer idx.Close()
+		found, err := idx.Search(ctx, *column, *value, *limit)
+		if err == nil {
+			if sort != rank.SORT_NONE {
+				found = rank.Items(rank.Rank(*value, found, sort))
+			}
+			for _, item := range found {
+				log.Println("Found: ", item)
+			}
+			return
+		}
+		log.Printf("Searching monday, the index cannot answer: %s", err)
+	}
+	var searchLimit = *limit
+	if sort != rank.SORT_NONE {
+		searchLimit = 0
+	}
+	search, err := client.GetItemsInAllBoards(ctx, params, searchLimit)
+	if err != nil && *searchMirror != "" {
+		found, syncedAt, mirrorErr := searchFromMirror(ctx, nil, searchLimit)
+		if mirrorErr != nil {
+			log.Fatal(fmt.Errorf("Failed to search monday (%w) and the mirror: %w", err, mirrorErr))
+		}
+		log.Printf("monday cannot be reached (%s), results are from the mirror as of %s (%s ago)",
+			err, syncedAt.Local().Format(time.DateTime), time.Since(syncedAt).Round(time.Second))
+		if sort != rank.SORT_NONE {
+			found = rank.Items(rank.Rank(*value, found, sort))
+		}
+		if *limit > 0 && len(found) > *limit {
+			found = found[:*limit]
+		}
+		for _, item := range found {
+			log.Println("Found: ", item)
+		}
+		return
+	}
 	if err != nil {
 		panic(err)
 	}
-	for item := range items {
-		log.Println("Found: ", item)
+	if sort == rank.SORT_NONE {
+		var sent = 0
+		for item := range search.Items {
+			log.Println("Found: ", item)
+			sent++
+		}
+		if *limit > 0 && sent >= *limit {
+			return
+		}
+		for _, item := range searchFailed(ctx, search.Err()) {
+			if *limit > 0 && sent >= *limit {
+				break
+			}
+			log.Println("Found (from the mirror): ", item)
+			sent++
+		}
+		return
+	}
+	var results = rank.Collect(*value, search.Items, sort, *limit)
+	if stale := searchFailed(ctx, search.Err()); len(stale) > 0 {
+		results = rank.Rank(*value, append(rank.Items(results), stale...), sort)
+		if *limit > 0 && len(results) > *limit {
+			results = results[:*limit]
+		}
+	}
+	for _, result := range results {
+		log.Printf("Found (%s match in %s): %s", result.Match, result.Item.Board.Name, result.Item)
+	}
+}
+
+// searchFailed returns the matches in the mirror of the boards a search
+// failed to search, as told by err. It fails the command when there is no
+// mirror to fall back to.
+func searchFailed(ctx context.Context, err error) []monday.Item {
+	if err == nil {
+		return nil
+	}
+	if *searchMirror == "" {
+		log.Fatal(fmt.Errorf("Failed to search every board: %w", err))
+	}
+	found, syncedAt, mirrorErr := searchFromMirror(ctx, monday.FailedBoards(err), 0)
+	if mirrorErr != nil {
+		log.Fatal(fmt.Errorf("Failed to search every board (%w) and the mirror: %w", err, mirrorErr))
+	}
+	log.Printf("Some boards failed to be searched (%s), their results are from the mirror as of %s (%s ago)",
+		err, syncedAt.Local().Format(time.DateTime), time.Since(syncedAt).Round(time.Second))
+	return found
+}
+
+// searchFromMirror searches the mirror of --mirror, only the boards of
+// failed when there are some.
+func searchFromMirror(ctx context.Context, failed []monday.BoardListing, limit int) ([]monday.Item, time.Time, error) {
+	m, err := mirror.Open(*searchMirror)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer m.Close()
+	found, syncedAt, err := m.Search(ctx, *column, *value, limit)
+	if err != nil || len(failed) == 0 {
+		return found, syncedAt, err
 	}
+	found = slices.DeleteFunc(found, func(item monday.Item) bool {
+		return !slices.ContainsFunc(failed, func(board monday.BoardListing) bool { return fmt.Sprint(board.Id) == fmt.Sprint(item.Board.Id) })
+	})
+	return found, syncedAt, nil
 }
 
 func doAdd(client *monday.ApiClient) {
+	var boardName, groupName = client.ResolveBoard(*board, *group)
 	var request = monday.CreateItemRequest{
-		BoardName: strings.ToLower(*board),
-		GroupName: strings.ToLower(*group),
+		BoardName: strings.ToLower(boardName),
+		GroupName: strings.ToLower(groupName),
 		Name:      *name,
 		Email:     *email,
 		Phone:     *phone,
 	}
-	if err := client.CreateItem(context.Background(), request); err != nil {
+	ctx, printDryRun := dryRunContext(context.Background(), *addDryRun)
+	id, err := client.CreateItem(ctx, request)
+	if err != nil {
 		log.Fatal(fmt.Errorf("Failed to create item: %w", err))
 	}
+	if !*addDryRun {
+		log.Println("Created item", id)
+	}
+	printDryRun()
+}
+
+func doProvision(client *monday.ApiClient) {
+	spec, err := provision.Load(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+	ws, err := client.GetContactsWorkspace(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	plan, err := provision.NewPlan(ctx, client, ws, spec)
+	if err != nil {
+		log.Fatal(fmt.Errorf("Failed to plan board %s: %w", spec.Name, err))
+	}
+	fmt.Print(plan)
+	if !plan.Pending() {
+		fmt.Println("Nothing to do, board is up to date")
+		return
+	}
+	ctx, printDryRun := dryRunContext(ctx, *provisionDryRun)
+	if err := plan.Apply(ctx, client); err != nil {
+		log.Fatal(fmt.Errorf("Failed to provision board %s: %w", spec.Name, err))
+	}
+	if *provisionDryRun {
+		printDryRun()
+		return
+	}
+	fmt.Println("Board provisioned")
+}
+
+// dryRunContext records the mutations made with ctx rather than sending
+// them when dryRun is set, report then prints them.
+func dryRunContext(ctx context.Context, dryRun bool) (_ context.Context, report func()) {
+	if !dryRun {
+		return ctx, func() {}
+	}
+	ctx, recorded := monday.WithDryRun(ctx)
+	return ctx, func() {
+		var mutations = recorded.Mutations()
+		fmt.Printf("Dry run, %d mutation(s) not sent:\n", len(mutations))
+		for _, m := range mutations {
+			fmt.Printf("\n%s\n", m)
+		}
+	}
+}
+
+// doTUI browses and edits the contact boards interactively. Logs would
+// garble the screen, they go to -log or nowhere.
+func doTUI(client *monday.ApiClient) {
+	var out io.Writer = io.Discard
+	if *tuiLog != "" {
+		f, err := os.OpenFile(*tuiLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			log.Fatal(fmt.Errorf("failed to open log %s: %w", *tuiLog, err))
+		}
+		defer f.Close()
+		out = f
+	}
+	log.SetOutput(out)
+	defer log.SetOutput(os.Stderr)
+	if err := tui.Run(context.Background(), client); err != nil {
+		log.SetOutput(os.Stderr)
+		log.Fatal(err)
+	}
+}
+
+func doDescribe(client *monday.ApiClient) {
+	boards, err := client.DescribeBoards(context.Background(), *describeBoard)
+	if err != nil {
+		log.Fatal(fmt.Errorf("Failed to describe boards: %w", err))
+	}
+	if *asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(boards); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	for _, b := range boards {
+		fmt.Println(b)
+	}
+}
+
+func doServe(client *monday.ApiClient, cfg *config.Config) {
+	var serve = cfg.Serve
+	lis, err := net.Listen("tcp", serve.Addr)
+	if err != nil {
+		log.Fatal(fmt.Errorf("Failed to listen on %s: %w", serve.Addr, err))
+	}
+	var srv = server.New(client)
+	if serve.Index != "" {
+		idx, err := index.Open(serve.Index)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer idx.Close()
+		srv.UseIndex(idx)
+		syncer := index.NewSyncer(client, idx)
+		go syncer.Run(context.Background(), serve.SyncInterval)
+		if serve.WebhookAddr != "" {
+			signingSecret, err := cfg.WebhookSecret()
+			if err != nil {
+				log.Fatal(err)
+			}
+			syncer.UseSigningSecret(signingSecret)
+			mux := http.NewServeMux()
+			mux.Handle("/webhooks/monday", syncer)
+			go func() {
+				log.Printf("Receiving monday webhooks on %s", serve.WebhookAddr)
+				log.Fatal(http.ListenAndServe(serve.WebhookAddr, mux))
+			}()
+		}
+	}
+	if serve.Mirror != "" {
+		m, err := mirror.Open(serve.Mirror)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer m.Close()
+		srv.UseMirror(m)
+		go mirror.NewSyncer(client, m, serve.MirrorBoards).Run(context.Background(), serve.MirrorInterval)
+	}
+	if serve.Authz != "" {
+		policy, err := authz.Load(serve.Authz)
+		if err != nil {
+			log.Fatal(err)
+		}
+		srv.UsePolicy(policy)
+	}
+	var reconfigure = []func(monday.Settings){client.Configure}
+	var store *oauth.Store
+	if cfg.OAuth.Addr != "" {
+		oauthConfig, err := cfg.OAuthConfig()
+		if err != nil {
+			log.Fatal(err)
+		}
+		linkSecret, err := cfg.LinkSecret()
+		if err != nil {
+			log.Fatal(err)
+		}
+		store, err = oauth.OpenStore(cfg.OAuth.Accounts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer store.Close()
+		accounts := oauth.NewAccounts(store, cfg.Monday.URL, cfg.Settings())
+		srv.UseAccounts(accounts)
+		reconfigure = append(reconfigure, accounts.Configure)
+		go serveInstaller(oauth.NewInstaller(oauthConfig, accounts, linkSecret), cfg.OAuth.Addr, serve.TLS)
+	}
+	if serve.Tenants != "" {
+		registry := loadTenants(serve.Tenants, cfg, store)
+		srv.UseTenants(registry)
+		reconfigure = append(reconfigure, registry.Configure)
+	}
+	if serve.Audit != "" {
+		auditLog, err := audit.Open(serve.Audit)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer auditLog.Close()
+		srv.UseAudit(auditLog)
+	}
+	if serve.Idempotency != "" {
+		keys, err := idempotency.OpenStore(serve.Idempotency, serve.IdempotencyTTL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer keys.Close()
+		srv.UseIdempotency(keys)
+	}
+	if serve.Undo != "" {
+		operations, err := undo.OpenStore(serve.Undo, serve.UndoWindow)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer operations.Close()
+		srv.UseUndo(operations)
+	}
+	if serve.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle(rpcmetrics.METRICS_PATH, rpcmetrics.Handler())
+		go func() {
+			log.Printf("Serving metrics on %s%s", serve.MetricsAddr, rpcmetrics.METRICS_PATH)
+			log.Fatal(http.ListenAndServe(serve.MetricsAddr, mux))
+		}()
+	}
+	shutdown, err := tracing.Setup(context.Background(), "ops", serve.Trace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer shutdown(context.Background())
+	go reloadOnHangup(cfg, reconfigure)
+	// calls are traced and counted before they are authenticated, failures
+	// included
+	var interceptors = append(tracing.ServerOptions(), rpcmetrics.ServerOptions()...)
+	interceptors = append(interceptors, authOptions(serve)...)
+	grpcServer := grpc.NewServer(append(credsOptions(serve.TLS), interceptors...)...)
+	pb.RegisterMondayServiceServer(grpcServer, srv)
+	if serve.HTTPAddr != "" {
+		go serveGateway(srv, interceptors, serve)
+	}
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(pb.MondayService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	reflection.Register(grpcServer)
+	log.Printf("Serving MondayService on %s", lis.Addr())
+	if err := grpcServer.Serve(lis); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// reloadOnHangup reads the config again on every SIGHUP and passes the
+// settings that can change while serving to reconfigure.
+func reloadOnHangup(cfg *config.Config, reconfigure []func(monday.Settings)) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	for range hangup {
+		next, err := loadConfig()
+		if err != nil {
+			log.Println(fmt.Errorf("failed to reload config, keeping the current one: %w", err))
+			continue
+		}
+		applied, restart := cfg.Reload(next)
+		for _, configure := range reconfigure {
+			configure(cfg.Settings())
+		}
+		if len(applied) == 0 {
+			log.Println("Reloaded config, nothing changed")
+		} else {
+			log.Printf("Reloaded config, applied %s", strings.Join(applied, ", "))
+		}
+		if len(restart) > 0 {
+			log.Printf("Restart to apply %s", strings.Join(restart, ", "))
+		}
+	}
+}
+
+// loadTenants makes the registry of the tenants listed in path. The tokens
+// of the tenants not served from an OAuth account are read up front, for a
+// missing one to stop the server rather than fail its calls.
+func loadTenants(path string, cfg *config.Config, store *oauth.Store) *tenant.Registry {
+	tenants, err := tenant.Load(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registry, err := tenant.NewRegistry(tenants, cfg.Monday.URL, cfg.Settings(), store)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, t := range registry.Tenants() {
+		if err := t.Check(); err != nil {
+			log.Fatal(fmt.Errorf("failed to get the monday token of tenant %s: %w", t.Id, err))
+		}
+	}
+	log.Printf("Serving %d tenants from %s", len(registry.Tenants()), path)
+	return registry
+}
+
+// serveInstaller lets monday accounts install ops on addr, over TLS when
+// the gRPC server serves it. Client certificates are not asked for, the
+// installing users come with a browser.
+func serveInstaller(installer *oauth.Installer, addr string, tlsConfig config.TLS) {
+	log.Printf("Installing ops in monday accounts from %s%s", addr, oauth.INSTALL_PATH)
+	if tlsConfig.Cert == "" {
+		log.Fatal(http.ListenAndServe(addr, installer))
+	}
+	log.Fatal(http.ListenAndServeTLS(addr, tlsConfig.Cert, tlsConfig.Key, installer))
+}
+
+// credsOptions serves TLS or mutual TLS as configured.
+func credsOptions(tlsConfig config.TLS) []grpc.ServerOption {
+	var options = []grpc.ServerOption{}
+	if tlsConfig.Cert != "" {
+		creds, err := rpcauth.ServerTLS(tlsConfig.Cert, tlsConfig.Key, tlsConfig.ClientCA)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+	return options
+}
+
+// authOptions authenticates the clients of the clients file, if any.
+func authOptions(serve config.Serve) []grpc.ServerOption {
+	var options = []grpc.ServerOption{}
+	if serve.Clients != "" {
+		clients, err := rpcauth.LoadClients(serve.Clients)
+		if err != nil {
+			log.Fatal(err)
+		}
+		auth := rpcauth.NewAuthenticator(clients)
+		options = append(options, grpc.ChainUnaryInterceptor(auth.UnaryInterceptor()), grpc.ChainStreamInterceptor(auth.StreamInterceptor()))
+		if serve.TLS.Cert == "" {
+			log.Println("Tokens are sent in plaintext, serve TLS with --tls-cert and --tls-key")
+		}
+	}
+	return options
+}
+
+// serveGateway serves the REST/JSON gateway on the HTTP address. It calls
+// srv through an in-process gRPC server with the same interceptors, and
+// serves TLS with the same certificates as the gRPC server. The client
+// certificates it verifies are passed on to the in-process server.
+func serveGateway(srv pb.MondayServiceServer, interceptors []grpc.ServerOption, serve config.Serve) {
+	lis := bufconn.Listen(1 << 20)
+	peer := rpcauth.NewForwardedPeer()
+	inProcess := grpc.NewServer(append(peer.ServerOptions(), interceptors...)...)
+	pb.RegisterMondayServiceServer(inProcess, srv)
+	go inProcess.Serve(lis)
+	conn, err := grpc.NewClient("passthrough:///gateway",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatal(fmt.Errorf("Failed to connect the gateway: %w", err))
+	}
+	gw, err := gateway.New(pb.NewMondayServiceClient(conn))
+	if err != nil {
+		log.Fatal(err)
+	}
+	gw.UseForwardedPeer(peer)
+	var httpServer = &http.Server{Addr: serve.HTTPAddr, Handler: gw}
+	log.Printf("Serving the REST/JSON gateway on %s, OpenAPI at %s", serve.HTTPAddr, gateway.OPENAPI_PATH)
+	if serve.TLS.Cert == "" {
+		log.Fatal(httpServer.ListenAndServe())
+	}
+	httpServer.TLSConfig, err = rpcauth.ServerTLSConfig(serve.TLS.Cert, serve.TLS.Key, serve.TLS.ClientCA)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Fatal(httpServer.ListenAndServeTLS("", ""))
+}
+
+func doSync(client *monday.ApiClient) {
+	idx, err := index.Open(*syncIndex)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer idx.Close()
+	if err := index.NewSyncer(client, idx).FullSync(context.Background()); err != nil {
+		log.Fatal(fmt.Errorf("Failed to sync index: %w", err))
+	}
+}
+
+func doMirror(client *monday.ApiClient) {
+	m, err := mirror.Open(*mirrorPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer m.Close()
+	if err := mirror.NewSyncer(client, m, splitList(*mirrorOnly)).Sync(context.Background()); err != nil {
+		log.Fatal(fmt.Errorf("Failed to sync mirror: %w", err))
+	}
+}
+
+func doCerts() {
+	var hosts = splitList(*certsHosts)
+	if len(hosts) == 0 {
+		log.Fatal("Use --hosts to name the server")
+	}
+	if err := devcert.Generate(*certsDir, hosts, *certsClient, *certsValidFor); err != nil {
+		log.Fatal(fmt.Errorf("Failed to generate certificates: %w", err))
+	}
+	fmt.Printf("Wrote a CA, a server certificate for %s and a client certificate for %s to %s\n",
+		strings.Join(hosts, ", "), *certsClient, *certsDir)
+}
+
+// doValidate checks the config as serve would read it, token included.
+func doValidate() {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var source = cfg.Path()
+	if source == "" {
+		source = "The config of the defaults and environment"
+	}
+	var errs = []error{cfg.Validate()}
+	if err := errs[0]; err == nil {
+		tokens := cfg.TokenSource()
+		if _, err := tokens.Token(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to get the monday token from %s: %w", tokens, err))
+		}
+	}
+	if cfg.Serve.Tenants != "" {
+		if _, err := tenant.Load(cfg.Serve.Tenants); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		fmt.Printf("%s is not valid:\n%s\n", source, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s is valid\n", source)
+}
+
+// doStoreToken keeps the token read from stdin in the keyring of the config,
+// for a monday.token.source of keyring.
+func doStoreToken() {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintln(os.Stderr, "Paste the monday API token and press enter:")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		log.Fatal(fmt.Errorf("failed to read token: %w", err))
+	}
+	var token = strings.TrimSpace(line)
+	if token == "" {
+		log.Fatal("No token given")
+	}
+	var keyring = cfg.Monday.Token
+	where, err := secret.Store(keyring.Keyring, keyring.KeyringUser, keyring.Fallback, token)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Stored the monday token in %s\n", where)
+	if keyring.Source != secret.SOURCE_KEYRING {
+		fmt.Printf("Set monday.token.source to %s to use it\n", secret.SOURCE_KEYRING)
+	}
+}
+
+func doAudit() {
+	var filter = audit.Filter{User: *auditUser, Board: *auditBoard, Limit: *auditLimit}
+	var err error
+	if filter.Since, err = parseTime(*auditSince); err != nil {
+		log.Fatal(err)
+	}
+	if filter.Until, err = parseTime(*auditUntil); err != nil {
+		log.Fatal(err)
+	}
+	auditLog, err := audit.Open(*auditPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer auditLog.Close()
+	records, err := auditLog.Query(context.Background(), filter)
+	if err != nil {
+		log.Fatal(err)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	for _, record := range records {
+		if *auditJson {
+			encoder.Encode(record)
+			continue
+		}
+		fmt.Println(record)
+	}
+}
+
+// parseTime reads a time given either as a duration ago, e.g. 24h or
+// 24h ago, or a date, e.g. 2024-05-01, or an RFC 3339 time. An empty value
+// is the zero time.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ago, err := time.ParseDuration(strings.TrimSuffix(strings.TrimSpace(value), " ago")); err == nil {
+		return time.Now().Add(-ago), nil
+	}
+	if at, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return at, nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %s, expected a duration ago, a date or an RFC 3339 time", value)
+	}
+	return at, nil
+}
+
+func splitList(list string) []string {
+	var values = []string{}
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
 }
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	var now = time.Now()
+	for _, tc := range []struct {
+		value string
+		want  time.Time
+	}{
+		{"", time.Time{}},
+		{"24h", now.Add(-24 * time.Hour)},
+		{"24h ago", now.Add(-24 * time.Hour)},
+		{"1h30m ago", now.Add(-90 * time.Minute)},
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+		{"2024-05-02T12:00:00Z", time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)},
+	} {
+		got, err := parseTime(tc.value)
+		if err != nil {
+			t.Errorf("parseTime(%q): %v", tc.value, err)
+			continue
+		}
+		if d := got.Sub(tc.want); d < -time.Minute || d > time.Minute {
+			t.Errorf("parseTime(%q) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+	for _, value := range []string{"ago", "yesterday", "24 ago", "2024-13-01"} {
+		if _, err := parseTime(value); err == nil {
+			t.Errorf("parseTime(%q) accepted", value)
+		}
+	}
+}
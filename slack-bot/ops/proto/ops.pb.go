@@ -9,6 +9,7 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,10 +22,121 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Sort int32
+
+const (
+	// items in the order the boards answered
+	Sort_SORT_NONE Sort = 0
+	// exact, then prefix, then fuzzy matches of the value on name, email and phone
+	Sort_SORT_RELEVANCE Sort = 1
+	Sort_SORT_NAME      Sort = 2
+)
+
+// Enum value maps for Sort.
+var (
+	Sort_name = map[int32]string{
+		0: "SORT_NONE",
+		1: "SORT_RELEVANCE",
+		2: "SORT_NAME",
+	}
+	Sort_value = map[string]int32{
+		"SORT_NONE":      0,
+		"SORT_RELEVANCE": 1,
+		"SORT_NAME":      2,
+	}
+)
+
+func (x Sort) Enum() *Sort {
+	p := new(Sort)
+	*p = x
+	return p
+}
+
+func (x Sort) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Sort) Descriptor() protoreflect.EnumDescriptor {
+	return file_ops_proto_enumTypes[0].Descriptor()
+}
+
+func (Sort) Type() protoreflect.EnumType {
+	return &file_ops_proto_enumTypes[0]
+}
+
+func (x Sort) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Sort.Descriptor instead.
+func (Sort) EnumDescriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{0}
+}
+
+type Source int32
+
+const (
+	Source_SOURCE_LIVE  Source = 0
+	Source_SOURCE_INDEX Source = 1
+	// served from the offline mirror because monday could not be reached
+	Source_SOURCE_MIRROR Source = 2
+)
+
+// Enum value maps for Source.
+var (
+	Source_name = map[int32]string{
+		0: "SOURCE_LIVE",
+		1: "SOURCE_INDEX",
+		2: "SOURCE_MIRROR",
+	}
+	Source_value = map[string]int32{
+		"SOURCE_LIVE":   0,
+		"SOURCE_INDEX":  1,
+		"SOURCE_MIRROR": 2,
+	}
+)
+
+func (x Source) Enum() *Source {
+	p := new(Source)
+	*p = x
+	return p
+}
+
+func (x Source) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Source) Descriptor() protoreflect.EnumDescriptor {
+	return file_ops_proto_enumTypes[1].Descriptor()
+}
+
+func (Source) Type() protoreflect.EnumType {
+	return &file_ops_proto_enumTypes[1]
+}
+
+func (x Source) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Source.Descriptor instead.
+func (Source) EnumDescriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{1}
+}
+
 type FindItemRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Column        string                 `protobuf:"bytes,1,opt,name=column,proto3" json:"column,omitempty"`
-	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Column string                 `protobuf:"bytes,1,opt,name=column,proto3" json:"column,omitempty"`
+	Value  string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// stop searching once this many items were found, 0 for no limit.
+	// When sorting, every board is searched and the limit applies to the sorted items.
+	Limit int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// any sort other than SORT_NONE also drops contacts duplicated across boards
+	Sort Sort `protobuf:"varint,4,opt,name=sort,proto3,enum=ops.proto.Sort" json:"sort,omitempty"`
+	// skip the local index, if the server has one, and query monday directly
+	Live bool `protobuf:"varint,5,opt,name=live,proto3" json:"live,omitempty"`
+	// only search the board with this name, before the limit applies; every
+	// board when empty
+	Board         string `protobuf:"bytes,6,opt,name=board,proto3" json:"board,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,11 +185,41 @@ func (x *FindItemRequest) GetValue() string {
 	return ""
 }
 
+func (x *FindItemRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *FindItemRequest) GetSort() Sort {
+	if x != nil {
+		return x.Sort
+	}
+	return Sort_SORT_NONE
+}
+
+func (x *FindItemRequest) GetLive() bool {
+	if x != nil {
+		return x.Live
+	}
+	return false
+}
+
+func (x *FindItemRequest) GetBoard() string {
+	if x != nil {
+		return x.Board
+	}
+	return ""
+}
+
 type ColumnMeta struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Type  string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	// status labels or dropdown options, in monday's order
+	Labels        []string `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -133,6 +275,65 @@ func (x *ColumnMeta) GetType() string {
 	return ""
 }
 
+func (x *ColumnMeta) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type GroupMeta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupMeta) Reset() {
+	*x = GroupMeta{}
+	mi := &file_ops_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupMeta) ProtoMessage() {}
+
+func (x *GroupMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupMeta.ProtoReflect.Descriptor instead.
+func (*GroupMeta) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GroupMeta) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GroupMeta) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
 type Column struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -144,7 +345,7 @@ type Column struct {
 
 func (x *Column) Reset() {
 	*x = Column{}
-	mi := &file_ops_proto_msgTypes[2]
+	mi := &file_ops_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -156,7 +357,7 @@ func (x *Column) String() string {
 func (*Column) ProtoMessage() {}
 
 func (x *Column) ProtoReflect() protoreflect.Message {
-	mi := &file_ops_proto_msgTypes[2]
+	mi := &file_ops_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -169,7 +370,7 @@ func (x *Column) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Column.ProtoReflect.Descriptor instead.
 func (*Column) Descriptor() ([]byte, []int) {
-	return file_ops_proto_rawDescGZIP(), []int{2}
+	return file_ops_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Column) GetId() string {
@@ -194,18 +395,26 @@ func (x *Column) GetMeta() *ColumnMeta {
 }
 
 type FindItemResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Group         string                 `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
-	Columns       []*Column              `protobuf:"bytes,4,rep,name=columns,proto3" json:"columns,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name    string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Group   string                 `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	Columns []*Column              `protobuf:"bytes,4,rep,name=columns,proto3" json:"columns,omitempty"`
+	Board   string                 `protobuf:"bytes,5,opt,name=board,proto3" json:"board,omitempty"`
+	Source  Source                 `protobuf:"varint,6,opt,name=source,proto3,enum=ops.proto.Source" json:"source,omitempty"`
+	// when the local copy the item comes from was last synced, unset for live results
+	SyncedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=synced_at,json=syncedAt,proto3" json:"synced_at,omitempty"`
+	Email    string                 `protobuf:"bytes,8,opt,name=email,proto3" json:"email,omitempty"`
+	Phone    string                 `protobuf:"bytes,9,opt,name=phone,proto3" json:"phone,omitempty"`
+	// link to the item in monday, unset for items synced before it was recorded
+	Url           string `protobuf:"bytes,10,opt,name=url,proto3" json:"url,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *FindItemResponse) Reset() {
 	*x = FindItemResponse{}
-	mi := &file_ops_proto_msgTypes[3]
+	mi := &file_ops_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -217,7 +426,7 @@ func (x *FindItemResponse) String() string {
 func (*FindItemResponse) ProtoMessage() {}
 
 func (x *FindItemResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_ops_proto_msgTypes[3]
+	mi := &file_ops_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -230,7 +439,7 @@ func (x *FindItemResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FindItemResponse.ProtoReflect.Descriptor instead.
 func (*FindItemResponse) Descriptor() ([]byte, []int) {
-	return file_ops_proto_rawDescGZIP(), []int{3}
+	return file_ops_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *FindItemResponse) GetId() string {
@@ -261,20 +470,70 @@ func (x *FindItemResponse) GetColumns() []*Column {
 	return nil
 }
 
+func (x *FindItemResponse) GetBoard() string {
+	if x != nil {
+		return x.Board
+	}
+	return ""
+}
+
+func (x *FindItemResponse) GetSource() Source {
+	if x != nil {
+		return x.Source
+	}
+	return Source_SOURCE_LIVE
+}
+
+func (x *FindItemResponse) GetSyncedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SyncedAt
+	}
+	return nil
+}
+
+func (x *FindItemResponse) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *FindItemResponse) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *FindItemResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
 type CreateItemRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Board         string                 `protobuf:"bytes,1,opt,name=board,proto3" json:"board,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
-	Phone         string                 `protobuf:"bytes,4,opt,name=phone,proto3" json:"phone,omitempty"`
-	Group         string                 `protobuf:"bytes,5,opt,name=group,proto3" json:"group,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the default board of ops, if it has one, when empty
+	Board string `protobuf:"bytes,1,opt,name=board,proto3" json:"board,omitempty"`
+	Name  string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Email string `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Phone string `protobuf:"bytes,4,opt,name=phone,proto3" json:"phone,omitempty"`
+	Group string `protobuf:"bytes,5,opt,name=group,proto3" json:"group,omitempty"`
+	// other column values, keyed by column id or title
+	Columns map[string]string `protobuf:"bytes,6,rep,name=columns,proto3" json:"columns,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// resolve the board, group and columns and return the mutation without sending it
+	DryRun bool `protobuf:"varint,7,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// names the request for its retries to create the item once: a key seen
+	// before returns the item it created, when the server keeps the keys
+	IdempotencyKey string `protobuf:"bytes,8,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *CreateItemRequest) Reset() {
 	*x = CreateItemRequest{}
-	mi := &file_ops_proto_msgTypes[4]
+	mi := &file_ops_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -286,7 +545,7 @@ func (x *CreateItemRequest) String() string {
 func (*CreateItemRequest) ProtoMessage() {}
 
 func (x *CreateItemRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_ops_proto_msgTypes[4]
+	mi := &file_ops_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -299,7 +558,7 @@ func (x *CreateItemRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateItemRequest.ProtoReflect.Descriptor instead.
 func (*CreateItemRequest) Descriptor() ([]byte, []int) {
-	return file_ops_proto_rawDescGZIP(), []int{4}
+	return file_ops_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *CreateItemRequest) GetBoard() string {
@@ -337,16 +596,105 @@ func (x *CreateItemRequest) GetGroup() string {
 	return ""
 }
 
+func (x *CreateItemRequest) GetColumns() map[string]string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *CreateItemRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *CreateItemRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+// Mutation is a GraphQL mutation as it would have been sent to monday.
+type Mutation struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Operation string                 `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"`
+	Query     string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	// variables of the query as JSON, column_values encoded as monday expects them
+	Variables     string `protobuf:"bytes,3,opt,name=variables,proto3" json:"variables,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Mutation) Reset() {
+	*x = Mutation{}
+	mi := &file_ops_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mutation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mutation) ProtoMessage() {}
+
+func (x *Mutation) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mutation.ProtoReflect.Descriptor instead.
+func (*Mutation) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Mutation) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *Mutation) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *Mutation) GetVariables() string {
+	if x != nil {
+		return x.Variables
+	}
+	return ""
+}
+
 type CreateItemResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// on a dry run, the mutation that was not sent
+	DryRun *Mutation `protobuf:"bytes,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// set when the idempotency key was seen before and no item was created
+	Replayed bool `protobuf:"varint,3,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	// reverts the creation with Revert, when the server keeps operations
+	OperationId   string `protobuf:"bytes,4,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateItemResponse) Reset() {
 	*x = CreateItemResponse{}
-	mi := &file_ops_proto_msgTypes[5]
+	mi := &file_ops_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -358,7 +706,7 @@ func (x *CreateItemResponse) String() string {
 func (*CreateItemResponse) ProtoMessage() {}
 
 func (x *CreateItemResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_ops_proto_msgTypes[5]
+	mi := &file_ops_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -371,7 +719,7 @@ func (x *CreateItemResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateItemResponse.ProtoReflect.Descriptor instead.
 func (*CreateItemResponse) Descriptor() ([]byte, []int) {
-	return file_ops_proto_rawDescGZIP(), []int{5}
+	return file_ops_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *CreateItemResponse) GetId() string {
@@ -381,40 +729,819 @@ func (x *CreateItemResponse) GetId() string {
 	return ""
 }
 
-var File_ops_proto protoreflect.FileDescriptor
+func (x *CreateItemResponse) GetDryRun() *Mutation {
+	if x != nil {
+		return x.DryRun
+	}
+	return nil
+}
 
-const file_ops_proto_rawDesc = "" +
-	"\n" +
-	"\tops.proto\x12\tops.proto\"?\n" +
-	"\x0fFindItemRequest\x12\x16\n" +
-	"\x06column\x18\x01 \x01(\tR\x06column\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value\"F\n" +
-	"\n" +
-	"ColumnMeta\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
-	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
-	"\x04type\x18\x03 \x01(\tR\x04type\"Y\n" +
-	"\x06Column\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value\x12)\n" +
-	"\x04meta\x18\x03 \x01(\v2\x15.ops.proto.ColumnMetaR\x04meta\"y\n" +
-	"\x10FindItemResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05group\x18\x03 \x01(\tR\x05group\x12+\n" +
-	"\acolumns\x18\x04 \x03(\v2\x11.ops.proto.ColumnR\acolumns\"\x7f\n" +
-	"\x11CreateItemRequest\x12\x14\n" +
-	"\x05board\x18\x01 \x01(\tR\x05board\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05email\x18\x03 \x01(\tR\x05email\x12\x14\n" +
-	"\x05phone\x18\x04 \x01(\tR\x05phone\x12\x14\n" +
-	"\x05group\x18\x05 \x01(\tR\x05group\"$\n" +
-	"\x12CreateItemResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id2\xa1\x01\n" +
+func (x *CreateItemResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+func (x *CreateItemResponse) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+type UpdateItemRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// renames the item when not empty
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// new column values, keyed by column id or title; an empty value clears the column
+	Columns map[string]string `protobuf:"bytes,3,rep,name=columns,proto3" json:"columns,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// resolve the item and columns and return the mutation without sending it
+	DryRun        bool `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateItemRequest) Reset() {
+	*x = UpdateItemRequest{}
+	mi := &file_ops_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateItemRequest) ProtoMessage() {}
+
+func (x *UpdateItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateItemRequest.ProtoReflect.Descriptor instead.
+func (*UpdateItemRequest) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateItemRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateItemRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateItemRequest) GetColumns() map[string]string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *UpdateItemRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type UpdateItemResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// on a dry run, the mutation that was not sent, unset when nothing would change
+	DryRun *Mutation `protobuf:"bytes,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// restores the name and column values the update changed with Revert,
+	// when the server keeps operations
+	OperationId   string `protobuf:"bytes,3,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateItemResponse) Reset() {
+	*x = UpdateItemResponse{}
+	mi := &file_ops_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateItemResponse) ProtoMessage() {}
+
+func (x *UpdateItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateItemResponse.ProtoReflect.Descriptor instead.
+func (*UpdateItemResponse) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateItemResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateItemResponse) GetDryRun() *Mutation {
+	if x != nil {
+		return x.DryRun
+	}
+	return nil
+}
+
+func (x *UpdateItemResponse) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+type ArchiveItemRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// return the mutation without sending it
+	DryRun        bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchiveItemRequest) Reset() {
+	*x = ArchiveItemRequest{}
+	mi := &file_ops_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveItemRequest) ProtoMessage() {}
+
+func (x *ArchiveItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveItemRequest.ProtoReflect.Descriptor instead.
+func (*ArchiveItemRequest) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ArchiveItemRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ArchiveItemRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type ArchiveItemResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// on a dry run, the mutation that was not sent
+	DryRun        *Mutation `protobuf:"bytes,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchiveItemResponse) Reset() {
+	*x = ArchiveItemResponse{}
+	mi := &file_ops_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveItemResponse) ProtoMessage() {}
+
+func (x *ArchiveItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveItemResponse.ProtoReflect.Descriptor instead.
+func (*ArchiveItemResponse) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ArchiveItemResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ArchiveItemResponse) GetDryRun() *Mutation {
+	if x != nil {
+		return x.DryRun
+	}
+	return nil
+}
+
+type AddNoteRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	ItemId string                 `protobuf:"bytes,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Body   string                 `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	// return the mutation without sending it
+	DryRun        bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddNoteRequest) Reset() {
+	*x = AddNoteRequest{}
+	mi := &file_ops_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddNoteRequest) ProtoMessage() {}
+
+func (x *AddNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddNoteRequest.ProtoReflect.Descriptor instead.
+func (*AddNoteRequest) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AddNoteRequest) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+func (x *AddNoteRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *AddNoteRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type AddNoteResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id of the update posted on the item
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// on a dry run, the mutation that was not sent
+	DryRun *Mutation `protobuf:"bytes,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// deletes the update with Revert, when the server keeps operations
+	OperationId   string `protobuf:"bytes,3,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddNoteResponse) Reset() {
+	*x = AddNoteResponse{}
+	mi := &file_ops_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddNoteResponse) ProtoMessage() {}
+
+func (x *AddNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddNoteResponse.ProtoReflect.Descriptor instead.
+func (*AddNoteResponse) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AddNoteResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AddNoteResponse) GetDryRun() *Mutation {
+	if x != nil {
+		return x.DryRun
+	}
+	return nil
+}
+
+func (x *AddNoteResponse) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+type RevertRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// operation_id of a CreateItem, UpdateItem or AddNote response; empty
+	// for the last change of the caller not reverted yet
+	OperationId string `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	// return the mutation without sending it
+	DryRun        bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevertRequest) Reset() {
+	*x = RevertRequest{}
+	mi := &file_ops_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevertRequest) ProtoMessage() {}
+
+func (x *RevertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevertRequest.ProtoReflect.Descriptor instead.
+func (*RevertRequest) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RevertRequest) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+func (x *RevertRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type RevertResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	OperationId string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	// the RPC of the change reverted: CreateItem, UpdateItem or AddNote
+	Method string `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	ItemId string `protobuf:"bytes,3,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Board  string `protobuf:"bytes,4,opt,name=board,proto3" json:"board,omitempty"`
+	// on a dry run, the mutation that was not sent
+	DryRun        *Mutation `protobuf:"bytes,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevertResponse) Reset() {
+	*x = RevertResponse{}
+	mi := &file_ops_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevertResponse) ProtoMessage() {}
+
+func (x *RevertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevertResponse.ProtoReflect.Descriptor instead.
+func (*RevertResponse) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RevertResponse) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+func (x *RevertResponse) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *RevertResponse) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+func (x *RevertResponse) GetBoard() string {
+	if x != nil {
+		return x.Board
+	}
+	return ""
+}
+
+func (x *RevertResponse) GetDryRun() *Mutation {
+	if x != nil {
+		return x.DryRun
+	}
+	return nil
+}
+
+type DescribeBoardRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// empty to describe every board in the workspace
+	Board         string `protobuf:"bytes,1,opt,name=board,proto3" json:"board,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeBoardRequest) Reset() {
+	*x = DescribeBoardRequest{}
+	mi := &file_ops_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeBoardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeBoardRequest) ProtoMessage() {}
+
+func (x *DescribeBoardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeBoardRequest.ProtoReflect.Descriptor instead.
+func (*DescribeBoardRequest) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DescribeBoardRequest) GetBoard() string {
+	if x != nil {
+		return x.Board
+	}
+	return ""
+}
+
+type BoardDescription struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Kind          string                 `protobuf:"bytes,4,opt,name=kind,proto3" json:"kind,omitempty"`
+	Columns       []*ColumnMeta          `protobuf:"bytes,5,rep,name=columns,proto3" json:"columns,omitempty"`
+	Groups        []*GroupMeta           `protobuf:"bytes,6,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BoardDescription) Reset() {
+	*x = BoardDescription{}
+	mi := &file_ops_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BoardDescription) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BoardDescription) ProtoMessage() {}
+
+func (x *BoardDescription) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BoardDescription.ProtoReflect.Descriptor instead.
+func (*BoardDescription) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BoardDescription) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BoardDescription) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BoardDescription) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *BoardDescription) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *BoardDescription) GetColumns() []*ColumnMeta {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *BoardDescription) GetGroups() []*GroupMeta {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type DescribeBoardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Boards        []*BoardDescription    `protobuf:"bytes,1,rep,name=boards,proto3" json:"boards,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeBoardResponse) Reset() {
+	*x = DescribeBoardResponse{}
+	mi := &file_ops_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeBoardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeBoardResponse) ProtoMessage() {}
+
+func (x *DescribeBoardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ops_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeBoardResponse.ProtoReflect.Descriptor instead.
+func (*DescribeBoardResponse) Descriptor() ([]byte, []int) {
+	return file_ops_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DescribeBoardResponse) GetBoards() []*BoardDescription {
+	if x != nil {
+		return x.Boards
+	}
+	return nil
+}
+
+var File_ops_proto protoreflect.FileDescriptor
+
+const file_ops_proto_rawDesc = "" +
+	"\n" +
+	"\tops.proto\x12\tops.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa4\x01\n" +
+	"\x0fFindItemRequest\x12\x16\n" +
+	"\x06column\x18\x01 \x01(\tR\x06column\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12#\n" +
+	"\x04sort\x18\x04 \x01(\x0e2\x0f.ops.proto.SortR\x04sort\x12\x12\n" +
+	"\x04live\x18\x05 \x01(\bR\x04live\x12\x14\n" +
+	"\x05board\x18\x06 \x01(\tR\x05board\"^\n" +
+	"\n" +
+	"ColumnMeta\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x16\n" +
+	"\x06labels\x18\x04 \x03(\tR\x06labels\"1\n" +
+	"\tGroupMeta\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\"Y\n" +
+	"\x06Column\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12)\n" +
+	"\x04meta\x18\x03 \x01(\v2\x15.ops.proto.ColumnMetaR\x04meta\"\xb1\x02\n" +
+	"\x10FindItemResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05group\x18\x03 \x01(\tR\x05group\x12+\n" +
+	"\acolumns\x18\x04 \x03(\v2\x11.ops.proto.ColumnR\acolumns\x12\x14\n" +
+	"\x05board\x18\x05 \x01(\tR\x05board\x12)\n" +
+	"\x06source\x18\x06 \x01(\x0e2\x11.ops.proto.SourceR\x06source\x127\n" +
+	"\tsynced_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\bsyncedAt\x12\x14\n" +
+	"\x05email\x18\b \x01(\tR\x05email\x12\x14\n" +
+	"\x05phone\x18\t \x01(\tR\x05phone\x12\x10\n" +
+	"\x03url\x18\n" +
+	" \x01(\tR\x03url\"\xc2\x02\n" +
+	"\x11CreateItemRequest\x12\x14\n" +
+	"\x05board\x18\x01 \x01(\tR\x05board\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x14\n" +
+	"\x05phone\x18\x04 \x01(\tR\x05phone\x12\x14\n" +
+	"\x05group\x18\x05 \x01(\tR\x05group\x12C\n" +
+	"\acolumns\x18\x06 \x03(\v2).ops.proto.CreateItemRequest.ColumnsEntryR\acolumns\x12\x17\n" +
+	"\adry_run\x18\a \x01(\bR\x06dryRun\x12'\n" +
+	"\x0fidempotency_key\x18\b \x01(\tR\x0eidempotencyKey\x1a:\n" +
+	"\fColumnsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\\\n" +
+	"\bMutation\x12\x1c\n" +
+	"\toperation\x18\x01 \x01(\tR\toperation\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x1c\n" +
+	"\tvariables\x18\x03 \x01(\tR\tvariables\"\x91\x01\n" +
+	"\x12CreateItemResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12,\n" +
+	"\adry_run\x18\x02 \x01(\v2\x13.ops.proto.MutationR\x06dryRun\x12\x1a\n" +
+	"\breplayed\x18\x03 \x01(\bR\breplayed\x12!\n" +
+	"\foperation_id\x18\x04 \x01(\tR\voperationId\"\xd1\x01\n" +
+	"\x11UpdateItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12C\n" +
+	"\acolumns\x18\x03 \x03(\v2).ops.proto.UpdateItemRequest.ColumnsEntryR\acolumns\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\x1a:\n" +
+	"\fColumnsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"u\n" +
+	"\x12UpdateItemResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12,\n" +
+	"\adry_run\x18\x02 \x01(\v2\x13.ops.proto.MutationR\x06dryRun\x12!\n" +
+	"\foperation_id\x18\x03 \x01(\tR\voperationId\"=\n" +
+	"\x12ArchiveItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"S\n" +
+	"\x13ArchiveItemResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12,\n" +
+	"\adry_run\x18\x02 \x01(\v2\x13.ops.proto.MutationR\x06dryRun\"V\n" +
+	"\x0eAddNoteRequest\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\tR\x06itemId\x12\x12\n" +
+	"\x04body\x18\x02 \x01(\tR\x04body\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\"r\n" +
+	"\x0fAddNoteResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12,\n" +
+	"\adry_run\x18\x02 \x01(\v2\x13.ops.proto.MutationR\x06dryRun\x12!\n" +
+	"\foperation_id\x18\x03 \x01(\tR\voperationId\"K\n" +
+	"\rRevertRequest\x12!\n" +
+	"\foperation_id\x18\x01 \x01(\tR\voperationId\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"\xa8\x01\n" +
+	"\x0eRevertResponse\x12!\n" +
+	"\foperation_id\x18\x01 \x01(\tR\voperationId\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12\x17\n" +
+	"\aitem_id\x18\x03 \x01(\tR\x06itemId\x12\x14\n" +
+	"\x05board\x18\x04 \x01(\tR\x05board\x12,\n" +
+	"\adry_run\x18\x05 \x01(\v2\x13.ops.proto.MutationR\x06dryRun\",\n" +
+	"\x14DescribeBoardRequest\x12\x14\n" +
+	"\x05board\x18\x01 \x01(\tR\x05board\"\xcb\x01\n" +
+	"\x10BoardDescription\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04kind\x18\x04 \x01(\tR\x04kind\x12/\n" +
+	"\acolumns\x18\x05 \x03(\v2\x15.ops.proto.ColumnMetaR\acolumns\x12,\n" +
+	"\x06groups\x18\x06 \x03(\v2\x14.ops.proto.GroupMetaR\x06groups\"L\n" +
+	"\x15DescribeBoardResponse\x123\n" +
+	"\x06boards\x18\x01 \x03(\v2\x1b.ops.proto.BoardDescriptionR\x06boards*8\n" +
+	"\x04Sort\x12\r\n" +
+	"\tSORT_NONE\x10\x00\x12\x12\n" +
+	"\x0eSORT_RELEVANCE\x10\x01\x12\r\n" +
+	"\tSORT_NAME\x10\x02*>\n" +
+	"\x06Source\x12\x0f\n" +
+	"\vSOURCE_LIVE\x10\x00\x12\x10\n" +
+	"\fSOURCE_INDEX\x10\x01\x12\x11\n" +
+	"\rSOURCE_MIRROR\x10\x022\x8f\x04\n" +
 	"\rMondayService\x12E\n" +
 	"\bFindItem\x12\x1a.ops.proto.FindItemRequest\x1a\x1b.ops.proto.FindItemResponse0\x01\x12I\n" +
 	"\n" +
-	"CreateItem\x12\x1c.ops.proto.CreateItemRequest\x1a\x1d.ops.proto.CreateItemResponseB3Z1github.com/CatalinCaprita/SPO/slack-bot/ops/protob\x06proto3"
+	"CreateItem\x12\x1c.ops.proto.CreateItemRequest\x1a\x1d.ops.proto.CreateItemResponse\x12R\n" +
+	"\rDescribeBoard\x12\x1f.ops.proto.DescribeBoardRequest\x1a .ops.proto.DescribeBoardResponse\x12I\n" +
+	"\n" +
+	"UpdateItem\x12\x1c.ops.proto.UpdateItemRequest\x1a\x1d.ops.proto.UpdateItemResponse\x12L\n" +
+	"\vArchiveItem\x12\x1d.ops.proto.ArchiveItemRequest\x1a\x1e.ops.proto.ArchiveItemResponse\x12@\n" +
+	"\aAddNote\x12\x19.ops.proto.AddNoteRequest\x1a\x1a.ops.proto.AddNoteResponse\x12=\n" +
+	"\x06Revert\x12\x18.ops.proto.RevertRequest\x1a\x19.ops.proto.RevertResponseB3Z1github.com/CatalinCaprita/SPO/slack-bot/ops/protob\x06proto3"
 
 var (
 	file_ops_proto_rawDescOnce sync.Once
@@ -428,27 +1555,69 @@ func file_ops_proto_rawDescGZIP() []byte {
 	return file_ops_proto_rawDescData
 }
 
-var file_ops_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_ops_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_ops_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_ops_proto_goTypes = []any{
-	(*FindItemRequest)(nil),    // 0: ops.proto.FindItemRequest
-	(*ColumnMeta)(nil),         // 1: ops.proto.ColumnMeta
-	(*Column)(nil),             // 2: ops.proto.Column
-	(*FindItemResponse)(nil),   // 3: ops.proto.FindItemResponse
-	(*CreateItemRequest)(nil),  // 4: ops.proto.CreateItemRequest
-	(*CreateItemResponse)(nil), // 5: ops.proto.CreateItemResponse
+	(Sort)(0),                     // 0: ops.proto.Sort
+	(Source)(0),                   // 1: ops.proto.Source
+	(*FindItemRequest)(nil),       // 2: ops.proto.FindItemRequest
+	(*ColumnMeta)(nil),            // 3: ops.proto.ColumnMeta
+	(*GroupMeta)(nil),             // 4: ops.proto.GroupMeta
+	(*Column)(nil),                // 5: ops.proto.Column
+	(*FindItemResponse)(nil),      // 6: ops.proto.FindItemResponse
+	(*CreateItemRequest)(nil),     // 7: ops.proto.CreateItemRequest
+	(*Mutation)(nil),              // 8: ops.proto.Mutation
+	(*CreateItemResponse)(nil),    // 9: ops.proto.CreateItemResponse
+	(*UpdateItemRequest)(nil),     // 10: ops.proto.UpdateItemRequest
+	(*UpdateItemResponse)(nil),    // 11: ops.proto.UpdateItemResponse
+	(*ArchiveItemRequest)(nil),    // 12: ops.proto.ArchiveItemRequest
+	(*ArchiveItemResponse)(nil),   // 13: ops.proto.ArchiveItemResponse
+	(*AddNoteRequest)(nil),        // 14: ops.proto.AddNoteRequest
+	(*AddNoteResponse)(nil),       // 15: ops.proto.AddNoteResponse
+	(*RevertRequest)(nil),         // 16: ops.proto.RevertRequest
+	(*RevertResponse)(nil),        // 17: ops.proto.RevertResponse
+	(*DescribeBoardRequest)(nil),  // 18: ops.proto.DescribeBoardRequest
+	(*BoardDescription)(nil),      // 19: ops.proto.BoardDescription
+	(*DescribeBoardResponse)(nil), // 20: ops.proto.DescribeBoardResponse
+	nil,                           // 21: ops.proto.CreateItemRequest.ColumnsEntry
+	nil,                           // 22: ops.proto.UpdateItemRequest.ColumnsEntry
+	(*timestamppb.Timestamp)(nil), // 23: google.protobuf.Timestamp
 }
 var file_ops_proto_depIdxs = []int32{
-	1, // 0: ops.proto.Column.meta:type_name -> ops.proto.ColumnMeta
-	2, // 1: ops.proto.FindItemResponse.columns:type_name -> ops.proto.Column
-	0, // 2: ops.proto.MondayService.FindItem:input_type -> ops.proto.FindItemRequest
-	4, // 3: ops.proto.MondayService.CreateItem:input_type -> ops.proto.CreateItemRequest
-	3, // 4: ops.proto.MondayService.FindItem:output_type -> ops.proto.FindItemResponse
-	5, // 5: ops.proto.MondayService.CreateItem:output_type -> ops.proto.CreateItemResponse
-	4, // [4:6] is the sub-list for method output_type
-	2, // [2:4] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	0,  // 0: ops.proto.FindItemRequest.sort:type_name -> ops.proto.Sort
+	3,  // 1: ops.proto.Column.meta:type_name -> ops.proto.ColumnMeta
+	5,  // 2: ops.proto.FindItemResponse.columns:type_name -> ops.proto.Column
+	1,  // 3: ops.proto.FindItemResponse.source:type_name -> ops.proto.Source
+	23, // 4: ops.proto.FindItemResponse.synced_at:type_name -> google.protobuf.Timestamp
+	21, // 5: ops.proto.CreateItemRequest.columns:type_name -> ops.proto.CreateItemRequest.ColumnsEntry
+	8,  // 6: ops.proto.CreateItemResponse.dry_run:type_name -> ops.proto.Mutation
+	22, // 7: ops.proto.UpdateItemRequest.columns:type_name -> ops.proto.UpdateItemRequest.ColumnsEntry
+	8,  // 8: ops.proto.UpdateItemResponse.dry_run:type_name -> ops.proto.Mutation
+	8,  // 9: ops.proto.ArchiveItemResponse.dry_run:type_name -> ops.proto.Mutation
+	8,  // 10: ops.proto.AddNoteResponse.dry_run:type_name -> ops.proto.Mutation
+	8,  // 11: ops.proto.RevertResponse.dry_run:type_name -> ops.proto.Mutation
+	3,  // 12: ops.proto.BoardDescription.columns:type_name -> ops.proto.ColumnMeta
+	4,  // 13: ops.proto.BoardDescription.groups:type_name -> ops.proto.GroupMeta
+	19, // 14: ops.proto.DescribeBoardResponse.boards:type_name -> ops.proto.BoardDescription
+	2,  // 15: ops.proto.MondayService.FindItem:input_type -> ops.proto.FindItemRequest
+	7,  // 16: ops.proto.MondayService.CreateItem:input_type -> ops.proto.CreateItemRequest
+	18, // 17: ops.proto.MondayService.DescribeBoard:input_type -> ops.proto.DescribeBoardRequest
+	10, // 18: ops.proto.MondayService.UpdateItem:input_type -> ops.proto.UpdateItemRequest
+	12, // 19: ops.proto.MondayService.ArchiveItem:input_type -> ops.proto.ArchiveItemRequest
+	14, // 20: ops.proto.MondayService.AddNote:input_type -> ops.proto.AddNoteRequest
+	16, // 21: ops.proto.MondayService.Revert:input_type -> ops.proto.RevertRequest
+	6,  // 22: ops.proto.MondayService.FindItem:output_type -> ops.proto.FindItemResponse
+	9,  // 23: ops.proto.MondayService.CreateItem:output_type -> ops.proto.CreateItemResponse
+	20, // 24: ops.proto.MondayService.DescribeBoard:output_type -> ops.proto.DescribeBoardResponse
+	11, // 25: ops.proto.MondayService.UpdateItem:output_type -> ops.proto.UpdateItemResponse
+	13, // 26: ops.proto.MondayService.ArchiveItem:output_type -> ops.proto.ArchiveItemResponse
+	15, // 27: ops.proto.MondayService.AddNote:output_type -> ops.proto.AddNoteResponse
+	17, // 28: ops.proto.MondayService.Revert:output_type -> ops.proto.RevertResponse
+	22, // [22:29] is the sub-list for method output_type
+	15, // [15:22] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
 }
 
 func init() { file_ops_proto_init() }
@@ -461,13 +1630,14 @@ func file_ops_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ops_proto_rawDesc), len(file_ops_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      2,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_ops_proto_goTypes,
 		DependencyIndexes: file_ops_proto_depIdxs,
+		EnumInfos:         file_ops_proto_enumTypes,
 		MessageInfos:      file_ops_proto_msgTypes,
 	}.Build()
 	File_ops_proto = out.File
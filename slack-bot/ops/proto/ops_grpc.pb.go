@@ -19,8 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MondayService_FindItem_FullMethodName   = "/ops.proto.MondayService/FindItem"
-	MondayService_CreateItem_FullMethodName = "/ops.proto.MondayService/CreateItem"
+	MondayService_FindItem_FullMethodName      = "/ops.proto.MondayService/FindItem"
+	MondayService_CreateItem_FullMethodName    = "/ops.proto.MondayService/CreateItem"
+	MondayService_DescribeBoard_FullMethodName = "/ops.proto.MondayService/DescribeBoard"
+	MondayService_UpdateItem_FullMethodName    = "/ops.proto.MondayService/UpdateItem"
+	MondayService_ArchiveItem_FullMethodName   = "/ops.proto.MondayService/ArchiveItem"
+	MondayService_AddNote_FullMethodName       = "/ops.proto.MondayService/AddNote"
+	MondayService_Revert_FullMethodName        = "/ops.proto.MondayService/Revert"
 )
 
 // MondayServiceClient is the client API for MondayService service.
@@ -29,6 +34,11 @@ const (
 type MondayServiceClient interface {
 	FindItem(ctx context.Context, in *FindItemRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FindItemResponse], error)
 	CreateItem(ctx context.Context, in *CreateItemRequest, opts ...grpc.CallOption) (*CreateItemResponse, error)
+	DescribeBoard(ctx context.Context, in *DescribeBoardRequest, opts ...grpc.CallOption) (*DescribeBoardResponse, error)
+	UpdateItem(ctx context.Context, in *UpdateItemRequest, opts ...grpc.CallOption) (*UpdateItemResponse, error)
+	ArchiveItem(ctx context.Context, in *ArchiveItemRequest, opts ...grpc.CallOption) (*ArchiveItemResponse, error)
+	AddNote(ctx context.Context, in *AddNoteRequest, opts ...grpc.CallOption) (*AddNoteResponse, error)
+	Revert(ctx context.Context, in *RevertRequest, opts ...grpc.CallOption) (*RevertResponse, error)
 }
 
 type mondayServiceClient struct {
@@ -68,12 +78,67 @@ func (c *mondayServiceClient) CreateItem(ctx context.Context, in *CreateItemRequ
 	return out, nil
 }
 
+func (c *mondayServiceClient) DescribeBoard(ctx context.Context, in *DescribeBoardRequest, opts ...grpc.CallOption) (*DescribeBoardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DescribeBoardResponse)
+	err := c.cc.Invoke(ctx, MondayService_DescribeBoard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mondayServiceClient) UpdateItem(ctx context.Context, in *UpdateItemRequest, opts ...grpc.CallOption) (*UpdateItemResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateItemResponse)
+	err := c.cc.Invoke(ctx, MondayService_UpdateItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mondayServiceClient) ArchiveItem(ctx context.Context, in *ArchiveItemRequest, opts ...grpc.CallOption) (*ArchiveItemResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ArchiveItemResponse)
+	err := c.cc.Invoke(ctx, MondayService_ArchiveItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mondayServiceClient) AddNote(ctx context.Context, in *AddNoteRequest, opts ...grpc.CallOption) (*AddNoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddNoteResponse)
+	err := c.cc.Invoke(ctx, MondayService_AddNote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mondayServiceClient) Revert(ctx context.Context, in *RevertRequest, opts ...grpc.CallOption) (*RevertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevertResponse)
+	err := c.cc.Invoke(ctx, MondayService_Revert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MondayServiceServer is the server API for MondayService service.
 // All implementations must embed UnimplementedMondayServiceServer
 // for forward compatibility.
 type MondayServiceServer interface {
 	FindItem(*FindItemRequest, grpc.ServerStreamingServer[FindItemResponse]) error
 	CreateItem(context.Context, *CreateItemRequest) (*CreateItemResponse, error)
+	DescribeBoard(context.Context, *DescribeBoardRequest) (*DescribeBoardResponse, error)
+	UpdateItem(context.Context, *UpdateItemRequest) (*UpdateItemResponse, error)
+	ArchiveItem(context.Context, *ArchiveItemRequest) (*ArchiveItemResponse, error)
+	AddNote(context.Context, *AddNoteRequest) (*AddNoteResponse, error)
+	Revert(context.Context, *RevertRequest) (*RevertResponse, error)
 	mustEmbedUnimplementedMondayServiceServer()
 }
 
@@ -90,6 +155,21 @@ func (UnimplementedMondayServiceServer) FindItem(*FindItemRequest, grpc.ServerSt
 func (UnimplementedMondayServiceServer) CreateItem(context.Context, *CreateItemRequest) (*CreateItemResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CreateItem not implemented")
 }
+func (UnimplementedMondayServiceServer) DescribeBoard(context.Context, *DescribeBoardRequest) (*DescribeBoardResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DescribeBoard not implemented")
+}
+func (UnimplementedMondayServiceServer) UpdateItem(context.Context, *UpdateItemRequest) (*UpdateItemResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateItem not implemented")
+}
+func (UnimplementedMondayServiceServer) ArchiveItem(context.Context, *ArchiveItemRequest) (*ArchiveItemResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ArchiveItem not implemented")
+}
+func (UnimplementedMondayServiceServer) AddNote(context.Context, *AddNoteRequest) (*AddNoteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddNote not implemented")
+}
+func (UnimplementedMondayServiceServer) Revert(context.Context, *RevertRequest) (*RevertResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Revert not implemented")
+}
 func (UnimplementedMondayServiceServer) mustEmbedUnimplementedMondayServiceServer() {}
 func (UnimplementedMondayServiceServer) testEmbeddedByValue()                       {}
 
@@ -140,6 +220,96 @@ func _MondayService_CreateItem_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MondayService_DescribeBoard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeBoardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MondayServiceServer).DescribeBoard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MondayService_DescribeBoard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MondayServiceServer).DescribeBoard(ctx, req.(*DescribeBoardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MondayService_UpdateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MondayServiceServer).UpdateItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MondayService_UpdateItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MondayServiceServer).UpdateItem(ctx, req.(*UpdateItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MondayService_ArchiveItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArchiveItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MondayServiceServer).ArchiveItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MondayService_ArchiveItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MondayServiceServer).ArchiveItem(ctx, req.(*ArchiveItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MondayService_AddNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MondayServiceServer).AddNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MondayService_AddNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MondayServiceServer).AddNote(ctx, req.(*AddNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MondayService_Revert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MondayServiceServer).Revert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MondayService_Revert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MondayServiceServer).Revert(ctx, req.(*RevertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MondayService_ServiceDesc is the grpc.ServiceDesc for MondayService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -151,6 +321,26 @@ var MondayService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateItem",
 			Handler:    _MondayService_CreateItem_Handler,
 		},
+		{
+			MethodName: "DescribeBoard",
+			Handler:    _MondayService_DescribeBoard_Handler,
+		},
+		{
+			MethodName: "UpdateItem",
+			Handler:    _MondayService_UpdateItem_Handler,
+		},
+		{
+			MethodName: "ArchiveItem",
+			Handler:    _MondayService_ArchiveItem_Handler,
+		},
+		{
+			MethodName: "AddNote",
+			Handler:    _MondayService_AddNote_Handler,
+		},
+		{
+			MethodName: "Revert",
+			Handler:    _MondayService_Revert_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
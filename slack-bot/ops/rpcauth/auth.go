@@ -0,0 +1,408 @@
+// Package rpcauth secures the gRPC connection between the bot and ops: TLS
+// or mutual TLS credentials, and interceptors authenticating every call
+// with a per-client bearer token or HMAC signature.
+package rpcauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CatalinCaprita/SPO/slack-bot/ops/authz"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	AUTHORIZATION_KEY = "authorization"
+	CLIENT_KEY        = "x-client-id"
+	TIMESTAMP_KEY     = "x-timestamp"
+	SIGNATURE_KEY     = "x-signature"
+	NONCE_KEY         = "x-nonce"
+	// how far the timestamp of a signed call may be from the server's clock,
+	// and so how long its nonce is remembered
+	maxSkew = 5 * time.Minute
+	// tokens and secrets shorter than this are refused, they can be guessed
+	minSecretLength = 16
+)
+
+// PUBLIC_SERVICES answer without credentials: they only tell whether the
+// server is up and what it serves.
+var PUBLIC_SERVICES = []string{
+	"grpc.health.v1.Health",
+	"grpc.reflection.v1.ServerReflection",
+	"grpc.reflection.v1alpha.ServerReflection",
+}
+
+// Client is a caller of the server, authenticated either by Token, sent as
+// a bearer token, or by HmacSecret, which signs every call.
+type Client struct {
+	Name       string `yaml:"name"`
+	Token      string `yaml:"token"`
+	HmacSecret string `yaml:"hmac_secret"`
+}
+
+type Clients struct {
+	Clients []Client `yaml:"clients"`
+}
+
+// LoadClients reads the clients allowed to call the server from a YAML file.
+func LoadClients(path string) (*Clients, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clients: %w", err)
+	}
+	var clients = &Clients{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(clients); err != nil {
+		return nil, fmt.Errorf("failed to decode clients %s: %w", path, err)
+	}
+	if err := clients.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid clients %s: %w", path, err)
+	}
+	return clients, nil
+}
+
+func (c *Clients) Validate() error {
+	var names = map[string]bool{}
+	for i, client := range c.Clients {
+		if client.Name == "" {
+			return fmt.Errorf("client %d has no name", i+1)
+		}
+		if names[client.Name] {
+			return fmt.Errorf("client %s is listed twice", client.Name)
+		}
+		names[client.Name] = true
+		if (client.Token == "") == (client.HmacSecret == "") {
+			return fmt.Errorf("client %s needs either a token or an hmac_secret", client.Name)
+		}
+		if len(client.Token+client.HmacSecret) < minSecretLength {
+			return fmt.Errorf("the token or hmac_secret of client %s is shorter than %d characters", client.Name, minSecretLength)
+		}
+	}
+	return nil
+}
+
+type clientKey struct{}
+
+// ClientFromContext returns the name of the client that made the call: the
+// one it authenticated as, or the common name of its certificate when only
+// mutual TLS is used, including one a ForwardedPeer passed on. It is empty
+// for anonymous calls.
+func ClientFromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(clientKey{}).(string); ok {
+		return name
+	}
+	if name, ok := ctx.Value(forwardedPeerKey{}).(string); ok {
+		return name
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return info.State.VerifiedChains[0][0].Subject.CommonName
+}
+
+// Authenticator rejects the calls without valid credentials of one of its
+// clients with Unauthenticated.
+type Authenticator struct {
+	clients []Client
+	now     func() time.Time
+	mu      sync.Mutex
+	// nonces of the signed calls accepted, until their timestamp expires
+	nonces map[string]time.Time
+}
+
+func NewAuthenticator(clients *Clients) *Authenticator {
+	return &Authenticator{clients: clients.Clients, now: time.Now, nonces: map[string]time.Time{}}
+}
+
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod, req)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor authenticates streams. The signature of a signed
+// stream covers its request, so it is checked when the handler receives
+// the request, before it can act on it.
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		var s = &authenticatedStream{ServerStream: stream, ctx: stream.Context(), auth: a, method: info.FullMethod}
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		if first(md, CLIENT_KEY) != "" && !isPublic(info.FullMethod) && !info.IsClientStream {
+			s.signed = true
+			return handler(srv, s)
+		}
+		ctx, err := a.authenticate(stream.Context(), info.FullMethod, nil)
+		if err != nil {
+			return err
+		}
+		s.ctx = ctx
+		return handler(srv, s)
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	auth   *Authenticator
+	method string
+	// signed is set until the request the signature covers is received
+	signed bool
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authenticatedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.signed {
+		ctx, err := s.auth.authenticate(s.ServerStream.Context(), s.method, m)
+		if err != nil {
+			return err
+		}
+		s.ctx, s.signed = ctx, false
+	}
+	return nil
+}
+
+func isPublic(method string) bool {
+	for _, service := range PUBLIC_SERVICES {
+		if strings.HasPrefix(method, "/"+service+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate returns ctx with the name of the client that made the call
+// with req.
+func (a *Authenticator) authenticate(ctx context.Context, method string, req any) (context.Context, error) {
+	if isPublic(method) {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if bearer, ok := strings.CutPrefix(first(md, AUTHORIZATION_KEY), "Bearer "); ok {
+		for _, c := range a.clients {
+			if c.Token != "" && subtle.ConstantTimeCompare([]byte(c.Token), []byte(bearer)) == 1 {
+				return context.WithValue(ctx, clientKey{}, c.Name), nil
+			}
+		}
+		return ctx, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+	if name := first(md, CLIENT_KEY); name != "" {
+		var timestamp = first(md, TIMESTAMP_KEY)
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return ctx, status.Error(codes.Unauthenticated, "invalid timestamp")
+		}
+		if skew := a.now().Sub(time.Unix(seconds, 0)); skew > maxSkew || skew < -maxSkew {
+			return ctx, status.Error(codes.Unauthenticated, "timestamp too far from the server's clock")
+		}
+		var nonce = first(md, NONCE_KEY)
+		if nonce == "" {
+			return ctx, status.Error(codes.Unauthenticated, "missing nonce")
+		}
+		for _, c := range a.clients {
+			if c.Name != name || c.HmacSecret == "" {
+				continue
+			}
+			var expected = sign(c.HmacSecret, name, timestamp, nonce, method, req, md)
+			if !hmac.Equal([]byte(expected), []byte(first(md, SIGNATURE_KEY))) {
+				break
+			}
+			if !a.firstUse(name+"/"+nonce, time.Unix(seconds, 0).Add(maxSkew)) {
+				return ctx, status.Error(codes.Unauthenticated, "replayed nonce")
+			}
+			return context.WithValue(ctx, clientKey{}, c.Name), nil
+		}
+		return ctx, status.Error(codes.Unauthenticated, "invalid signature")
+	}
+	return ctx, status.Error(codes.Unauthenticated, "missing bearer token or signature")
+}
+
+// firstUse remembers nonce until expires, and tells whether it was not
+// already. A call is refused by its timestamp once its nonce is forgotten.
+func (a *Authenticator) firstUse(nonce string, expires time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var now = a.now()
+	for n, expiry := range a.nonces {
+		if now.After(expiry) {
+			delete(a.nonces, n)
+		}
+	}
+	if _, seen := a.nonces[nonce]; seen {
+		return false
+	}
+	a.nonces[nonce] = expires
+	return true
+}
+
+// sign signs a call to method with req, along with the caller it is made
+// for so that neither can be swapped for another one. The nonce keeps the
+// call from being replayed.
+func sign(secret, client, timestamp, nonce, method string, req any, md metadata.MD) string {
+	var payload = strings.Join([]string{
+		client,
+		timestamp,
+		nonce,
+		method,
+		digest(req),
+		first(md, authz.USER_KEY),
+		first(md, authz.GROUPS_KEY),
+		first(md, authz.CHANNEL_KEY),
+		first(md, authz.TEAM_KEY),
+		first(md, authz.TENANT_KEY),
+	}, "\n")
+	var mac = hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// digest is the SHA-256 of the deterministic protobuf encoding of req, or
+// "" when there is no request to sign.
+func digest(req any) string {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return ""
+	}
+	content, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return ""
+	}
+	var sum = sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// WithBearerToken returns the dial options sending token with every call.
+func WithBearerToken(token string) []grpc.DialOption {
+	return withMetadata(func(ctx context.Context, method string, req any) []string {
+		return []string{AUTHORIZATION_KEY, "Bearer " + token}
+	})
+}
+
+// WithHMAC returns the dial options signing every call as client.
+func WithHMAC(client, secret string) []grpc.DialOption {
+	return withMetadata(func(ctx context.Context, method string, req any) []string {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		var timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+		var nonce = rand.Text()
+		return []string{
+			CLIENT_KEY, client,
+			TIMESTAMP_KEY, timestamp,
+			NONCE_KEY, nonce,
+			SIGNATURE_KEY, sign(secret, client, timestamp, nonce, method, req, md),
+		}
+	})
+}
+
+// withMetadata returns client interceptors adding the metadata returned by
+// pairs to every call. Server streams are only opened when their request is
+// sent, for pairs to get it like it does for unary calls.
+func withMetadata(pairs func(ctx context.Context, method string, req any) []string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(metadata.AppendToOutgoingContext(ctx, pairs(ctx, method, req)...), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			if desc.ClientStreams {
+				return streamer(metadata.AppendToOutgoingContext(ctx, pairs(ctx, method, nil)...), desc, cc, method, opts...)
+			}
+			return &pendingStream{ctx: ctx, open: func(req any) (grpc.ClientStream, error) {
+				return streamer(metadata.AppendToOutgoingContext(ctx, pairs(ctx, method, req)...), desc, cc, method, opts...)
+			}}, nil
+		}),
+	}
+}
+
+var errNotSent = status.Error(codes.Internal, "the request of the stream was not sent")
+
+// pendingStream opens a server stream when its request is sent.
+type pendingStream struct {
+	grpc.ClientStream
+	ctx  context.Context
+	open func(req any) (grpc.ClientStream, error)
+}
+
+func (s *pendingStream) SendMsg(m any) error {
+	if s.ClientStream == nil {
+		stream, err := s.open(m)
+		if err != nil {
+			return err
+		}
+		s.ClientStream = stream
+	}
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *pendingStream) Context() context.Context {
+	if s.ClientStream == nil {
+		return s.ctx
+	}
+	return s.ClientStream.Context()
+}
+
+func (s *pendingStream) Header() (metadata.MD, error) {
+	if s.ClientStream == nil {
+		return nil, errNotSent
+	}
+	return s.ClientStream.Header()
+}
+
+func (s *pendingStream) Trailer() metadata.MD {
+	if s.ClientStream == nil {
+		return nil
+	}
+	return s.ClientStream.Trailer()
+}
+
+func (s *pendingStream) CloseSend() error {
+	if s.ClientStream == nil {
+		return errNotSent
+	}
+	return s.ClientStream.CloseSend()
+}
+
+func (s *pendingStream) RecvMsg(m any) error {
+	if s.ClientStream == nil {
+		return errNotSent
+	}
+	return s.ClientStream.RecvMsg(m)
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
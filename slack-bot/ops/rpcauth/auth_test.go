@@ -0,0 +1,134 @@
+package rpcauth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	pb "github.com/CatalinCaprita/SPO/slack-bot/ops/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const hmacSecret = "0123456789abcdef"
+
+// echoServer answers with the name of the client calling it.
+type echoServer struct {
+	pb.UnimplementedMondayServiceServer
+}
+
+func (echoServer) CreateItem(ctx context.Context, req *pb.CreateItemRequest) (*pb.CreateItemResponse, error) {
+	return &pb.CreateItemResponse{Id: ClientFromContext(ctx) + ":" + req.Name}, nil
+}
+
+func (echoServer) FindItem(req *pb.FindItemRequest, stream grpc.ServerStreamingServer[pb.FindItemResponse]) error {
+	return stream.Send(&pb.FindItemResponse{Name: ClientFromContext(stream.Context()) + ":" + req.Value})
+}
+
+// serve serves the signed clients in memory until the end of the test.
+func serve(t *testing.T) *bufconn.Listener {
+	var auth = NewAuthenticator(&Clients{Clients: []Client{{Name: "bot", HmacSecret: hmacSecret}}})
+	var listener = bufconn.Listen(1 << 20)
+	var server = grpc.NewServer(grpc.ChainUnaryInterceptor(auth.UnaryInterceptor()), grpc.ChainStreamInterceptor(auth.StreamInterceptor()))
+	pb.RegisterMondayServiceServer(server, echoServer{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener
+}
+
+// dial returns a connection to listener made with options.
+func dial(t *testing.T, listener *bufconn.Listener, options ...grpc.DialOption) *grpc.ClientConn {
+	options = append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, options...)
+	conn, err := grpc.NewClient("passthrough:///bufnet", options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func findOne(client pb.MondayServiceClient, value string) (string, error) {
+	stream, err := client.FindItem(context.Background(), &pb.FindItemRequest{Value: value})
+	if err != nil {
+		return "", err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return "", err
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return resp.Name, nil
+}
+
+func TestSignedCalls(t *testing.T) {
+	var client = pb.NewMondayServiceClient(dial(t, serve(t), WithHMAC("bot", hmacSecret)...))
+	resp, err := client.CreateItem(context.Background(), &pb.CreateItemRequest{Name: "Ann"})
+	if err != nil || resp.Id != "bot:Ann" {
+		t.Errorf("CreateItem() = %v, %v, want it made by bot", resp, err)
+	}
+	name, err := findOne(client, "ann")
+	if err != nil || name != "bot:ann" {
+		t.Errorf("FindItem() = %q, %v, want it made by bot", name, err)
+	}
+}
+
+func TestTamperedRequestsAreRefused(t *testing.T) {
+	// the requests are changed after they were signed
+	var tamper = []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			req.(*pb.CreateItemRequest).Name = "Mallory"
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			stream, err := streamer(ctx, desc, cc, method, opts...)
+			return &tamperedStream{ClientStream: stream}, err
+		}),
+	}
+	var client = pb.NewMondayServiceClient(dial(t, serve(t), append(WithHMAC("bot", hmacSecret), tamper...)...))
+	if _, err := client.CreateItem(context.Background(), &pb.CreateItemRequest{Name: "Ann"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("tampered CreateItem: err = %v, want Unauthenticated", err)
+	}
+	if _, err := findOne(client, "ann"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("tampered FindItem: err = %v, want Unauthenticated", err)
+	}
+}
+
+type tamperedStream struct {
+	grpc.ClientStream
+}
+
+func (s *tamperedStream) SendMsg(m any) error {
+	m.(*pb.FindItemRequest).Value = "everyone"
+	return s.ClientStream.SendMsg(m)
+}
+
+func TestReplayedNoncesAreRefused(t *testing.T) {
+	var signed metadata.MD
+	var record = grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		signed, _ = metadata.FromOutgoingContext(ctx)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	})
+	var listener = serve(t)
+	var conn = dial(t, listener, append(WithHMAC("bot", hmacSecret), record)...)
+	var req = &pb.CreateItemRequest{Name: "Ann"}
+	if _, err := pb.NewMondayServiceClient(conn).CreateItem(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	var replay = metadata.NewOutgoingContext(context.Background(), signed)
+	_, err := pb.NewMondayServiceClient(dial(t, listener)).CreateItem(replay, req)
+	if status.Code(err) != codes.Unauthenticated || status.Convert(err).Message() != "replayed nonce" {
+		t.Errorf("replayed CreateItem: err = %v, want Unauthenticated", err)
+	}
+}
@@ -0,0 +1,65 @@
+package rpcauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	FORWARDED_PEER_KEY   = "x-forwarded-peer"
+	FORWARDED_SECRET_KEY = "x-forwarded-peer-secret"
+)
+
+type forwardedPeerKey struct{}
+
+// ForwardedPeer passes the client certificate a proxy in the same process,
+// e.g. the REST/JSON gateway, verified on to the gRPC server behind it,
+// which only sees the in-memory connection of the proxy. The name is only
+// trusted along with a secret random to the process, so it cannot be
+// forged by the clients of the proxy or of any other server.
+type ForwardedPeer struct {
+	secret string
+}
+
+func NewForwardedPeer() *ForwardedPeer {
+	return &ForwardedPeer{secret: rand.Text()}
+}
+
+// OutgoingContext returns ctx forwarding the common name of the client
+// certificate verified on state, if any.
+func (f *ForwardedPeer) OutgoingContext(ctx context.Context, state *tls.ConnectionState) context.Context {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx,
+		FORWARDED_PEER_KEY, state.VerifiedChains[0][0].Subject.CommonName,
+		FORWARDED_SECRET_KEY, f.secret,
+	)
+}
+
+// ServerOptions make the names forwarded with the secret the ones
+// ClientFromContext returns for mutual TLS.
+func (f *ForwardedPeer) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(f.trust(ctx), req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &authenticatedStream{ServerStream: stream, ctx: f.trust(stream.Context())})
+		}),
+	}
+}
+
+func (f *ForwardedPeer) trust(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var name = first(md, FORWARDED_PEER_KEY)
+	if name == "" || subtle.ConstantTimeCompare([]byte(first(md, FORWARDED_SECRET_KEY)), []byte(f.secret)) != 1 {
+		return ctx
+	}
+	return context.WithValue(ctx, forwardedPeerKey{}, name)
+}
@@ -0,0 +1,74 @@
+package rpcauth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// ServerTLS returns the credentials of a server presenting the certificate
+// in certFile. With clientCAFile, clients must present a certificate signed
+// by one of its CAs (mutual TLS).
+func ServerTLS(certFile, keyFile, clientCAFile string) (credentials.TransportCredentials, error) {
+	config, err := ServerTLSConfig(certFile, keyFile, clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// ServerTLSConfig is ServerTLS for servers other than gRPC, e.g. HTTP.
+func ServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+	var config = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if clientCAFile != "" {
+		pool, err := loadPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// ClientTLS returns the credentials of a client verifying the server with
+// the CAs in caFile, or the system ones when empty. certFile and keyFile,
+// when set, are presented to servers asking for a client certificate.
+// serverName overrides the name the server certificate is checked against.
+func ClientTLS(caFile, certFile, keyFile, serverName string) (credentials.TransportCredentials, error) {
+	var config = &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pool, err := loadPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(config), nil
+}
+
+func loadPool(caFile string) (*x509.CertPool, error) {
+	content, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA: %w", err)
+	}
+	var pool = x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("no certificate found in %s", caFile)
+	}
+	return pool, nil
+}
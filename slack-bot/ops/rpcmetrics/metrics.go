@@ -0,0 +1,117 @@
+// Package rpcmetrics counts and times the gRPC calls between the bot and
+// ops, on the server side in ops and on the client side in the bot, for
+// Prometheus to scrape at METRICS_PATH.
+package rpcmetrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const METRICS_PATH = "/metrics"
+
+var buckets = []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+
+var (
+	serverHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "RPCs completed by the server, by method and status code.",
+	}, []string{"grpc_method", "grpc_code"})
+	serverDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Time the server took to handle an RPC, by method.",
+		Buckets: buckets,
+	}, []string{"grpc_method"})
+	clientHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_handled_total",
+		Help: "RPCs completed by the client, by method and status code.",
+	}, []string{"grpc_method", "grpc_code"})
+	clientDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_handling_seconds",
+		Help:    "Time an RPC took until the client got its answer, by method.",
+		Buckets: buckets,
+	}, []string{"grpc_method"})
+)
+
+func init() {
+	prometheus.MustRegister(serverHandled, serverDuration, clientHandled, clientDuration)
+}
+
+// Handler serves every metric registered in the process.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ServerOptions instruments every RPC handled by a server.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			var start = time.Now()
+			resp, err := handler(ctx, req)
+			observe(serverHandled, serverDuration, info.FullMethod, start, err)
+			return resp, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			var start = time.Now()
+			err := handler(srv, ss)
+			observe(serverHandled, serverDuration, info.FullMethod, start, err)
+			return err
+		}),
+	}
+}
+
+// DialOptions instruments every RPC made through a connection. A stream is
+// timed until it ends, and not counted when the caller stops reading early.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			var start = time.Now()
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			observe(clientHandled, clientDuration, method, start, err)
+			return err
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			var start = time.Now()
+			stream, err := streamer(ctx, desc, cc, method, opts...)
+			if err != nil {
+				observe(clientHandled, clientDuration, method, start, err)
+				return nil, err
+			}
+			return &observedStream{ClientStream: stream, method: method, start: start}, nil
+		}),
+	}
+}
+
+type observedStream struct {
+	grpc.ClientStream
+	method string
+	start  time.Time
+	once   sync.Once
+}
+
+func (s *observedStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.once.Do(func() {
+			if err == io.EOF {
+				observe(clientHandled, clientDuration, s.method, s.start, nil)
+				return
+			}
+			observe(clientHandled, clientDuration, s.method, s.start, err)
+		})
+	}
+	return err
+}
+
+func observe(handled *prometheus.CounterVec, duration *prometheus.HistogramVec, method string, start time.Time, err error) {
+	duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	handled.WithLabelValues(method, status.Code(err).String()).Inc()
+}
@@ -0,0 +1,82 @@
+// Package tracing sets up OpenTelemetry tracing for the bot and ops, and
+// propagates the trace of a chat command over the gRPC calls it makes.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"google.golang.org/grpc"
+)
+
+const (
+	EXPORTER_NONE   = ""
+	EXPORTER_STDOUT = "stdout"
+	// EXPORTER_OTLP sends spans over gRPC to OTEL_EXPORTER_OTLP_ENDPOINT,
+	// localhost:4317 by default, e.g. a local Jaeger.
+	EXPORTER_OTLP = "otlp"
+)
+
+// Setup makes the spans of the process, which is called service, go to
+// exporter. Spans are dropped when exporter is EXPORTER_NONE. The returned
+// function flushes the spans not exported yet.
+func Setup(ctx context.Context, service, exporter string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case EXPORTER_NONE:
+		return func(context.Context) error { return nil }, nil
+	case EXPORTER_STDOUT:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr), stdouttrace.WithPrettyPrint())
+	case EXPORTER_OTLP:
+		spanExporter, err = otlptracegrpc.New(ctx, otlpOptions()...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %s, expected %s or %s", exporter, EXPORTER_STDOUT, EXPORTER_OTLP)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", exporter, err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(service)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe %s: %w", service, err)
+	}
+	var provider = sdktrace.NewTracerProvider(sdktrace.WithBatcher(spanExporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// otlpOptions sends spans in plaintext unless an endpoint with https:// is
+// configured, local collectors rarely serve TLS.
+func otlpOptions() []otlptracegrpc.Option {
+	var endpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if strings.HasPrefix(endpoint, "https://") {
+		return nil
+	}
+	return []otlptracegrpc.Option{otlptracegrpc.WithInsecure()}
+}
+
+// ServerOptions continues the trace of the caller in every RPC handled by a
+// server.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.StatsHandler(otelgrpc.NewServerHandler())}
+}
+
+// DialOptions traces every RPC made through a connection and sends the
+// trace along.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{grpc.WithStatsHandler(otelgrpc.NewClientHandler())}
+}